@@ -2,44 +2,88 @@
 package main
 
 import (
-	// Import both agent packages to trigger init() registration
-	_ "agentlog/internal/claude"
-	_ "agentlog/internal/codex"
+	"agentlog/internal/claude"
+	"agentlog/internal/codex"
 	"agentlog/internal/format"
 	"agentlog/internal/model"
 	"agentlog/internal/store"
+	"agentlog/internal/tui"
+	"agentlog/internal/util"
 	"agentlog/internal/view"
-	"encoding/json"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
+	"text/template"
 	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
 )
 
 var version = "dev"
 
 var (
-	agentType string
+	agentType      string
+	quiet          bool
+	followSymlinks bool
 )
 
-var rootCmd = &cobra.Command{
-	Use:     "agentlog",
-	Short:   "Browse, search, and analyze AI agent conversation logs",
-	Version: version,
-}
+// Exit codes returned by the agentlog binary.
+const (
+	exitOK          = 0   // the command succeeded
+	exitNoMatch     = 1   // no session or event matched, or doctor found errors
+	exitError       = 2   // invalid usage or an I/O error
+	exitInterrupted = 130 // stopped by Ctrl-C, as shells report for SIGINT
+)
+
+// errNoMatch is returned by list when no session matched the filters.
+var errNoMatch = errors.New("no sessions matched")
+
+// errIssuesFound is returned by doctor when error-level issues were found.
+var errIssuesFound = errors.New("validation failed")
 
-func init() {
-	rootCmd.PersistentFlags().StringVar(&agentType, "agent", "",
+func newRootCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "agentlog",
+		Short:   "Browse, search, and analyze AI agent conversation logs",
+		Version: version,
+		// main reports errors itself so it can pick the exit code.
+		SilenceErrors: true,
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			// Flags and arguments are valid by now; later errors are not
+			// usage mistakes.
+			cmd.SilenceUsage = true
+			if quiet {
+				cmd.SetOut(io.Discard)
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&agentType, "agent", "",
 		"Agent type: 'codex' or 'claude' (env: AGENTLOG_AGENT, default: claude)")
+	cmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false,
+		"suppress normal output; only the exit status reports the result")
+	cmd.PersistentFlags().BoolVar(&followSymlinks, "follow-symlinks", false,
+		"descend into symlinked directories under the sessions directory, and into a symlinked sessions directory itself")
 
-	rootCmd.AddCommand(newListCmd())
-	rootCmd.AddCommand(newViewCmd())
-	rootCmd.AddCommand(newInfoCmd())
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newViewCmd())
+	cmd.AddCommand(newInfoCmd())
+	cmd.AddCommand(newDoctorCmd())
+	cmd.AddCommand(newResumeCmd())
+	cmd.AddCommand(newPathCmd())
+	cmd.AddCommand(newTagCmd())
+	return cmd
 }
 
 // getAgentType returns the agent type from flag, environment variable, or default.
@@ -53,41 +97,179 @@ func getAgentType() model.AgentType {
 	return model.AgentClaude
 }
 
-// defaultSessionsDir returns the default sessions directory for the given agent type.
-func defaultSessionsDir(agentType model.AgentType) string {
-	if dir := os.Getenv("AGENTLOG_SESSIONS_DIR"); dir != "" {
-		return dir
+// timeFlags holds the timestamp display flags shared by list, view, and info.
+type timeFlags struct {
+	layout string
+	local  bool
+	utc    bool
+	zone   string
+}
+
+// addTimeFlags registers --time-format, --local, --utc, and --timezone on cmd.
+func addTimeFlags(cmd *cobra.Command) *timeFlags {
+	tf := &timeFlags{}
+	flags := cmd.Flags()
+	flags.StringVar(&tf.layout, "time-format", "", "timestamp format: rfc3339, kitchen, datetime, unix, or a Go layout string")
+	flags.BoolVar(&tf.local, "local", false, "display timestamps in the local time zone")
+	flags.BoolVar(&tf.utc, "utc", false, "display timestamps in UTC")
+	flags.StringVar(&tf.zone, "timezone", "", "display timestamps in the named IANA time zone (e.g. America/New_York) or local")
+	return tf
+}
+
+func (tf *timeFlags) formatter() (format.TimeFormatter, error) {
+	f, err := format.NewTimeFormatter(tf.layout, tf.local, tf.utc)
+	if err != nil || tf.zone == "" {
+		return f, err
 	}
+	if tf.local || tf.utc {
+		return f, errors.New("--timezone cannot be used with --local or --utc")
+	}
+	loc, err := format.LoadTimeZone(tf.zone)
+	if err != nil {
+		return f, fmt.Errorf("invalid --timezone value: %w", err)
+	}
+	f.Location = loc
+	return f, nil
+}
 
-	home, _ := os.UserHomeDir()
-	switch agentType {
-	case model.AgentCodex:
-		return filepath.Join(home, ".codex", "sessions")
-	case model.AgentClaude:
-		return filepath.Join(home, ".claude", "projects")
-	default:
-		return filepath.Join(home, ".claude", "projects")
+// addTemplateFlags registers --template and --template-file on cmd. when
+// describes how often the template runs.
+func addTemplateFlags(cmd *cobra.Command, text, file *string, when string) {
+	flags := cmd.Flags()
+	flags.StringVar(text, "template", "", "Go text/template executed "+when+", e.g. '{{.ID}} {{.MessageCount}} {{.Summary}}'")
+	flags.StringVar(file, "template-file", "", "read the --template from the named file")
+}
+
+// loadTemplate parses the --template or --template-file template, returning
+// nil when neither is set.
+func loadTemplate(text, path string, timeFormat format.TimeFormatter) (*template.Template, error) {
+	if text != "" && path != "" {
+		return nil, errors.New("--template and --template-file cannot be used together")
 	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read template file: %w", err)
+		}
+		text = string(data)
+	}
+	if text == "" {
+		return nil, nil
+	}
+	return format.ParseTemplate(text, timeFormat)
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "agentlog: %v\n", err)
-		os.Exit(1)
+	// An interrupt cancels the command's context instead of killing the
+	// process, so rendering stops cleanly and the terminal is left in a sane
+	// state. Interrupts keep being caught afterwards: one pressed while a
+	// pager is open must not leave the pager running without its parent.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	root := newRootCmd()
+	root.SetContext(ctx)
+	code := run(root, os.Args[1:], os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes root with args, reports errors on stderr, and returns the
+// process exit code.
+func run(root *cobra.Command, args []string, stderr io.Writer) int {
+	root.SetArgs(args)
+	err := root.Execute()
+	code := exitCode(err)
+	if shouldReport(err, code) {
+		fmt.Fprintf(stderr, "agentlog: %v\n", err) //nolint:errcheck
 	}
+	return code
+}
+
+// exitCode maps a command error to the process exit status: 0 on success,
+// 1 when nothing matched, 2 for usage and I/O errors, and 130 when
+// interrupted.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, errNoMatch),
+		errors.Is(err, view.ErrNoEvents),
+		errors.Is(err, store.ErrSessionNotFound),
+		errors.Is(err, errIssuesFound):
+		return exitNoMatch
+	default:
+		return exitError
+	}
+}
+
+// shouldReport reports whether err deserves a message on stderr. Empty
+// results speak for themselves, and --quiet silences every outcome that is
+// not a failure.
+func shouldReport(err error, code int) bool {
+	if err == nil {
+		return false
+	}
+	if code == exitInterrupted {
+		return false
+	}
+	if code != exitNoMatch {
+		return true
+	}
+	if quiet || errors.Is(err, errNoMatch) || errors.Is(err, view.ErrNoEvents) {
+		return false
+	}
+	return true
 }
 
 func newListCmd() *cobra.Command {
 	var (
-		cwd          string
-		all          bool
-		afterStr     string
-		beforeStr    string
-		limit        int
-		formatFlag   string
-		noHeader     bool
-		summaryWidth int
-		sessionsDir  string
+		cwd            string
+		all            bool
+		afterStr       string
+		beforeStr      string
+		sinceStr       string
+		today          bool
+		yesterday      bool
+		thisWeek       bool
+		limit          int
+		formatFlag     string
+		noHeader       bool
+		summaryWidth   int
+		summarySource  string
+		sessionsDirs   []string
+		countOnly      bool
+		groupBy        string
+		modelFilter    string
+		minMessages    int
+		maxMessages    int
+		nonEmpty       bool
+		minDuration    string
+		maxDuration    string
+		fullSummary    bool
+		summaryLines   int
+		durationFormat string
+		outputPath     string
+		warningsFormat string
+		hyperlinks     bool
+		sessionURL     string
+		templateText   string
+		templateFile   string
+		page           int
+		pageSize       int
+		afterID        string
+		beforeID       string
+		markInterrupt  bool
+		uniqueCWD      bool
+		duplicates     bool
+		showTags       bool
+		tagFilter      string
+		excludeDirs    []string
+		compactJSON    bool
+		watch          bool
+		watchInterval  time.Duration
+		columns        []string
+		timeOpts       *timeFlags
 	)
 
 	cmd := &cobra.Command{
@@ -97,6 +279,32 @@ func newListCmd() *cobra.Command {
 			if all && cwd != "" {
 				return errors.New("--cwd cannot be used with --all")
 			}
+			switch strings.ToLower(warningsFormat) {
+			case "text", "json":
+			default:
+				return fmt.Errorf("invalid --warnings-format value: %s (expected text or json)", warningsFormat)
+			}
+			switch strings.ToLower(summarySource) {
+			case "message", "summary":
+			default:
+				return fmt.Errorf("invalid --summary-source value: %s (expected message or summary)", summarySource)
+			}
+			if err := format.ValidateColumns(columns); err != nil {
+				return fmt.Errorf("invalid --columns value: %w", err)
+			}
+
+			timeFormat, err := timeOpts.formatter()
+			if err != nil {
+				return err
+			}
+
+			tmpl, err := loadTemplate(templateText, templateFile, timeFormat)
+			if err != nil {
+				return err
+			}
+			if tmpl != nil && (countOnly || groupBy != "" || cmd.Flags().Changed("format")) {
+				return errors.New("--template cannot be used with --format, --count, or --group-by")
+			}
 
 			// Get agent type and create parser
 			agent := getAgentType()
@@ -106,8 +314,8 @@ func newListCmd() *cobra.Command {
 			}
 
 			// Use default sessions dir if not provided
-			if sessionsDir == "" {
-				sessionsDir = defaultSessionsDir(agent)
+			if len(sessionsDirs) == 0 {
+				sessionsDirs = []string{model.DefaultSessionsDir(agent)}
 			}
 
 			var after, before *time.Time
@@ -125,14 +333,129 @@ func newListCmd() *cobra.Command {
 				}
 				before = &t
 			}
+			if sinceStr != "" {
+				if after != nil {
+					return errors.New("--since cannot be used with --after")
+				}
+				d, err := parseRelativeDuration(sinceStr)
+				if err != nil {
+					return fmt.Errorf("invalid --since value: %w", err)
+				}
+				t := time.Now().Add(-d)
+				after = &t
+			}
+			var window string
+			for _, flag := range []struct {
+				name string
+				set  bool
+			}{{"today", today}, {"yesterday", yesterday}, {"this-week", thisWeek}} {
+				if !flag.set {
+					continue
+				}
+				if window != "" {
+					return fmt.Errorf("--%s cannot be used with --%s", window, flag.name)
+				}
+				window = flag.name
+			}
+			if window != "" {
+				if afterStr != "" || beforeStr != "" || sinceStr != "" {
+					return fmt.Errorf("--%s cannot be used with --after, --before, or --since", window)
+				}
+				loc := timeFormat.Location
+				if loc == nil {
+					loc = time.Local
+				}
+				start, end := calendarWindow(window, time.Now().In(loc))
+				after, before = &start, &end
+			}
+
+			if minMessages < 0 || maxMessages < 0 {
+				return errors.New("--min-messages and --max-messages must not be negative")
+			}
+			if nonEmpty && minMessages < 1 {
+				minMessages = 1
+			}
+			if maxMessages > 0 && minMessages > maxMessages {
+				return errors.New("--min-messages cannot be greater than --max-messages")
+			}
+
+			minDur, err := parseDurationFlag("--min-duration", minDuration)
+			if err != nil {
+				return err
+			}
+			maxDur, err := parseDurationFlag("--max-duration", maxDuration)
+			if err != nil {
+				return err
+			}
+			durations, err := format.ParseDurationFormat(durationFormat)
+			if err != nil {
+				return fmt.Errorf("invalid --duration-format value: %w", err)
+			}
+			if minDur != nil && maxDur != nil && *minDur > *maxDur {
+				return errors.New("--min-duration cannot be greater than --max-duration")
+			}
+
+			if page < 0 || pageSize < 0 {
+				return errors.New("--page and --page-size must not be negative")
+			}
+			if page > 0 && pageSize == 0 {
+				return errors.New("--page requires --page-size")
+			}
+			if pageSize > 0 && page == 0 {
+				page = 1
+			}
+
+			if fullSummary {
+				summaryWidth = 0
+			}
+			if summaryLines < 0 {
+				return errors.New("--summary-lines must not be negative")
+			}
+			if summaryLines > 0 && fullSummary {
+				return errors.New("--summary-lines cannot be used with --full-summary")
+			}
+
+			if watchInterval <= 0 {
+				return errors.New("--interval must be positive")
+			}
+			if cmd.Flags().Changed("interval") && !watch {
+				return errors.New("--interval requires --watch")
+			}
 
 			opts := store.ListOptions{
-				Root:       sessionsDir,
-				After:      after,
-				Before:     before,
-				Limit:      limit,
-				MaxSummary: summaryWidth,
+				Roots:          sessionsDirs,
+				After:          after,
+				Before:         before,
+				Limit:          limit,
+				MaxSummary:     summaryWidth,
+				ModelFilter:    modelFilter,
+				MinMessages:    minMessages,
+				MaxMessages:    maxMessages,
+				MinDuration:    minDur,
+				MaxDuration:    maxDur,
+				UniqueCWD:      uniqueCWD,
+				Page:           page,
+				PageSize:       pageSize,
+				AfterID:        afterID,
+				BeforeID:       beforeID,
+				FollowSymlinks: followSymlinks,
+				ExcludeDirs:    excludeDirs,
+				Duplicates:     duplicates,
+				SummarySource:  strings.ToLower(summarySource),
+			}
+
+			if cmd.Flags().Changed("tag") && strings.TrimSpace(tagFilter) == "" {
+				return errors.New("--tag must not be empty")
+			}
+			var tags store.Tags
+			if showTags || tagFilter != "" || hasColumn(columns, "tags") {
+				tags, err = store.LoadTags(store.DefaultTagsPath())
+				if err != nil {
+					return err
+				}
 			}
+			opts.Tag = tagFilter
+			opts.Tags = tags
 
 			if !all {
 				if cwd != "" {
@@ -149,21 +472,73 @@ func newListCmd() *cobra.Command {
 				opts.CWD = cwd
 			}
 
-			result, err := store.ListSessions(parser, opts)
-			if err != nil {
-				return err
+			out := cmd.OutOrStdout()
+			if watch && outputPath != "" {
+				return errors.New("--watch cannot be used with --output")
+			}
+			if outputPath != "" {
+				file, err := createOutputFile(outputPath)
+				if err != nil {
+					return err
+				}
+				defer file.Close() //nolint:errcheck
+				out = file
 			}
 
-			errs := cmd.ErrOrStderr()
-			for _, warn := range result.Warnings {
-				fmt.Fprintf(errs, "warning: %v\n", warn) //nolint:errcheck
+			// Fit the table to the terminal; files and pipes keep the fixed
+			// summary width so their output does not depend on the window.
+			tableWidth := 0
+			if file, ok := out.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+				tableWidth = util.TerminalWidth(file)
+			}
+			if duplicates && len(columns) == 0 {
+				// The copies share every other field, so show where each lives.
+				columns = append(format.DefaultColumnNames(), "path")
+			}
+			if showTags && !hasColumn(columns, "tags") {
+				if len(columns) == 0 {
+					columns = format.DefaultColumnNames()
+				}
+				columns = append(columns, "tags")
 			}
 
-			includeHeader := !noHeader
-			if err := format.WriteSummaries(cmd.OutOrStdout(), result.Summaries, includeHeader, strings.ToLower(formatFlag)); err != nil {
-				return err
+			output := listOutput{
+				count:          countOnly,
+				format:         strings.ToLower(formatFlag),
+				template:       tmpl,
+				warningsFormat: warningsFormat,
+				summary: format.SummaryOptions{
+					Format:          strings.ToLower(formatFlag),
+					IncludeHeader:   !noHeader,
+					GroupBy:         strings.ToLower(groupBy),
+					FullSummary:     fullSummary,
+					SummaryLines:    summaryLines,
+					Time:            timeFormat,
+					Links:           newHyperlinks(hyperlinks, out, sessionURL),
+					MarkInterrupted: markInterrupt,
+					CompactJSON:     compactJSON,
+					Columns:         columns,
+					Duration:        durations,
+					Width:           tableWidth,
+					Tags:            tags,
+				},
 			}
 
+			if watch {
+				return watchList(cmd.Context(), out, watchInterval, func(w io.Writer) error {
+					_, err := writeList(w, cmd.ErrOrStderr(), parser, opts, output)
+					return err
+				})
+			}
+
+			output.progress = newProgressLine(cmd.ErrOrStderr())
+			n, err := writeList(out, cmd.ErrOrStderr(), parser, opts, output)
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return errNoMatch
+			}
 			return nil
 		},
 	}
@@ -173,35 +548,235 @@ func newListCmd() *cobra.Command {
 	flags.BoolVar(&all, "all", false, "include sessions from all directories")
 	flags.StringVar(&afterStr, "after", "", "include sessions starting on/after the given RFC3339 timestamp")
 	flags.StringVar(&beforeStr, "before", "", "include sessions starting on/before the given RFC3339 timestamp")
+	flags.StringVar(&sinceStr, "since", "", "include sessions started within the given duration, e.g. 12h, 7d, or 2w")
+	flags.BoolVar(&today, "today", false, "include sessions started today (in the --local, --utc, or --timezone zone; default local)")
+	flags.BoolVar(&yesterday, "yesterday", false, "include sessions started yesterday")
+	flags.BoolVar(&thisWeek, "this-week", false, "include sessions started this week, from Monday")
 	flags.IntVar(&limit, "limit", 0, "limit number of sessions returned (0 means no limit)")
-	flags.StringVar(&formatFlag, "format", "table", "output format: table, plain, json, or jsonl")
-	flags.BoolVar(&noHeader, "no-header", false, "omit header row for plain output")
+	flags.StringSliceVar(&excludeDirs, "exclude-dir", nil, "skip directories whose name or path under the sessions directory matches a glob, comma-separated or repeated")
+	flags.BoolVar(&uniqueCWD, "unique-cwd", false, "show only the most recent session per working directory (applied before --limit)")
+	flags.BoolVar(&duplicates, "show-duplicates", false, "show only sessions whose id is found in more than one file, listing every copy with its path")
+	flags.BoolVar(&showTags, "show-tags", false, "add a tags column with the tags given by 'agentlog tag'")
+	flags.StringVar(&tagFilter, "tag", "", "only include sessions tagged with this tag (case-insensitive)")
+	flags.IntVar(&pageSize, "page-size", 0, "split the sorted sessions into pages of N (applied after --limit)")
+	flags.IntVar(&page, "page", 0, "with --page-size, show page K (1-based; default 1)")
+	flags.StringVar(&afterID, "after-id", "", "show only sessions sorted after the session with this id, for cursor-style paging (applied before --limit)")
+	flags.StringVar(&beforeID, "before-id", "", "show only sessions sorted before the session with this id (applied before --limit)")
+	flags.StringVar(&formatFlag, "format", "table", "output format: table, plain, json, jsonl, or csv")
+	flags.BoolVar(&compactJSON, "compact-json", false, "write --format json on a single line instead of indenting it")
+	flags.BoolVar(&noHeader, "no-header", false, "omit header row for plain and csv output")
 	flags.IntVar(&summaryWidth, "summary-width", 160, "maximum characters included in the summary column")
-	flags.StringVar(&sessionsDir, "sessions-dir", "", "override the sessions directory (default: agent-specific)")
+	flags.StringVar(&summarySource, "summary-source", "message", "summary text: message (first user message) or summary (Claude's summary entry when present, else the first message)")
+	flags.BoolVar(&fullSummary, "full-summary", false, "show the full first message instead of clipping it to --summary-width")
+	flags.StringVar(&durationFormat, "duration-format", "clock", "duration display: clock (HH:MM:SS) or human (e.g. 1d 3h 10m)")
+	flags.IntVar(&summaryLines, "summary-lines", 0, "show up to N lines of the first message in the table summary column, wrapped to fit (0 keeps one clipped line)")
+	flags.StringSliceVar(&sessionsDirs, "sessions-dir", nil, "sessions directories to search, comma-separated or repeated (default: agent-specific)")
+	flags.BoolVar(&countOnly, "count", false, "print only the number of matching sessions")
+	flags.StringVarP(&outputPath, "output", "o", "", "write output to the named file instead of stdout")
+	flags.StringVar(&warningsFormat, "warnings-format", "text", "format of warnings written to stderr: text or json (one object per line)")
+	flags.BoolVar(&hyperlinks, "hyperlinks", false, "make session IDs in the table clickable (OSC 8) when stdout is a terminal")
+	flags.StringVar(&sessionURL, "hyperlink-url", "", "link target for session IDs with {id} and {path} placeholders (default: the session file)")
+	flags.StringVar(&modelFilter, "model", "", "only include sessions that used a model containing this text")
+	flags.IntVar(&minMessages, "min-messages", 0, "only include sessions with at least N messages")
+	flags.IntVar(&maxMessages, "max-messages", 0, "only include sessions with at most N messages (0 means no limit)")
+	flags.BoolVar(&nonEmpty, "non-empty", false, "exclude sessions without messages (same as --min-messages 1)")
+	flags.StringVar(&minDuration, "min-duration", "", "only include sessions lasting at least this long (e.g. 30s, 10m)")
+	flags.StringVar(&maxDuration, "max-duration", "", "only include sessions lasting at most this long (e.g. 1h)")
+	flags.StringSliceVar(&columns, "columns", nil, "columns of the table, plain, and csv formats, in order: "+strings.Join(format.ColumnNames(), ", "))
+	flags.StringVar(&groupBy, "group-by", "", "group sessions under headers: day or cwd")
+	flags.BoolVar(&markInterrupt, "mark-interrupted", false, "prefix the summary of sessions that ended mid-turn with [interrupted]")
+	flags.BoolVar(&watch, "watch", false, "clear the screen and re-render the list every --interval until interrupted (terminal only)")
+	flags.DurationVar(&watchInterval, "interval", 2*time.Second, "with --watch, time between refreshes")
+	addTemplateFlags(cmd, &templateText, &templateFile, "per session")
+	timeOpts = addTimeFlags(cmd)
 
 	return cmd
 }
 
+// listOutput controls how writeList renders the sessions it finds.
+type listOutput struct {
+	count          bool
+	format         string
+	template       *template.Template
+	warningsFormat string
+	summary        format.SummaryOptions
+	progress       *progressLine // cleared once the scan is done; may be nil
+}
+
+// progressDelay is how long a scan runs before progress is shown, so quick
+// listings never flash a progress line.
+const progressDelay = 500 * time.Millisecond
+
+// progressLine reports scan progress on a single terminal line that is
+// rewritten in place.
+type progressLine struct {
+	out     io.Writer
+	start   time.Time
+	last    time.Time
+	printed bool
+}
+
+// newProgressLine returns a progress line writing to w, or nil when w is
+// not a terminal or --quiet is set.
+func newProgressLine(w io.Writer) *progressLine {
+	file, ok := w.(*os.File)
+	if quiet || !ok || !term.IsTerminal(int(file.Fd())) {
+		return nil
+	}
+	return &progressLine{out: w, start: time.Now()}
+}
+
+// Update shows done out of total, at most every 100ms and only once the
+// scan has run for progressDelay.
+func (p *progressLine) Update(done, total int) {
+	now := time.Now()
+	if now.Sub(p.start) < progressDelay || now.Sub(p.last) < 100*time.Millisecond {
+		return
+	}
+	p.last = now
+	p.printed = true
+	fmt.Fprintf(p.out, "\r\x1b[Kscanned %d/%d sessions…", done, total) //nolint:errcheck
+}
+
+// Clear erases the progress line, if one was shown.
+func (p *progressLine) Clear() {
+	if p == nil || !p.printed {
+		return
+	}
+	fmt.Fprint(p.out, "\r\x1b[K") //nolint:errcheck
+	p.printed = false
+}
+
+// writeList lists sessions once and writes them to out, with warnings to
+// errOut, returning the number of sessions found. list --watch calls it on
+// every refresh.
+func writeList(out, errOut io.Writer, parser model.Parser, opts store.ListOptions, output listOutput) (int, error) {
+	if output.progress != nil {
+		opts.Progress = output.progress.Update
+	}
+	result, err := store.ListSessions(parser, opts)
+	output.progress.Clear()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := format.WriteWarnings(errOut, result.Warnings, output.warningsFormat); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case output.count:
+		if err := format.WriteCount(out, len(result.Summaries), output.format); err != nil {
+			return 0, err
+		}
+	case output.template != nil:
+		for _, item := range result.Summaries {
+			if err := format.RenderTemplate(out, output.template, format.NewTemplateData(item)); err != nil {
+				return 0, err
+			}
+		}
+	default:
+		summaryOpts := output.summary
+		if opts.PageSize > 0 {
+			summaryOpts.Page = &format.PageInfo{Page: opts.Page, PageSize: opts.PageSize, Total: result.Total}
+		}
+		if err := format.WriteSummaries(out, result.Summaries, summaryOpts); err != nil {
+			return 0, err
+		}
+	}
+	return len(result.Summaries), nil
+}
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\x1b[H\x1b[2J"
+
+// watchList calls render every interval, replacing the previous output on
+// the terminal, until ctx is canceled, as main does on an interrupt. Each
+// frame is rendered before the screen is cleared to avoid flicker.
+func watchList(ctx context.Context, out io.Writer, interval time.Duration, render func(io.Writer) error) error {
+	file, ok := out.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return errors.New("--watch requires a terminal")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		var frame bytes.Buffer
+		if err := render(&frame); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(out, clearScreen+frame.String()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
 func newViewCmd() *cobra.Command {
 	var (
 		entryTypeArg    string
 		responseTypeArg string
 		eventMsgTypeArg string
 		payloadRoleArg  string
+		excludeResponse string
+		excludeEventMsg string
+		excludeRole     string
+		maxArgBytes     int
+		maxOutputBytes  int
 		allFilter       bool
 		raw             bool
 		wrap            int
 		maxEvents       int
-		sessionsDir     string
+		firstEvents     int
+		betweenIndex    []int
+		reverseEvents   bool
+		renumber        bool
+		sessionsDirs    []string
 		formatFlag      string
 		forceColor      bool
 		forceNoColor    bool
+		noPager         bool
+		forcePager      bool
+		noLegend        bool
+		pagerCmd        string
+		outputPath      string
+		countOnly       bool
+		showStats       bool
+		wrapModeArg     string
+		ascii           bool
+		tail            int
+		redact          bool
+		redactCWD       bool
+		chain           bool
+		excludeDirs     []string
+		withRole        bool
+		flatTools       bool
+		mergeChunks     bool
+		sanitize        bool
+		modelChanges    bool
+		limitBytes      int
+		instructions    bool
+		fullText        bool
+		roleLabelArgs   map[string]string
+		alignArgs       map[string]string
+		debug           bool
+		noEmptyContent  bool
+		filterExpr      string
+		jsonArray       bool
+		timeOpts        *timeFlags
 	)
 
 	cmd := &cobra.Command{
-		Use:   "view <session-id-or-path>",
+		Use:   "view [session-id-or-path]",
 		Short: "Render a session transcript",
-		Args:  cobra.ExactArgs(1),
+		Long: "Render a session transcript.\n\n" +
+			"When no session is given and the terminal is interactive, a picker lists sessions to choose from.",
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Get agent type and create parser
 			agent := getAgentType()
@@ -211,41 +786,201 @@ func newViewCmd() *cobra.Command {
 			}
 
 			// Use default sessions dir if not provided
-			if sessionsDir == "" {
-				sessionsDir = defaultSessionsDir(agent)
+			if len(sessionsDirs) == 0 {
+				sessionsDirs = []string{model.DefaultSessionsDir(agent)}
+			}
+
+			out := cmd.OutOrStdout()
+			if forceColor && forceNoColor {
+				return errors.New("--color and --no-color cannot be used together")
+			}
+			if forcePager && noPager {
+				return errors.New("--force-pager and --no-pager cannot be used together")
 			}
 
-			path, err := resolveSessionPath(parser, args[0], sessionsDir)
+			timeFormat, err := timeOpts.formatter()
 			if err != nil {
 				return err
 			}
 
-			out := cmd.OutOrStdout()
-			if forceColor && forceNoColor {
-				return errors.New("--color and --no-color cannot be used together")
+			wrapMode, err := format.ParseWrapMode(wrapModeArg)
+			if err != nil {
+				return err
 			}
 
-			// Check for exclusive flag usage
-			if allFilter && (entryTypeArg != "" || responseTypeArg != "" || eventMsgTypeArg != "" || payloadRoleArg != "") {
-				return errors.New("--all cannot be used with -E, -T, -M, or -R flags")
+			var path string
+			if len(args) == 0 {
+				path, err = pickSession(parser, sessionsDirs, timeFormat)
+			} else {
+				path, err = resolveSessionPath(parser, args[0], sessionsDirs)
+			}
+			if err != nil {
+				return err
+			}
+
+			if countOnly && raw {
+				return errors.New("--count cannot be used with --raw")
+			}
+			if tail < 0 {
+				return errors.New("--tail must not be negative")
+			}
+			if tail > 0 && raw {
+				return errors.New("--tail cannot be used with --raw")
+			}
+			if firstEvents < 0 {
+				return errors.New("--first must not be negative")
+			}
+			if firstEvents > 0 && (maxEvents > 0 || tail > 0) {
+				return errors.New("--first cannot be combined with --max or --tail")
+			}
+			if firstEvents > 0 && raw {
+				return errors.New("--first cannot be used with --raw")
+			}
+			var betweenStart, betweenEnd int
+			if cmd.Flags().Changed("between-index") {
+				if len(betweenIndex) != 2 {
+					return errors.New("--between-index takes a start and an end position, e.g. 3,5")
+				}
+				betweenStart, betweenEnd = betweenIndex[0], betweenIndex[1]
+				if betweenStart < 1 || betweenStart > betweenEnd {
+					return errors.New("--between-index positions must satisfy 1 <= start <= end")
+				}
+				if firstEvents > 0 || maxEvents > 0 {
+					return errors.New("--between-index cannot be combined with --first or --max")
+				}
+				if raw {
+					return errors.New("--between-index cannot be used with --raw")
+				}
+			}
+			if reverseEvents && raw {
+				return errors.New("--reverse-events cannot be used with --raw")
+			}
+			if renumber && !reverseEvents {
+				return errors.New("--renumber requires --reverse-events")
+			}
+			if redact && raw {
+				return errors.New("--redact cannot be used with --raw; use --format raw instead")
+			}
+			if maxArgBytes < 0 || maxOutputBytes < 0 {
+				return errors.New("--max-arg-bytes and --max-output-bytes must not be negative")
+			}
+			if limitBytes < 0 {
+				return errors.New("--limit-bytes must not be negative")
+			}
+			if redactCWD && !redact {
+				return errors.New("--redact-cwd requires --redact")
+			}
+			if chain && tail > 0 {
+				return errors.New("--chain cannot be used with --tail")
+			}
+			if len(excludeDirs) > 0 && !chain {
+				return errors.New("--exclude-dir requires --chain")
+			}
+			if withRole && strings.ToLower(formatFlag) != "content" {
+				return errors.New("--with-role requires --format content")
+			}
+			if jsonArray && strings.ToLower(formatFlag) != "json" {
+				return errors.New("--json-array requires --format json")
+			}
+			if fullText && !instructions {
+				return errors.New("--full requires --show-instructions")
+			}
+			roleLabels, err := view.ParseRoleLabels(roleLabelArgs)
+			if err != nil {
+				return fmt.Errorf("invalid --role-label value: %w", err)
+			}
+			alignments, err := view.ParseRoleAlignments(alignArgs)
+			if err != nil {
+				return fmt.Errorf("invalid --align value: %w", err)
+			}
+			var filter *view.FilterExpr
+			if filterExpr != "" {
+				filter, err = view.ParseFilterExpr(filterExpr)
+				if err != nil {
+					return fmt.Errorf("invalid --filter-expr value: %w", err)
+				}
+			}
+
+			var chainPaths []string
+			if chain {
+				meta, err := parser.ReadSessionMeta(path)
+				if err != nil {
+					return err
+				}
+				chainPaths, err = store.ResolveChain(parser, store.ChainOptions{
+					Roots:          sessionsDirs,
+					FollowSymlinks: followSymlinks,
+					ExcludeDirs:    excludeDirs,
+				}, meta.GetID())
+				if err != nil {
+					return fmt.Errorf("resolve chain: %w", err)
+				}
+			}
+
+			if outputPath != "" {
+				file, err := createOutputFile(outputPath)
+				if err != nil {
+					return err
+				}
+				defer file.Close() //nolint:errcheck
+				out = file
+				noPager = true
 			}
 
 			outFile, _ := out.(*os.File)
 			return view.Run(parser, view.Options{
-				Path:            path,
-				Format:          formatFlag,
-				Wrap:            wrap,
-				MaxEvents:       maxEvents,
-				EntryTypeArg:    entryTypeArg,
-				ResponseTypeArg: responseTypeArg,
-				EventMsgTypeArg: eventMsgTypeArg,
-				PayloadRoleArg:  payloadRoleArg,
-				AllFilter:       allFilter,
-				ForceColor:      forceColor,
-				ForceNoColor:    forceNoColor,
-				RawFile:         raw,
-				Out:             out,
-				OutFile:         outFile,
+				Path:                   path,
+				Chain:                  chainPaths,
+				Format:                 formatFlag,
+				Wrap:                   wrap,
+				WrapMode:               wrapMode,
+				MaxEvents:              maxEvents,
+				First:                  firstEvents,
+				BetweenStart:           betweenStart,
+				BetweenEnd:             betweenEnd,
+				Reverse:                reverseEvents,
+				Renumber:               renumber,
+				Tail:                   tail,
+				MaxArgBytes:            maxArgBytes,
+				MaxOutputBytes:         maxOutputBytes,
+				EntryTypeArg:           entryTypeArg,
+				ResponseTypeArg:        responseTypeArg,
+				EventMsgTypeArg:        eventMsgTypeArg,
+				PayloadRoleArg:         payloadRoleArg,
+				ExcludeResponseTypeArg: excludeResponse,
+				ExcludeEventMsgTypeArg: excludeEventMsg,
+				ExcludePayloadRoleArg:  excludeRole,
+				AllFilter:              allFilter,
+				ForceColor:             forceColor,
+				ForceNoColor:           forceNoColor,
+				ASCII:                  ascii,
+				RawFile:                raw,
+				Count:                  countOnly,
+				Stats:                  showStats,
+				Redact:                 redact,
+				RedactCWD:              redactCWD,
+				NoPager:                noPager,
+				ForcePager:             forcePager,
+				NoLegend:               noLegend,
+				WithRole:               withRole,
+				FlatTools:              flatTools,
+				MergeConsecutive:       mergeChunks,
+				KeepControl:            !sanitize,
+				ShowModelChanges:       modelChanges,
+				LimitBytes:             limitBytes,
+				ShowInstructions:       instructions,
+				FullInstructions:       fullText,
+				Debug:                  debug,
+				NoEmptyContent:         noEmptyContent,
+				JSONArray:              jsonArray,
+				Pager:                  pagerCmd,
+				Time:                   timeFormat,
+				RoleLabels:             roleLabels,
+				Alignments:             alignments,
+				FilterExpr:             filter,
+				Out:                    out,
+				OutFile:                outFile,
+				Context:                cmd.Context(),
 			})
 		},
 	}
@@ -255,36 +990,126 @@ func newViewCmd() *cobra.Command {
 	flags.StringVarP(&responseTypeArg, "response-type", "T", "", "comma-separated response_item payload types (default: message)")
 	flags.StringVarP(&eventMsgTypeArg, "event-msg-type", "M", "", "comma-separated event_msg payload types (default: none)")
 	flags.StringVarP(&payloadRoleArg, "payload-role", "R", "", "comma-separated payload roles to include (default: user,assistant; use 'all' for every role)")
-	flags.BoolVar(&allFilter, "all", false, "show all entries (overrides -E, -T, -M, and -R)")
+	flags.StringVar(&excludeResponse, "exclude-response-type", "", "comma-separated response_item payload types to drop from the -T filter")
+	flags.StringVar(&excludeEventMsg, "exclude-event-msg-type", "", "comma-separated event_msg payload types to drop from the -M filter")
+	flags.StringVar(&excludeRole, "exclude-payload-role", "", "comma-separated payload roles to drop from the -R filter")
+	flags.BoolVar(&allFilter, "all", false, "show all entries; explicit -E, -T, -M, and -R still apply")
 	flags.BoolVar(&raw, "raw", false, "output raw JSONL without formatting")
 	flags.IntVar(&wrap, "wrap", 0, "wrap message body at the given column width")
+	flags.StringVar(&wrapModeArg, "wrap-mode", "word", "how to break long lines: word, char, or none")
 	flags.IntVar(&maxEvents, "max", 0, "show only the most recent N events (0 means no limit)")
-	flags.StringVar(&sessionsDir, "sessions-dir", "", "override the sessions directory (default: agent-specific)")
-	flags.StringVar(&formatFlag, "format", "text", "output format: text, chat, or raw")
+	flags.IntVar(&firstEvents, "first", 0, "show only the first N matching events and stop reading (0 means no limit)")
+	flags.IntVar(&firstEvents, "head", 0, "alias for --first")
+	_ = flags.MarkHidden("head")
+	flags.IntSliceVar(&betweenIndex, "between-index", nil, "show only the matching events at positions A through B (1-based, inclusive), given as A,B")
+	flags.BoolVar(&reverseEvents, "reverse-events", false, "show events newest first, after filtering and --max/--tail selection")
+	flags.BoolVar(&renumber, "renumber", false, "with --reverse-events, number events in display order instead of chronologically")
+	flags.IntVar(&maxArgBytes, "max-arg-bytes", 0, "truncate function call arguments longer than N bytes (0 means no limit)")
+	flags.IntVar(&maxOutputBytes, "max-output-bytes", 0, "truncate function call outputs longer than N bytes (0 means no limit)")
+	flags.IntVar(&tail, "tail", 0, "read only the last N lines of the file before filtering (fast on large files)")
+	flags.StringSliceVar(&sessionsDirs, "sessions-dir", nil, "sessions directories to search, comma-separated or repeated (default: agent-specific)")
+	flags.StringVar(&formatFlag, "format", "text", "output format: text, chat, raw, jsonl, json, or content")
 	flags.BoolVar(&forceColor, "color", false, "force-enable ANSI colors even when stdout is not a TTY")
 	flags.BoolVar(&forceNoColor, "no-color", false, "disable ANSI colors regardless of terminal detection")
+	flags.BoolVar(&ascii, "ascii", false, "draw chat bubbles with ASCII characters (automatic when TERM=dumb or the locale is not UTF-8)")
+	flags.BoolVar(&redact, "redact", false, "replace the home directory with ~ and mask API keys and tokens")
+	flags.BoolVar(&redactCWD, "redact-cwd", false, "with --redact, also replace the session working directory with <cwd>")
+	flags.BoolVar(&noPager, "no-pager", false, "write chat output directly instead of piping it through a pager")
+	flags.BoolVar(&forcePager, "force-pager", false, "pipe chat output through the pager even when stdout is not a TTY")
+	flags.BoolVar(&noLegend, "no-legend", false, "omit the session header and role color legend shown above chat output on a terminal")
+	flags.StringVar(&pagerCmd, "pager", "", "pager command for chat output (env: AGENTLOG_PAGER, PAGER; default: less)")
+	flags.StringVarP(&outputPath, "output", "o", "", "write output to the named file instead of stdout (disables color unless --color and never pages)")
+	flags.BoolVar(&countOnly, "count", false, "print only the number of matching events (use --format json for {\"count\": N})")
+	flags.BoolVar(&showStats, "stats", false, "append a footer with event counts, token totals, and the time span (text and chat formats)")
+	flags.BoolVar(&jsonArray, "json-array", false, "with --format json, write the events as one JSON array instead of one object per line")
+	flags.BoolVar(&withRole, "with-role", false, "with --format content, prefix each message body with its role, e.g. \"user: \"")
+	flags.StringToStringVar(&roleLabelArgs, "role-label", nil, "show a role under another name in event headers, e.g. user=You,assistant=AI")
+	flags.StringToStringVar(&alignArgs, "align", nil, "draw a role's chat bubbles on the left, right, or center, e.g. user=left,assistant=right")
+	flags.BoolVar(&sanitize, "sanitize", true, "strip control characters that could inject terminal escape sequences from message bodies (--sanitize=false keeps them)")
+	flags.BoolVar(&instructions, "show-instructions", false, "show the instructions (system prompt) recorded by Codex above the events (text and chat formats)")
+	flags.BoolVar(&fullText, "full", false, "with --show-instructions, show the whole instructions instead of the first 1000 bytes")
+	flags.IntVar(&limitBytes, "limit-bytes", 0, "stop after writing N bytes of output and note the truncation (0 means no limit)")
+	flags.BoolVar(&modelChanges, "show-model-changes", false, "insert a divider where the session switches to another model (text and chat formats)")
+	flags.BoolVar(&noEmptyContent, "no-empty-content", false, "skip events with nothing to show, such as encrypted reasoning, instead of printing \"(no content)\"")
+	flags.StringVar(&filterExpr, "filter-expr", "", "show only events matching a boolean expression over role, type, text, and timestamp, e.g. 'role == user && contains(text, \"error\")'")
+	flags.BoolVar(&debug, "debug", false, "print the raw JSON line under each event, e.g. to report a parser bug (text format)")
+	flags.BoolVar(&flatTools, "flat-tools", false, "render each tool call together with its output instead of as separate events")
+	flags.BoolVar(&mergeChunks, "merge-consecutive", false, "render consecutive messages from the same role, such as a reply streamed in chunks, as one message")
+	flags.BoolVar(&chain, "chain", false, "render the session together with the sessions it resumes and that resume it, oldest first")
+	flags.StringSliceVar(&excludeDirs, "exclude-dir", nil, "with --chain, skip directories whose name or path under the sessions directory matches a glob, comma-separated or repeated")
+	timeOpts = addTimeFlags(cmd)
 
 	return cmd
 }
 
 type infoPayload struct {
-	SessionID       string `json:"session_id"`
-	JSONLPath       string `json:"jsonl_path"`
-	StartedAt       string `json:"started_at"`
-	CWD             string `json:"cwd"`
-	Originator      string `json:"originator"`
-	CLIVersion      string `json:"cli_version"`
-	MessageCount    int    `json:"message_count"`
-	DurationSeconds int    `json:"duration_seconds"`
-	DurationDisplay string `json:"duration_display"`
-	Summary         string `json:"summary"`
+	SessionID            string `json:"session_id" yaml:"session_id"`
+	JSONLPath            string `json:"jsonl_path" yaml:"jsonl_path"`
+	StartedAt            string `json:"started_at" yaml:"started_at"`
+	CWD                  string `json:"cwd" yaml:"cwd"`
+	Originator           string `json:"originator" yaml:"originator"`
+	CLIVersion           string `json:"cli_version" yaml:"cli_version"`
+	Model                string `json:"model,omitempty" yaml:"model,omitempty"`
+	Effort               string `json:"effort,omitempty" yaml:"effort,omitempty"`
+	MessageCount         int    `json:"message_count" yaml:"message_count"`
+	DurationSeconds      int    `json:"duration_seconds" yaml:"duration_seconds"`
+	DurationDisplay      string `json:"duration_display" yaml:"duration_display"`
+	FirstResponseSeconds *int   `json:"first_response_seconds,omitempty" yaml:"first_response_seconds,omitempty"`
+	Interrupted          bool   `json:"interrupted" yaml:"interrupted"`
+	Summary              string `json:"summary" yaml:"summary"`
+	Instructions         string `json:"instructions,omitempty" yaml:"instructions,omitempty"`
+	Agent                string `json:"agent" yaml:"agent"`
+	AgentMeta            any    `json:"agent_meta,omitempty" yaml:"agent_meta,omitempty"`
+}
+
+// codexAgentMeta is the agent_meta of a Codex session in info output.
+type codexAgentMeta struct {
+	Originator string `json:"originator,omitempty" yaml:"originator,omitempty"`
+	CLIVersion string `json:"cli_version,omitempty" yaml:"cli_version,omitempty"`
+	Model      string `json:"model,omitempty" yaml:"model,omitempty"`
+	Effort     string `json:"effort,omitempty" yaml:"effort,omitempty"`
+	ParentID   string `json:"parent_id,omitempty" yaml:"parent_id,omitempty"`
+}
+
+// claudeAgentMeta is the agent_meta of a Claude Code session in info output.
+type claudeAgentMeta struct {
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// setAgentMeta fills the fields of payload that only one agent records,
+// keeping them in agent_meta as the agent names them. Model and Effort must
+// already be set.
+func setAgentMeta(payload *infoPayload, meta model.SessionMetaProvider) {
+	switch meta := meta.(type) {
+	case *codex.CodexSessionMeta:
+		payload.Originator = meta.Originator
+		payload.CLIVersion = meta.CLIVersion
+		payload.AgentMeta = codexAgentMeta{
+			Originator: meta.Originator,
+			CLIVersion: meta.CLIVersion,
+			Model:      payload.Model,
+			Effort:     payload.Effort,
+			ParentID:   meta.ParentID,
+		}
+	case *claude.ClaudeSessionMeta:
+		payload.CLIVersion = meta.Version
+		payload.AgentMeta = claudeAgentMeta{Version: meta.Version}
+	}
 }
 
 func newInfoCmd() *cobra.Command {
 	var (
-		formatFlag  string
-		summaryMode string
-		sessionsDir string
+		formatFlag   string
+		summaryMode  string
+		durationFmt  string
+		sessionsDirs []string
+		hyperlinks   bool
+		templateText string
+		templateFile string
+		compactJSON  bool
+		instructions bool
+		fullText     bool
+		timeOpts     *timeFlags
 	)
 
 	cmd := &cobra.Command{
@@ -300,11 +1125,31 @@ func newInfoCmd() *cobra.Command {
 			}
 
 			// Use default sessions dir if not provided
-			if sessionsDir == "" {
-				sessionsDir = defaultSessionsDir(agent)
+			if len(sessionsDirs) == 0 {
+				sessionsDirs = []string{model.DefaultSessionsDir(agent)}
 			}
 
-			path, err := resolveSessionPath(parser, args[0], sessionsDir)
+			timeFormat, err := timeOpts.formatter()
+			if err != nil {
+				return err
+			}
+			durations, err := format.ParseDurationFormat(durationFmt)
+			if err != nil {
+				return fmt.Errorf("invalid --duration-format value: %w", err)
+			}
+
+			tmpl, err := loadTemplate(templateText, templateFile, timeFormat)
+			if err != nil {
+				return err
+			}
+			if tmpl != nil && cmd.Flags().Changed("format") {
+				return errors.New("--template cannot be used with --format")
+			}
+			if fullText && !instructions {
+				return errors.New("--full requires --show-instructions")
+			}
+
+			path, err := resolveSessionPath(parser, args[0], sessionsDirs)
 			if err != nil {
 				return err
 			}
@@ -319,14 +1164,31 @@ func newInfoCmd() *cobra.Command {
 				return err
 			}
 
-			// Count messages and find last timestamp
-			var count int
-			var lastTimestamp time.Time
+			count, err := parser.CountMessages(path)
+			if err != nil {
+				return err
+			}
+
+			// Find last timestamp, the most recent model and effort, and whether the last turn finished
+			var (
+				lastTimestamp time.Time
+				modelName     string
+				effort        string
+				turns         store.TurnTracker
+				firstResponse store.FirstResponseTracker
+			)
 			err = parser.IterateEvents(path, func(event model.EventProvider) error {
-				count++
 				if !event.GetTimestamp().IsZero() && event.GetTimestamp().After(lastTimestamp) {
 					lastTimestamp = event.GetTimestamp()
 				}
+				if provider, ok := event.(model.ModelProvider); ok && provider.GetModel() != "" {
+					modelName = provider.GetModel()
+				}
+				if provider, ok := event.(model.EffortProvider); ok && provider.GetEffort() != "" {
+					effort = provider.GetEffort()
+				}
+				turns.Observe(event)
+				firstResponse.Observe(event)
 				return nil
 			})
 			if err != nil {
@@ -336,7 +1198,7 @@ func newInfoCmd() *cobra.Command {
 			if lastTimestamp.IsZero() || lastTimestamp.Before(meta.GetStartedAt()) {
 				lastTimestamp = meta.GetStartedAt()
 			}
-			duration := durationSeconds(meta.GetStartedAt(), lastTimestamp)
+			duration := util.DurationSeconds(meta.GetStartedAt(), lastTimestamp)
 
 			summaryMode = strings.ToLower(summaryMode)
 			switch summaryMode {
@@ -346,30 +1208,61 @@ func newInfoCmd() *cobra.Command {
 				return fmt.Errorf("invalid --summary value: %s", summaryMode)
 			}
 
-			summarySnippet := collapseWhitespace(summary)
+			summarySnippet := util.CollapseWhitespace(summary)
 			if summaryMode != "full" {
-				summarySnippet = clipSummary(summarySnippet, 160)
+				summarySnippet = util.ClipRunes(summarySnippet, 160)
 			}
 
 			payload := infoPayload{
 				SessionID:       meta.GetID(),
 				JSONLPath:       path,
-				StartedAt:       meta.GetStartedAt().Format(time.RFC3339),
+				StartedAt:       meta.GetStartedAt().UTC().Format(time.RFC3339),
 				CWD:             meta.GetCWD(),
+				Model:           modelName,
+				Effort:          effort,
 				MessageCount:    count,
 				DurationSeconds: duration,
-				DurationDisplay: formatDuration(duration),
+				DurationDisplay: durations.Format(duration),
+				Interrupted:     turns.Interrupted(),
 				Summary:         summary,
+				Agent:           string(agent),
+			}
+			setAgentMeta(&payload, meta)
+
+			if latency, ok := firstResponse.Latency(); ok {
+				seconds := int(latency / time.Second)
+				payload.FirstResponseSeconds = &seconds
+			}
+			if provider, ok := meta.(model.InstructionsProvider); ok && instructions {
+				payload.Instructions = provider.GetInstructions()
+				if !fullText {
+					payload.Instructions = format.TruncateBytes(payload.Instructions, format.InstructionsPreviewBytes)
+				}
+			}
+
+			if tmpl != nil {
+				return format.RenderTemplate(cmd.OutOrStdout(), tmpl, format.TemplateData{
+					ID:              payload.SessionID,
+					Path:            path,
+					CWD:             payload.CWD,
+					StartedAt:       meta.GetStartedAt(),
+					Summary:         summary,
+					MessageCount:    count,
+					DurationSeconds: duration,
+					Model:           modelName,
+					Effort:          payload.Effort,
+				})
 			}
 
 			switch strings.ToLower(formatFlag) {
 			case "json":
-				enc := json.NewEncoder(cmd.OutOrStdout())
-				enc.SetIndent("", "  ")
-				return enc.Encode(payload)
+				return format.NewJSONEncoder(cmd.OutOrStdout(), compactJSON).Encode(payload)
+			case "yaml":
+				return writeYAML(cmd.OutOrStdout(), payload)
 			case "text":
 				out := cmd.OutOrStdout()
-				renderInfoText(out, payload, summarySnippet)
+				startedAt := timeFormat.Format(meta.GetStartedAt())
+				renderInfoText(out, payload, startedAt, summarySnippet, durations, newHyperlinks(hyperlinks, out, ""))
 				return nil
 			default:
 				return fmt.Errorf("unsupported format: %s", formatFlag)
@@ -378,14 +1271,324 @@ func newInfoCmd() *cobra.Command {
 	}
 
 	flags := cmd.Flags()
-	flags.StringVar(&formatFlag, "format", "text", "output format: text or json")
+	flags.StringVar(&formatFlag, "format", "text", "output format: text, json, or yaml")
+	flags.BoolVar(&compactJSON, "compact-json", false, "write --format json on a single line instead of indenting it")
 	flags.StringVar(&summaryMode, "summary", "clip", "summary display: clip or full")
-	flags.StringVar(&sessionsDir, "sessions-dir", "", "override the sessions directory (default: agent-specific)")
+	flags.StringVar(&durationFmt, "duration-format", "clock", "duration display: clock (HH:MM:SS) or human (e.g. 1d 3h 10m)")
+	flags.BoolVar(&instructions, "show-instructions", false, "include the instructions (system prompt) recorded by Codex")
+	flags.BoolVar(&fullText, "full", false, "with --show-instructions, include the whole instructions instead of the first 1000 bytes")
+	flags.BoolVar(&hyperlinks, "hyperlinks", false, "make file paths clickable (OSC 8) when stdout is a terminal")
+	flags.StringSliceVar(&sessionsDirs, "sessions-dir", nil, "sessions directories to search, comma-separated or repeated (default: agent-specific)")
+	addTemplateFlags(cmd, &templateText, &templateFile, "once")
+	timeOpts = addTimeFlags(cmd)
+
+	return cmd
+}
+
+func newDoctorCmd() *cobra.Command {
+	var (
+		formatFlag   string
+		sessionsDirs []string
+		excludeDirs  []string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "doctor",
+		Aliases: []string{"validate"},
+		Short:   "Check the sessions directory for broken or suspicious logs",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// Get agent type and create parser
+			agent := getAgentType()
+			parser, err := model.NewParser(agent)
+			if err != nil {
+				return fmt.Errorf("create parser: %w", err)
+			}
+
+			// Use default sessions dir if not provided
+			if len(sessionsDirs) == 0 {
+				sessionsDirs = []string{model.DefaultSessionsDir(agent)}
+			}
+
+			result, err := store.ValidateSessions(parser, store.ValidateOptions{
+				Roots:          sessionsDirs,
+				FollowSymlinks: followSymlinks,
+				ExcludeDirs:    excludeDirs,
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := format.WriteIssues(cmd.OutOrStdout(), result, formatFlag); err != nil {
+				return err
+			}
+
+			if n := result.ErrorCount(); n > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%w: %d error(s) under %s", errIssuesFound, n, strings.Join(sessionsDirs, ", "))
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&formatFlag, "format", "table", "output format: table or json")
+	flags.StringSliceVar(&sessionsDirs, "sessions-dir", nil, "sessions directories to search, comma-separated or repeated (default: agent-specific)")
+	flags.StringSliceVar(&excludeDirs, "exclude-dir", nil, "skip directories whose name or path under the sessions directory matches a glob, comma-separated or repeated")
+
+	return cmd
+}
+
+// defaultResumeTemplates holds the command each agent uses to continue a
+// session. {id} and {cwd} are replaced with the shell-quoted session values.
+var defaultResumeTemplates = map[model.AgentType]string{
+	model.AgentCodex:  "codex resume {id}",
+	model.AgentClaude: "claude --resume {id}",
+}
+
+func newResumeCmd() *cobra.Command {
+	var (
+		sessionsDirs []string
+		execute      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "resume <session-id-or-path>",
+		Aliases: []string{"open"},
+		Short:   "Print the command that resumes a session in its original tool",
+		Long: "Print the command that resumes a session in its original tool.\n\n" +
+			"The command defaults to 'codex resume {id}' or 'claude --resume {id}' and can be\n" +
+			"changed with AGENTLOG_RESUME_COMMAND, where {id} and {cwd} are replaced with the\n" +
+			"session ID and working directory.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get agent type and create parser
+			agent := getAgentType()
+			parser, err := model.NewParser(agent)
+			if err != nil {
+				return fmt.Errorf("create parser: %w", err)
+			}
+
+			// Use default sessions dir if not provided
+			if len(sessionsDirs) == 0 {
+				sessionsDirs = []string{model.DefaultSessionsDir(agent)}
+			}
+
+			path, err := resolveSessionPath(parser, args[0], sessionsDirs)
+			if err != nil {
+				return err
+			}
+
+			meta, err := parser.ReadSessionMeta(path)
+			if err != nil {
+				return err
+			}
+
+			command, err := resumeCommand(agent, os.Getenv("AGENTLOG_RESUME_COMMAND"), meta.GetID(), meta.GetCWD())
+			if err != nil {
+				return err
+			}
+
+			if !execute {
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), command)
+				return err
+			}
+
+			run := exec.Command("sh", "-c", command) // #nosec G204
+			run.Stdin = os.Stdin
+			run.Stdout = cmd.OutOrStdout()
+			run.Stderr = cmd.ErrOrStderr()
+			if info, err := os.Stat(meta.GetCWD()); err == nil && info.IsDir() {
+				run.Dir = meta.GetCWD()
+			}
+			if err := run.Run(); err != nil {
+				return fmt.Errorf("run %q: %w", command, err)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&execute, "exec", false, "run the command in the session's working directory instead of printing it")
+	flags.StringSliceVar(&sessionsDirs, "sessions-dir", nil, "sessions directories to search, comma-separated or repeated (default: agent-specific)")
+
+	return cmd
+}
+
+func newPathCmd() *cobra.Command {
+	var sessionsDirs []string
+
+	cmd := &cobra.Command{
+		Use:   "path <session-id-or-prefix>",
+		Short: "Print the absolute path of a session file",
+		Long: "Print the absolute path of a session file, for use in scripts such as\n" +
+			"'vim $(agentlog path 0193a4b2)'. The session is resolved like 'info'.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get agent type and create parser
+			agent := getAgentType()
+			parser, err := model.NewParser(agent)
+			if err != nil {
+				return fmt.Errorf("create parser: %w", err)
+			}
+
+			// Use default sessions dir if not provided
+			if len(sessionsDirs) == 0 {
+				sessionsDirs = []string{model.DefaultSessionsDir(agent)}
+			}
+
+			path, err := resolveSessionPath(parser, args[0], sessionsDirs)
+			if err != nil {
+				return err
+			}
+
+			absPath, err := filepath.Abs(path)
+			if err != nil {
+				return fmt.Errorf("resolve path: %w", err)
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), absPath)
+			return err
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&sessionsDirs, "sessions-dir", nil, "sessions directories to search, comma-separated or repeated (default: agent-specific)")
 
 	return cmd
 }
 
-func resolveSessionPath(parser model.Parser, arg, root string) (string, error) {
+func newTagCmd() *cobra.Command {
+	var (
+		sessionsDirs []string
+		remove       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tag <session-id-or-prefix> [tag...]",
+		Short: "Add, remove, or print the tags of a session",
+		Long: "Tag a session with short notes, such as 'agentlog tag 0193a4b2 \"important bugfix\"'.\n" +
+			"Tags are kept in $XDG_CONFIG_HOME/agentlog/tags.json (or the file named by\n" +
+			"AGENTLOG_TAGS_FILE), keyed by session id, and shown by 'list --show-tags'.\n" +
+			"Without tags, the session's current tags are printed one per line.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get agent type and create parser
+			agent := getAgentType()
+			parser, err := model.NewParser(agent)
+			if err != nil {
+				return fmt.Errorf("create parser: %w", err)
+			}
+
+			// Use default sessions dir if not provided
+			if len(sessionsDirs) == 0 {
+				sessionsDirs = []string{model.DefaultSessionsDir(agent)}
+			}
+
+			path, err := resolveSessionPath(parser, args[0], sessionsDirs)
+			if err != nil {
+				return err
+			}
+			meta, err := parser.ReadSessionMeta(path)
+			if err != nil {
+				return fmt.Errorf("read session meta: %w", err)
+			}
+			id := meta.GetID()
+
+			tagsPath := store.DefaultTagsPath()
+			tags, err := store.LoadTags(tagsPath)
+			if err != nil {
+				return err
+			}
+			labels := args[1:]
+			switch {
+			case remove:
+				tags.Remove(id, labels...)
+			case len(labels) > 0:
+				tags.Merge(store.Tags{id: labels})
+			default:
+				for _, tag := range tags[id] {
+					fmt.Fprintln(cmd.OutOrStdout(), tag) //nolint:errcheck
+				}
+				return nil
+			}
+			return store.SaveTags(tagsPath, tags)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&sessionsDirs, "sessions-dir", nil, "sessions directories to search, comma-separated or repeated (default: agent-specific)")
+	flags.BoolVar(&remove, "remove", false, "remove the given tags, or every tag of the session when none are given")
+
+	return cmd
+}
+
+// hasColumn reports whether names, as given to --columns, include name.
+func hasColumn(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(strings.TrimSpace(n), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// resumeCommand fills template, or the agent's default template when it is
+// empty, with the session ID and working directory.
+func resumeCommand(agent model.AgentType, template, id, cwd string) (string, error) {
+	if template == "" {
+		template = defaultResumeTemplates[agent]
+	}
+	if template == "" {
+		return "", fmt.Errorf("no resume command known for agent %q; set AGENTLOG_RESUME_COMMAND", agent)
+	}
+	if id == "" {
+		return "", errors.New("session has no id to resume")
+	}
+	replacer := strings.NewReplacer("{id}", shellQuote(id), "{cwd}", shellQuote(cwd))
+	return replacer.Replace(template), nil
+}
+
+// shellQuote single-quotes s for sh unless it only contains characters that
+// need no quoting.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:@+=") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// pickSession lets the user choose a session interactively when no
+// identifier was given on the command line.
+func pickSession(parser model.Parser, roots []string, timeFormat format.TimeFormatter) (string, error) {
+	if !tui.IsInteractive(os.Stdin, os.Stdout) {
+		return "", errors.New("session id is required when not running in a terminal")
+	}
+
+	result, err := store.ListSessions(parser, store.ListOptions{Roots: roots, MaxSummary: 160, FollowSymlinks: followSymlinks})
+	if err != nil {
+		return "", err
+	}
+
+	choice, err := tui.Pick(tui.CandidatesFromSummaries(result.Summaries), tui.PickOptions{
+		In:   os.Stdin,
+		Out:  os.Stdout,
+		Time: timeFormat,
+	})
+	if err != nil {
+		return "", err
+	}
+	return choice.Path, nil
+}
+
+// createOutputFile creates or truncates the file named by --output.
+func createOutputFile(path string) (*os.File, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("open output file: %w", err)
+	}
+	return file, nil
+}
+
+func resolveSessionPath(parser model.Parser, arg string, roots []string) (string, error) {
 	if arg == "" {
 		return "", errors.New("session identifier is empty")
 	}
@@ -394,78 +1597,129 @@ func resolveSessionPath(parser model.Parser, arg, root string) (string, error) {
 		return arg, nil
 	}
 
-	candidate := filepath.Join(root, arg)
-	if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
-		return candidate, nil
+	for _, root := range roots {
+		candidate := filepath.Join(root, arg)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
 	}
 
-	return store.FindSessionPath(parser, root, arg)
+	return store.FindSessionPath(parser, roots, arg, followSymlinks)
 }
 
-// Note: The old defaultSessionsDir() has been replaced by defaultSessionsDir(agentType) above
-
-func oldDefaultSessionsDir() string {
-	if dir := os.Getenv("AGENTLOG_SESSIONS_DIR"); dir != "" {
-		return dir
+// parseDurationFlag parses a Go duration flag value; empty means unset.
+func parseDurationFlag(name, value string) (*time.Duration, error) {
+	if value == "" {
+		return nil, nil
 	}
-	home, err := os.UserHomeDir()
+	d, err := time.ParseDuration(value)
 	if err != nil {
-		return ""
+		return nil, fmt.Errorf("invalid %s value: %w", name, err)
 	}
-	return filepath.Join(home, ".codex", "sessions")
+	if d < 0 {
+		return nil, fmt.Errorf("invalid %s value: must not be negative", name)
+	}
+	return &d, nil
 }
 
-func durationSeconds(start, end time.Time) int {
-	if start.IsZero() || end.IsZero() {
-		return 0
-	}
-	if end.Before(start) {
-		return 0
+// calendarWindow returns the first and last instant of the --today,
+// --yesterday, or --this-week window around now, in now's location. Weeks
+// start on Monday.
+func calendarWindow(name string, now time.Time) (start, end time.Time) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch name {
+	case "yesterday":
+		start, end = midnight.AddDate(0, 0, -1), midnight
+	case "this-week":
+		start = midnight.AddDate(0, 0, -(int(now.Weekday())+6)%7)
+		end = start.AddDate(0, 0, 7)
+	default:
+		start, end = midnight, midnight.AddDate(0, 0, 1)
 	}
-	return int(end.Sub(start).Seconds())
+	// --before is inclusive, so stop just short of the next window.
+	return start, end.Add(-time.Nanosecond)
 }
 
-func formatDuration(seconds int) string {
-	if seconds <= 0 {
-		return "00:00:00"
+// parseRelativeDuration parses a Go duration, also accepting a whole number
+// of days or weeks such as "7d" or "2w".
+func parseRelativeDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	var d time.Duration
+	if unit := strings.TrimLeft(value, "0123456789"); (unit == "d" || unit == "w") && len(unit) < len(value) {
+		n, err := strconv.Atoi(strings.TrimSuffix(value, unit))
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(n) * 24 * time.Hour
+		if unit == "w" {
+			d *= 7
+		}
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, err
+		}
+		d = parsed
 	}
-	h := seconds / 3600
-	m := (seconds % 3600) / 60
-	s := seconds % 60
-	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive: %s", value)
+	}
+	return d, nil
 }
 
-func renderInfoText(out io.Writer, payload infoPayload, summarySnippet string) {
+func renderInfoText(out io.Writer, payload infoPayload, startedAt, summarySnippet string, durations format.DurationFormat, links format.Hyperlinks) {
 	const labelWidth = 14
 	writeKV(out, labelWidth, "Session ID", payload.SessionID)
-	writeKV(out, labelWidth, "Started At", payload.StartedAt)
+	writeKV(out, labelWidth, "Started At", startedAt)
 	writeKV(out, labelWidth, "Duration", payload.DurationDisplay)
-	writeKV(out, labelWidth, "CWD", payload.CWD)
+	if payload.FirstResponseSeconds != nil {
+		writeKV(out, labelWidth, "First Response", durations.Format(*payload.FirstResponseSeconds))
+	}
+	writeKV(out, labelWidth, "CWD", links.Path(payload.CWD))
 	writeKV(out, labelWidth, "Originator", payload.Originator)
 	writeKV(out, labelWidth, "CLI Version", payload.CLIVersion)
+	if payload.Model != "" {
+		writeKV(out, labelWidth, "Model", payload.Model)
+	}
+	if payload.Effort != "" {
+		writeKV(out, labelWidth, "Effort", payload.Effort)
+	}
 	writeKV(out, labelWidth, "Message Count", fmt.Sprintf("%d", payload.MessageCount))
-	writeKV(out, labelWidth, "JSONL Path", payload.JSONLPath)
+	status := "completed"
+	if payload.Interrupted {
+		status = "interrupted"
+	}
+	writeKV(out, labelWidth, "Status", status)
+	writeKV(out, labelWidth, "JSONL Path", links.Path(payload.JSONLPath))
 	writeKV(out, labelWidth, "Summary", summarySnippet)
+	if payload.Instructions != "" {
+		fmt.Fprintln(out, "Instructions:") //nolint:errcheck
+		for _, line := range strings.Split(strings.TrimSpace(payload.Instructions), "\n") {
+			fmt.Fprintln(out, strings.TrimRight("  "+line, " ")) //nolint:errcheck
+		}
+	}
 }
 
-func writeKV(out io.Writer, width int, label string, value string) {
-	fmt.Fprintf(out, "%-*s: %s\n", width, label, value) //nolint:errcheck
+// newHyperlinks enables OSC 8 links only when requested and out is a
+// terminal, so redirected output stays plain.
+func newHyperlinks(enabled bool, out io.Writer, sessionURL string) format.Hyperlinks {
+	file, ok := out.(*os.File)
+	if !enabled || !ok || !term.IsTerminal(int(file.Fd())) {
+		return format.Hyperlinks{}
+	}
+	return format.Hyperlinks{Enabled: true, SessionURL: sessionURL}
 }
 
-func collapseWhitespace(text string) string {
-	return strings.Join(strings.Fields(strings.TrimSpace(text)), " ")
+// writeYAML writes v as a YAML document indented by two spaces.
+func writeYAML(out io.Writer, v any) error {
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode yaml: %w", err)
+	}
+	return enc.Close()
 }
 
-func clipSummary(text string, maxLen int) string {
-	if maxLen <= 0 {
-		return ""
-	}
-	runes := []rune(text)
-	if len(runes) <= maxLen {
-		return text
-	}
-	if maxLen == 1 {
-		return "…"
-	}
-	return string(runes[:maxLen-1]) + "…"
+func writeKV(out io.Writer, width int, label string, value string) {
+	fmt.Fprintf(out, "%-*s: %s\n", width, label, value) //nolint:errcheck
 }
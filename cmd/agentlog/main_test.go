@@ -1,29 +1,23 @@
 package main
 
 import (
+	"agentlog/internal/format"
+	"agentlog/internal/model"
+	"agentlog/internal/store"
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
-)
-
-func TestClipSummary(t *testing.T) {
-	if got := clipSummary("abcdef", 3); got != "ab…" {
-		t.Fatalf("clipSummary unexpected result: %q", got)
-	}
-	if got := clipSummary("short", 10); got != "short" {
-		t.Fatalf("clipSummary should not alter short text: %q", got)
-	}
-}
+	"time"
 
-func TestCollapseWhitespace(t *testing.T) {
-	text := "  line one\n\nline\t two  "
-	if got := collapseWhitespace(text); got != "line one line two" {
-		t.Fatalf("collapseWhitespace failed: %q", got)
-	}
-}
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
 
 func TestViewCommandFormatRaw(t *testing.T) {
 	t.Skip("Filtering logic temporarily bypassed during agent-agnostic refactoring")
@@ -56,3 +50,880 @@ func TestViewCommandFormatRaw(t *testing.T) {
 		t.Fatalf("raw output mismatch\nwant:\n%q\n\ngot:\n%q", want, got)
 	}
 }
+
+func TestInfoCommandClaudeMessageCount(t *testing.T) {
+	prev := agentType
+	agentType = "claude"
+	t.Cleanup(func() { agentType = prev })
+
+	cmd := newInfoCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	path := filepath.Join("..", "..", "testdata", "claude-sessions", "sample-with-tools.jsonl")
+	cmd.SetArgs([]string{path, "--format", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("info command failed: %v", err)
+	}
+
+	var payload infoPayload
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("decode info output: %v", err)
+	}
+	if payload.MessageCount != 4 {
+		t.Fatalf("expected 4 messages (summary entry excluded), got %d", payload.MessageCount)
+	}
+}
+
+func TestListCommandCount(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	root := filepath.Join("..", "..", "testdata", "sessions")
+	for _, tt := range []struct {
+		format string
+		want   string
+	}{
+		{format: "table", want: "2\n"},
+		{format: "json", want: "{\"count\":2}\n"},
+	} {
+		cmd := newListCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"--all", "--sessions-dir", root, "--count", "--format", tt.format})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("list command failed: %v", err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Fatalf("list --count --format %s: got %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestViewCommandCount(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	cmd := newViewCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	cmd.SetArgs([]string{path, "--all", "--count"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("view command failed: %v", err)
+	}
+	if got := buf.String(); got != "5\n" {
+		t.Fatalf("view --count: got %q", got)
+	}
+}
+
+func TestViewCommandAllWithEntryType(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	cmd := newViewCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "turn-context.jsonl")
+	cmd.SetArgs([]string{path, "--entry-type", "session_meta", "--all"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("view command failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "test-turn-context-session") {
+		t.Fatalf("expected session_meta event in output:\n%s", buf.String())
+	}
+}
+
+func TestViewCommandChain(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	cmd := newViewCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	root := filepath.Join("..", "..", "testdata", "codex-chain")
+	cmd.SetArgs([]string{"chain-resumed", "--chain", "--sessions-dir", root})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("view command failed: %v", err)
+	}
+
+	out := buf.String()
+	first := strings.Index(out, "Refactor step one done")
+	second := strings.Index(out, "Refactor step two done")
+	if first < 0 || second < 0 || first > second {
+		t.Fatalf("expected both sessions in chain order:\n%s", out)
+	}
+}
+
+func TestInfoCommandCodexModelAndEffort(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	cmd := newInfoCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "turn-context.jsonl")
+	cmd.SetArgs([]string{path, "--format", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("info command failed: %v", err)
+	}
+
+	var payload infoPayload
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("decode info output: %v", err)
+	}
+	if payload.Model != "gpt-5" || payload.Effort != "high" {
+		t.Fatalf("expected model gpt-5 and effort high, got %q/%q", payload.Model, payload.Effort)
+	}
+}
+
+func TestInfoCommandAgentMeta(t *testing.T) {
+	prev := agentType
+	t.Cleanup(func() { agentType = prev })
+
+	run := func(agent, path string) map[string]any {
+		t.Helper()
+		agentType = agent
+		cmd := newInfoCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{path, "--format", "json"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("info command failed: %v", err)
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+			t.Fatalf("decode info output: %v", err)
+		}
+		if payload["agent"] != agent {
+			t.Fatalf("agent: got %v, want %s", payload["agent"], agent)
+		}
+		agentMeta, ok := payload["agent_meta"].(map[string]any)
+		if !ok {
+			t.Fatalf("expected agent_meta object, got %v", payload["agent_meta"])
+		}
+		return agentMeta
+	}
+
+	codexMeta := run("codex", filepath.Join("..", "..", "testdata", "codex-edge-cases", "turn-context.jsonl"))
+	want := map[string]any{"originator": "codex_cli", "cli_version": "1.0.0", "model": "gpt-5", "effort": "high"}
+	if len(codexMeta) != len(want) {
+		t.Fatalf("codex agent_meta: got %v, want %v", codexMeta, want)
+	}
+	for key, value := range want {
+		if codexMeta[key] != value {
+			t.Fatalf("codex agent_meta[%s]: got %v, want %v", key, codexMeta[key], value)
+		}
+	}
+
+	claudeMeta := run("claude", filepath.Join("..", "..", "testdata", "claude-sessions", "sample-meta.jsonl"))
+	if len(claudeMeta) != 1 || claudeMeta["version"] != "1.0.35" {
+		t.Fatalf("claude agent_meta: got %v", claudeMeta)
+	}
+}
+
+func TestInfoCommandYAML(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	cmd := newInfoCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "turn-context.jsonl")
+	cmd.SetArgs([]string{path, "--format", "yaml"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("info command failed: %v", err)
+	}
+
+	var payload map[string]any
+	if err := yaml.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("decode info output: %v\n%s", err, buf.String())
+	}
+	if payload["session_id"] != "test-turn-context-session" || payload["model"] != "gpt-5" {
+		t.Fatalf("unexpected session_id/model: %v/%v", payload["session_id"], payload["model"])
+	}
+	for _, key := range []string{"jsonl_path", "message_count", "duration_seconds"} {
+		if _, ok := payload[key]; !ok {
+			t.Fatalf("expected key %q in YAML output:\n%s", key, buf.String())
+		}
+	}
+}
+
+func TestTimezoneFlag(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "turn-context.jsonl")
+	cmd := newInfoCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{path, "--timezone", "America/New_York"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("info command failed: %v", err)
+	}
+	// The session starts at 09:00 UTC, after the switch back to EST.
+	if !strings.Contains(buf.String(), "2025-11-08T04:00:00-05:00") {
+		t.Fatalf("expected the start time in New York time:\n%s", buf.String())
+	}
+
+	// JSON stays in UTC whatever the display zone, as in list.
+	cmd = newInfoCmd()
+	buf.Reset()
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{path, "--format", "json", "--timezone", "America/New_York"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("info command failed: %v", err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("decode info output: %v\n%s", err, buf.String())
+	}
+	if payload["started_at"] != "2025-11-08T09:00:00Z" {
+		t.Fatalf("expected started_at in UTC, got %v", payload["started_at"])
+	}
+
+	for _, args := range [][]string{
+		{path, "--timezone", "Mars/Olympus_Mons"},
+		{path, "--timezone", "UTC", "--local"},
+	} {
+		cmd := newInfoCmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--timezone") {
+			t.Fatalf("expected a --timezone error for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestInfoCommandTimeFormatTextOnly(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "turn-context.jsonl")
+	info := func(args ...string) string {
+		t.Helper()
+		cmd := newInfoCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{path, "--time-format", "unix"}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("info command failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	if out := info(); !strings.Contains(out, "1762592400") {
+		t.Fatalf("expected a unix start time in text output:\n%s", out)
+	}
+	var payload infoPayload
+	if err := json.Unmarshal([]byte(info("--format", "json")), &payload); err != nil {
+		t.Fatalf("decode info output: %v", err)
+	}
+	if payload.StartedAt != "2025-11-08T09:00:00Z" {
+		t.Fatalf("expected RFC 3339 started_at in JSON, got %q", payload.StartedAt)
+	}
+	if out := info("--format", "yaml"); !strings.Contains(out, "started_at: \"2025-11-08T09:00:00Z\"") {
+		t.Fatalf("expected RFC 3339 started_at in YAML:\n%s", out)
+	}
+}
+
+func TestInfoCommandInstructions(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "instructions.jsonl")
+	run := func(args ...string) infoPayload {
+		t.Helper()
+		cmd := newInfoCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{path, "--format", "json"}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("info command failed: %v", err)
+		}
+		var payload infoPayload
+		if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+			t.Fatalf("decode info output: %v", err)
+		}
+		return payload
+	}
+
+	if payload := run(); payload.Instructions != "" {
+		t.Fatalf("expected no instructions without --show-instructions, got %q", payload.Instructions)
+	}
+	if payload := run("--show-instructions"); !strings.HasSuffix(payload.Instructions, "bytes truncated)") {
+		t.Fatalf("expected clipped instructions, got %q", payload.Instructions)
+	}
+	if payload := run("--show-instructions", "--full"); !strings.HasSuffix(payload.Instructions, "files you changed.") {
+		t.Fatalf("expected the whole instructions with --full, got %q", payload.Instructions)
+	}
+}
+
+func TestInfoCommandFirstResponse(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "first-response.jsonl")
+	run := func(formatName string) string {
+		t.Helper()
+		cmd := newInfoCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{path, "--format", formatName})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("info command failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	var payload infoPayload
+	if err := json.Unmarshal([]byte(run("json")), &payload); err != nil {
+		t.Fatalf("decode info output: %v", err)
+	}
+	if payload.FirstResponseSeconds == nil || *payload.FirstResponseSeconds != 42 {
+		t.Fatalf("expected first response after 42s, got %v", payload.FirstResponseSeconds)
+	}
+	if out := run("text"); !strings.Contains(out, "First Response: 00:00:42\n") {
+		t.Fatalf("expected first response in text output:\n%s", out)
+	}
+}
+
+func TestListCommandNonEmpty(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	root := filepath.Join("..", "..", "testdata", "codex-edge-cases")
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := newListCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"--all", "--sessions-dir", root, "--format", "plain", "--no-header"}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("list command failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	if out := run(); !strings.Contains(out, "test-empty-session") {
+		t.Fatalf("expected empty session in unfiltered output:\n%s", out)
+	}
+	if out := run("--non-empty"); strings.Contains(out, "test-empty-session") {
+		t.Fatalf("--non-empty should exclude the empty session:\n%s", out)
+	}
+}
+
+func TestListCommandShowDuplicates(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	root := filepath.Join("..", "..", "testdata", "duplicate-sessions")
+	cmd := newListCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--all", "--sessions-dir", root, "--format", "plain", "--show-duplicates"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("list command failed: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "timestamp\tsession_id\tcwd\tduration\tmessage_count\tsummary\tpath\n") {
+		t.Fatalf("expected the path column to be added:\n%s", out)
+	}
+	if strings.Contains(out, "unique-session") {
+		t.Fatalf("sessions with a single file should not be listed:\n%s", out)
+	}
+	for _, day := range []string{"07", "08"} {
+		path := filepath.Join(root, "2025", "11", day, "rollout-copied.jsonl")
+		if !strings.Contains(out, "copied-session") || !strings.Contains(out, path) {
+			t.Fatalf("expected copied-session at %s:\n%s", path, out)
+		}
+	}
+}
+
+func TestTagCommandAndListTags(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+	tagsPath := filepath.Join(t.TempDir(), "tags.json")
+	t.Setenv("AGENTLOG_TAGS_FILE", tagsPath)
+
+	root := filepath.Join("..", "..", "testdata", "duplicate-sessions")
+	run := func(cmd *cobra.Command, args ...string) string {
+		t.Helper()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append(args, "--sessions-dir", root))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%s %v failed: %v", cmd.Name(), args, err)
+		}
+		return buf.String()
+	}
+
+	run(newTagCmd(), "unique", "important bugfix", "auth")
+	if got := run(newTagCmd(), "unique-session"); got != "auth\nimportant bugfix\n" {
+		t.Fatalf("unexpected tags: %q", got)
+	}
+	run(newTagCmd(), "unique-session", "--remove", "auth")
+
+	out := run(newListCmd(), "--all", "--format", "plain", "--show-tags", "--tag", "Important Bugfix")
+	want := "timestamp\tsession_id\tcwd\tduration\tmessage_count\tsummary\ttags\n"
+	if !strings.HasPrefix(out, want) {
+		t.Fatalf("expected the tags column to be added:\n%s", out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "unique-session") || !strings.HasSuffix(lines[1], "\timportant bugfix") {
+		t.Fatalf("expected only the tagged session with its tags:\n%s", out)
+	}
+
+	cmd := newListCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--all", "--sessions-dir", root, "--tag", "missing"})
+	if err := cmd.Execute(); !errors.Is(err, errNoMatch) {
+		t.Fatalf("expected no match for an unused tag, got %v", err)
+	}
+}
+
+func TestParseDurationFlag(t *testing.T) {
+	if d, err := parseDurationFlag("--min-duration", ""); err != nil || d != nil {
+		t.Fatalf("empty value should be unset, got %v, %v", d, err)
+	}
+	if d, err := parseDurationFlag("--min-duration", "10m"); err != nil || d == nil || d.Minutes() != 10 {
+		t.Fatalf("unexpected result for 10m: %v, %v", d, err)
+	}
+	for _, bad := range []string{"10", "-5s", "soon"} {
+		if _, err := parseDurationFlag("--max-duration", bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestParseRelativeDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"2d":  48 * time.Hour,
+		"12h": 12 * time.Hour,
+		"1w":  7 * 24 * time.Hour,
+		"90m": 90 * time.Minute,
+	}
+	for input, want := range cases {
+		got, err := parseRelativeDuration(input)
+		if err != nil {
+			t.Fatalf("parseRelativeDuration(%q) returned error: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("parseRelativeDuration(%q) = %v, want %v", input, got, want)
+		}
+	}
+	for _, bad := range []string{"", "d", "7", "-2d", "0h", "1.5d", "yesterday"} {
+		if _, err := parseRelativeDuration(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestCalendarWindow(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Thursday evening in New York is already Friday in UTC.
+	now := time.Date(2025, 11, 6, 21, 30, 0, 0, newYork)
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		start, end string
+	}{
+		{"today", now, "2025-11-06T05:00:00Z", "2025-11-07T04:59:59.999999999Z"},
+		{"today", now.UTC(), "2025-11-07T00:00:00Z", "2025-11-07T23:59:59.999999999Z"},
+		{"yesterday", now, "2025-11-05T05:00:00Z", "2025-11-06T04:59:59.999999999Z"},
+		{"this-week", now, "2025-11-03T05:00:00Z", "2025-11-10T04:59:59.999999999Z"},
+		// The week of the DST switch on 2 November starts at EDT midnight.
+		{"this-week", time.Date(2025, 11, 2, 12, 0, 0, 0, newYork), "2025-10-27T04:00:00Z", "2025-11-03T04:59:59.999999999Z"},
+	}
+	for _, tt := range tests {
+		start, end := calendarWindow(tt.name, tt.now)
+		if got := start.UTC().Format(time.RFC3339Nano); got != tt.start {
+			t.Fatalf("%s at %v: start = %s, want %s", tt.name, tt.now, got, tt.start)
+		}
+		if got := end.UTC().Format(time.RFC3339Nano); got != tt.end {
+			t.Fatalf("%s at %v: end = %s, want %s", tt.name, tt.now, got, tt.end)
+		}
+	}
+}
+
+func TestListCommandCalendarWindowConflicts(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	sessions := filepath.Join("..", "..", "testdata", "sessions")
+	for _, args := range [][]string{
+		{"--today", "--yesterday"},
+		{"--this-week", "--since", "2d"},
+		{"--today", "--after", "2025-01-01T00:00:00Z"},
+	} {
+		cmd := newListCmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"--all", "--sessions-dir", sessions}, args...))
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "cannot be used with") {
+			t.Fatalf("%v: expected a conflict error, got %v", args, err)
+		}
+	}
+}
+
+func TestViewCommandForcePager(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	cmd := newViewCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{path, "--format", "chat", "--no-color", "--force-pager", "--pager", "sed 's/^/paged:/'"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("view command failed: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		if !strings.HasPrefix(line, "paged:") {
+			t.Fatalf("expected every line to pass through the pager, got %q", line)
+		}
+	}
+
+	cmd = newViewCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{path, "--format", "chat", "--force-pager", "--no-pager"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "cannot be used together") {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestViewCommandOutputFile(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	target := filepath.Join(t.TempDir(), "chat.txt")
+	if err := os.WriteFile(target, []byte("stale content that must be truncated\n"), 0o644); err != nil {
+		t.Fatalf("seed output file: %v", err)
+	}
+
+	cmd := newViewCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	cmd.SetArgs([]string{path, "--format", "chat", "-o", target})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("view command failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected nothing on stdout, got %q", buf.String())
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("read output file: %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatal("expected chat output in file")
+	}
+	if bytes.Contains(data, []byte("\x1b[")) {
+		t.Fatalf("output file contains escape sequences:\n%q", data)
+	}
+	if bytes.Contains(data, []byte("stale content")) {
+		t.Fatal("output file was not truncated")
+	}
+}
+
+func TestListCommandOutputFileError(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	cmd := newListCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	root := filepath.Join("..", "..", "testdata", "sessions")
+	target := filepath.Join(t.TempDir(), "missing", "list.txt")
+	cmd.SetArgs([]string{"--all", "--sessions-dir", root, "-o", target})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "open output file") {
+		t.Fatalf("expected open output file error, got %v", err)
+	}
+}
+
+func TestResumeCommand(t *testing.T) {
+	cases := []struct {
+		name     string
+		agent    string
+		template string
+		id       string
+		cwd      string
+		want     string
+	}{
+		{"codex default", "codex", "", "0193a4b2", "/work", "codex resume 0193a4b2"},
+		{"claude default", "claude", "", "0193a4b2", "/work", "claude --resume 0193a4b2"},
+		{"custom template", "claude", "cd {cwd} && claude -r {id}", "0193a4b2", "/my work", "cd '/my work' && claude -r 0193a4b2"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resumeCommand(model.AgentType(tc.agent), tc.template, tc.id, tc.cwd)
+			if err != nil {
+				t.Fatalf("resumeCommand returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("resumeCommand = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := resumeCommand(model.AgentType("other"), "", "id", ""); err == nil {
+		t.Fatal("expected error for agent without a default template")
+	}
+}
+
+func TestResumeCommandPrintsCodexInvocation(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+	t.Setenv("AGENTLOG_RESUME_COMMAND", "")
+
+	cmd := newResumeCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	cmd.SetArgs([]string{path})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("resume command failed: %v", err)
+	}
+	if got := buf.String(); got != "codex resume test-simple-session\n" {
+		t.Fatalf("resume output: got %q", got)
+	}
+}
+
+func TestPathCommand(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	sessions := filepath.Join("..", "..", "testdata", "sessions")
+	want, err := filepath.Abs(filepath.Join(sessions, "sample-simple.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pathOf := func(id string) (string, error) {
+		t.Helper()
+		cmd := newPathCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{id, "--sessions-dir", sessions})
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	for _, id := range []string{"test-simple-session", "test-simple"} {
+		got, err := pathOf(id)
+		if err != nil {
+			t.Fatalf("path %s failed: %v", id, err)
+		}
+		if got != want+"\n" {
+			t.Fatalf("path %s: got %q, want %q", id, got, want)
+		}
+	}
+
+	if _, err := pathOf("missing-session"); !errors.Is(err, store.ErrSessionNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if _, err := pathOf("test-"); !errors.Is(err, store.ErrAmbiguousSession) {
+		t.Fatalf("expected ambiguous error, got %v", err)
+	}
+}
+
+func TestCommandsReadGzipSessions(t *testing.T) {
+	prev := agentType
+	agentType = "codex"
+	t.Cleanup(func() { agentType = prev })
+
+	root := filepath.Join("..", "..", "testdata", "compressed-sessions")
+	run := func(cmd *cobra.Command, args ...string) string {
+		t.Helper()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("command %v failed: %v", args, err)
+		}
+		return buf.String()
+	}
+
+	if out := run(newListCmd(), "--all", "--sessions-dir", root, "--format", "plain", "--no-header"); !strings.Contains(out, "test-simple-session") {
+		t.Fatalf("list should include the gzipped session:\n%s", out)
+	}
+	if out := run(newViewCmd(), "test-simple-session", "--sessions-dir", root); !strings.Contains(out, "Hello, can you help me?") {
+		t.Fatalf("view should render the gzipped session:\n%s", out)
+	}
+	if out := run(newViewCmd(), "test-simple-session", "--sessions-dir", root, "--tail", "2", "--count"); out != "2\n" {
+		t.Fatalf("view --tail on a gzipped session: got %q", out)
+	}
+
+	var payload infoPayload
+	out := run(newInfoCmd(), filepath.Join(root, "sample-simple.jsonl.gz"), "--format", "json")
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("decode info output: %v", err)
+	}
+	if payload.SessionID != "test-simple-session" || payload.MessageCount == 0 {
+		t.Fatalf("unexpected info for gzipped session: %+v", payload)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	prev := agentType
+	t.Cleanup(func() { agentType = prev })
+
+	sessions := filepath.Join("..", "..", "testdata", "sessions")
+	simple := filepath.Join(sessions, "sample-simple.jsonl")
+	broken := filepath.Join("..", "..", "testdata", "broken-sessions")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"list match", []string{"list", "--all", "--sessions-dir", sessions}, exitOK},
+		{"list no match", []string{"list", "--cwd", "/nonexistent", "--sessions-dir", sessions}, exitNoMatch},
+		{"list count no match", []string{"list", "--cwd", "/nonexistent", "--sessions-dir", sessions, "--count"}, exitNoMatch},
+		{"view match", []string{"view", simple}, exitOK},
+		{"info match", []string{"info", "test-simple-session", "--sessions-dir", sessions}, exitOK},
+		{"info unknown session", []string{"info", "missing-session", "--sessions-dir", sessions}, exitNoMatch},
+		{"path unknown session", []string{"path", "missing-session", "--sessions-dir", sessions}, exitNoMatch},
+		{"doctor issues", []string{"doctor", "--sessions-dir", broken}, exitNoMatch},
+		{"unknown flag", []string{"list", "--bogus"}, exitError},
+		{"invalid value", []string{"view", simple, "--tail", "-1"}, exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newRootCmd()
+			root.SetOut(io.Discard)
+			root.SetErr(io.Discard)
+			args := append([]string{"--agent", "codex"}, tt.args...)
+			if got := run(root, args, io.Discard); got != tt.want {
+				t.Fatalf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterruptedView(t *testing.T) {
+	prev := agentType
+	t.Cleanup(func() { agentType = prev })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	root := newRootCmd()
+	root.SetContext(ctx)
+	root.SetOut(io.Discard)
+	var stderr bytes.Buffer
+	root.SetErr(&stderr)
+	simple := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	if got := run(root, []string{"--agent", "codex", "view", simple}, &stderr); got != exitInterrupted {
+		t.Fatalf("exit code = %d, want %d", got, exitInterrupted)
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("expected no error message after an interrupt, got %q", stderr.String())
+	}
+}
+
+func TestQuietSuppressesOutput(t *testing.T) {
+	prev := agentType
+	t.Cleanup(func() { agentType = prev })
+
+	sessions := filepath.Join("..", "..", "testdata", "sessions")
+	for _, args := range [][]string{
+		{"list", "--all", "--sessions-dir", sessions},
+		{"info", "missing-session", "--sessions-dir", sessions},
+	} {
+		root := newRootCmd()
+		var stdout, stderr bytes.Buffer
+		root.SetOut(&stdout)
+		root.SetErr(&stderr)
+		run(root, append([]string{"--agent", "codex", "--quiet"}, args...), &stderr)
+		if stdout.Len() != 0 || stderr.Len() != 0 {
+			t.Fatalf("%v: expected no output with --quiet, got stdout %q, stderr %q", args, stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestWriteList(t *testing.T) {
+	parser, err := model.NewParser(model.AgentCodex)
+	if err != nil {
+		t.Fatalf("create parser: %v", err)
+	}
+	opts := store.ListOptions{Roots: []string{filepath.Join("..", "..", "testdata", "sessions")}}
+
+	var buf bytes.Buffer
+	n, err := writeList(&buf, io.Discard, parser, opts, listOutput{
+		format:  "plain",
+		summary: format.SummaryOptions{Format: "plain"},
+	})
+	if err != nil {
+		t.Fatalf("writeList returned error: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 sessions, got %d", n)
+	}
+	if !strings.Contains(buf.String(), "test-simple-session") {
+		t.Fatalf("expected session in output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := writeList(&buf, io.Discard, parser, opts, listOutput{count: true, format: "table"}); err != nil {
+		t.Fatalf("writeList count returned error: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "2" {
+		t.Fatalf("expected count 2, got %q", buf.String())
+	}
+}
+
+func TestWatchListRequiresTerminal(t *testing.T) {
+	err := watchList(context.Background(), &bytes.Buffer{}, time.Second, func(io.Writer) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "terminal") {
+		t.Fatalf("expected terminal error, got %v", err)
+	}
+}
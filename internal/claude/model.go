@@ -26,8 +26,24 @@ const (
 	ContentBlockTypeText       ContentBlockType = "text"
 	ContentBlockTypeToolUse    ContentBlockType = "tool_use"
 	ContentBlockTypeToolResult ContentBlockType = "tool_result"
+	// ContentBlockTypeThinking and ContentBlockTypeRedactedThinking carry
+	// extended thinking; both are normalized to "reasoning" blocks.
+	ContentBlockTypeThinking         ContentBlockType = "thinking"
+	ContentBlockTypeRedactedThinking ContentBlockType = "redacted_thinking"
 )
 
+// RedactedThinkingPlaceholder is shown for thinking blocks whose content was
+// encrypted by the API.
+const RedactedThinkingPlaceholder = "(redacted thinking)"
+
+// RoleTool is the normalized role for user entries that only carry
+// tool_result blocks.
+const RoleTool = "tool"
+
+// RoleSystem is the normalized role for system entries and for entries
+// flagged isMeta, which Claude Code injects rather than the user typing them.
+const RoleSystem = "system"
+
 // ClaudeSessionSummary represents a Claude Code session summary for listing.
 type ClaudeSessionSummary struct {
 	ID              string    // Session ID (typically the filename without extension)
@@ -68,6 +84,11 @@ type ClaudeSessionMeta struct {
 	CWD       string    // Working directory
 	Version   string    // Claude Code version
 	StartedAt time.Time // First message timestamp
+	// ContinuedFrom holds the summaries a continuation file starts with.
+	// Claude Code opens the file that carries on a compacted conversation
+	// with summary entries whose leafUuid names the last message they
+	// cover, which lives in the earlier file.
+	ContinuedFrom []string
 }
 
 // GetID returns the session ID.
@@ -82,13 +103,18 @@ func (m *ClaudeSessionMeta) GetCWD() string { return m.CWD }
 // GetStartedAt returns the start timestamp.
 func (m *ClaudeSessionMeta) GetStartedAt() time.Time { return m.StartedAt }
 
+// GetContinuedFrom returns the summaries of the compacted conversation the
+// session continues, if any.
+func (m *ClaudeSessionMeta) GetContinuedFrom() []string { return m.ContinuedFrom }
+
 // ClaudeEvent represents a single entry in the Claude Code session JSONL stream.
 type ClaudeEvent struct {
 	Timestamp time.Time
 	Kind      EntryType
-	Role      string // "user", "assistant"
+	Role      string // "user", "assistant", RoleTool for tool results, or RoleSystem
 	Content   []model.ContentBlock
 	Raw       string
+	IsMeta    bool // injected by Claude Code (isMeta), not part of the conversation
 
 	// Metadata fields
 	UUID       string
@@ -103,6 +129,10 @@ type ClaudeEvent struct {
 	Model     string
 	Usage     *TokenUsage
 
+	// ToolCallIDs holds the IDs of the tool_use blocks, or the tool_use_ids
+	// answered by the tool_result blocks, in the message.
+	ToolCallIDs []string
+
 	// Summary-specific fields
 	SummaryText string
 	LeafUUID    string
@@ -126,6 +156,57 @@ func (e *ClaudeEvent) GetContent() []model.ContentBlock { return e.Content }
 // GetRaw returns the raw JSON string.
 func (e *ClaudeEvent) GetRaw() string { return e.Raw }
 
+// GetEntryType maps the Claude entry type onto the normalized entry types:
+// messages and system entries are response items, summaries are session
+// metadata, and file history snapshots are events.
+func (e *ClaudeEvent) GetEntryType() string {
+	switch e.Kind {
+	case EntryTypeUser, EntryTypeAssistant, EntryTypeSystem:
+		return "response_item"
+	case EntryTypeSummary:
+		return "session_meta"
+	default:
+		return "event_msg"
+	}
+}
+
+// GetPayloadType derives the normalized payload type from the content
+// blocks. Entries with any text are messages, so an assistant reply that
+// also calls a tool stays visible under the default filters; entries with
+// only thinking are reasoning, like Codex reasoning items.
+func (e *ClaudeEvent) GetPayloadType() string {
+	switch e.GetEntryType() {
+	case "session_meta":
+		return ""
+	case "event_msg":
+		return string(e.Kind)
+	}
+
+	var hasToolUse, hasToolResult, hasReasoning bool
+	for _, block := range e.Content {
+		switch ContentBlockType(block.Type) {
+		case ContentBlockTypeToolUse:
+			hasToolUse = true
+		case ContentBlockTypeToolResult:
+			hasToolResult = true
+		case "reasoning":
+			hasReasoning = true
+		default:
+			return "message"
+		}
+	}
+	switch {
+	case hasToolUse:
+		return "function_call"
+	case hasToolResult:
+		return "function_call_output"
+	case hasReasoning:
+		return "reasoning"
+	default:
+		return "message"
+	}
+}
+
 // GetRole returns the role string for the event.
 func (e *ClaudeEvent) GetRole() string {
 	if e.Role != "" {
@@ -133,3 +214,38 @@ func (e *ClaudeEvent) GetRole() string {
 	}
 	return string(e.Kind)
 }
+
+// GetToolCallIDs returns the tool_use IDs the event calls or answers.
+func (e *ClaudeEvent) GetToolCallIDs() []string { return e.ToolCallIDs }
+
+// GetModel returns the model that produced an assistant message.
+func (e *ClaudeEvent) GetModel() string { return e.Model }
+
+// GetTokenUsage returns the prompt tokens, including cache reads and
+// writes, and the output tokens of an assistant message.
+func (e *ClaudeEvent) GetTokenUsage() (input, output int) {
+	if e.Usage == nil {
+		return 0, 0
+	}
+	input = e.Usage.InputTokens + e.Usage.CacheCreationInputTokens + e.Usage.CacheReadInputTokens
+	return input, e.Usage.OutputTokens
+}
+
+// GetMetadata returns the assistant message identifiers, model, and service
+// tier when present.
+func (e *ClaudeEvent) GetMetadata() map[string]string {
+	meta := make(map[string]string)
+	if e.MessageID != "" {
+		meta["message_id"] = e.MessageID
+	}
+	if e.RequestID != "" {
+		meta["request_id"] = e.RequestID
+	}
+	if e.Model != "" {
+		meta["model"] = e.Model
+	}
+	if e.Usage != nil && e.Usage.ServiceTier != "" {
+		meta["service_tier"] = e.Usage.ServiceTier
+	}
+	return meta
+}
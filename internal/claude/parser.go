@@ -2,11 +2,9 @@ package claude
 
 import (
 	"agentlog/internal/model"
-	"bufio"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 )
@@ -15,7 +13,10 @@ import (
 type ClaudeParser struct{}
 
 // Ensure ClaudeParser implements model.Parser
-var _ model.Parser = (*ClaudeParser)(nil)
+var (
+	_ model.Parser          = (*ClaudeParser)(nil)
+	_ model.EventLineParser = (*ClaudeParser)(nil)
+)
 
 func init() {
 	model.RegisterClaudeParser(func() model.Parser {
@@ -39,6 +40,28 @@ func (p *ClaudeParser) FirstUserSummary(path string) (string, error) {
 	return summary, err
 }
 
+// SummaryEntry returns the text of the session's first summary entry.
+// This is the implementation of model.SummaryEntryReader.SummaryEntry.
+func (p *ClaudeParser) SummaryEntry(path string) (string, error) {
+	return SummaryEntry(path)
+}
+
+// CountMessages returns the number of user and assistant messages in the session.
+// This is the implementation of model.Parser.CountMessages.
+func (p *ClaudeParser) CountMessages(path string) (int, error) {
+	return CountMessages(path)
+}
+
+// ParseEventLine decodes a single JSONL record.
+// This is the implementation of model.EventLineParser.
+func (p *ClaudeParser) ParseEventLine(line []byte) (model.EventProvider, error) {
+	event, err := parseEvent(line)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
 // IterateEvents iterates through all events in the session.
 // This is the implementation of model.Parser.IterateEvents.
 func (p *ClaudeParser) IterateEvents(path string, fn func(model.EventProvider) error) error {
@@ -49,13 +72,14 @@ func (p *ClaudeParser) IterateEvents(path string, fn func(model.EventProvider) e
 
 // ReadSessionMeta loads metadata from the first entry in a Claude Code session file.
 func ReadSessionMeta(path string) (*ClaudeSessionMeta, error) {
-	file, err := os.Open(path)
+	file, err := model.OpenSessionFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("open session file: %w", err)
 	}
 	defer file.Close() //nolint:errcheck
 
-	scanner := newScanner(file)
+	var continuedFrom []string
+	scanner := model.NewLineScanner(file)
 	for scanner.Scan() {
 		recBytes := scanner.Bytes()
 		event, err := parseEvent(recBytes)
@@ -63,6 +87,13 @@ func ReadSessionMeta(path string) (*ClaudeSessionMeta, error) {
 			continue // Skip invalid entries
 		}
 
+		// Summaries ahead of the first message point back into the
+		// compacted conversation this file continues.
+		if event.Kind == EntryTypeSummary && event.LeafUUID != "" && event.SummaryText != "" {
+			continuedFrom = append(continuedFrom, event.SummaryText)
+			continue
+		}
+
 		// Extract metadata from first valid entry
 		if event.Timestamp.IsZero() {
 			continue
@@ -75,6 +106,7 @@ func ReadSessionMeta(path string) (*ClaudeSessionMeta, error) {
 			Version:   event.Version,
 			StartedAt: event.Timestamp,
 		}
+		meta.ContinuedFrom = continuedFrom
 		return meta, nil
 	}
 
@@ -86,14 +118,18 @@ func ReadSessionMeta(path string) (*ClaudeSessionMeta, error) {
 }
 
 // FirstUserSummary returns the first user message text and total message count.
+// User entries that only carry tool results are skipped, so sessions resumed
+// mid-tool-call are summarized by their first real prompt. The text of a
+// summary entry is used only when the session has no such prompt.
 func FirstUserSummary(path string) (summary string, messageCount int, lastTimestamp time.Time, err error) {
-	file, err := os.Open(path)
+	file, err := model.OpenSessionFile(path)
 	if err != nil {
 		return "", 0, time.Time{}, fmt.Errorf("open session file: %w", err)
 	}
 	defer file.Close() //nolint:errcheck
 
-	scanner := newScanner(file)
+	var fallback string
+	scanner := model.NewLineScanner(file)
 	for scanner.Scan() {
 		recBytes := scanner.Bytes()
 		event, err := parseEvent(recBytes)
@@ -105,18 +141,20 @@ func FirstUserSummary(path string) (summary string, messageCount int, lastTimest
 			lastTimestamp = event.Timestamp
 		}
 
-		if event.Kind == EntryTypeUser || event.Kind == EntryTypeAssistant {
+		if isMessage(event) {
 			messageCount++
-			if summary == "" && event.Kind == EntryTypeUser {
+			if summary == "" && event.Kind == EntryTypeUser && event.Role != RoleTool {
 				summary = buildSummaryText(event.Content)
 			}
 		}
 
-		// Also check for summary entries
-		if summary == "" && event.Kind == EntryTypeSummary && event.SummaryText != "" {
-			summary = event.SummaryText
+		if fallback == "" && event.Kind == EntryTypeSummary && event.SummaryText != "" {
+			fallback = event.SummaryText
 		}
 	}
+	if summary == "" {
+		summary = fallback
+	}
 
 	if err := scanner.Err(); err != nil {
 		return summary, messageCount, lastTimestamp, fmt.Errorf("scan session: %w", err)
@@ -125,15 +163,59 @@ func FirstUserSummary(path string) (summary string, messageCount int, lastTimest
 	return summary, messageCount, lastTimestamp, nil
 }
 
+// SummaryEntry returns the text of the first summary entry, which Claude
+// Code writes at the top of resumed and compacted sessions, or "" when the
+// session has none.
+func SummaryEntry(path string) (string, error) {
+	file, err := model.OpenSessionFile(path)
+	if err != nil {
+		return "", fmt.Errorf("open session file: %w", err)
+	}
+	defer file.Close() //nolint:errcheck
+
+	scanner := model.NewLineScanner(file)
+	for scanner.Scan() {
+		event, err := parseEvent(scanner.Bytes())
+		if err != nil {
+			continue
+		}
+		if event.Kind == EntryTypeSummary && event.SummaryText != "" {
+			return event.SummaryText, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("scan session: %w", err)
+	}
+	return "", nil
+}
+
+// CountMessages returns the number of user and assistant entries in the session.
+func CountMessages(path string) (int, error) {
+	var count int
+	err := IterateEvents(path, func(event ClaudeEvent) error {
+		if isMessage(event) {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// isMessage reports whether event counts as a conversational message.
+// Meta entries are excluded even though they are logged as user entries.
+func isMessage(event ClaudeEvent) bool {
+	return (event.Kind == EntryTypeUser || event.Kind == EntryTypeAssistant) && !event.IsMeta
+}
+
 // IterateEvents walks through the session JSONL file and calls fn for each decoded event.
 func IterateEvents(path string, fn func(ClaudeEvent) error) error {
-	file, err := os.Open(path)
+	file, err := model.OpenSessionFile(path)
 	if err != nil {
 		return fmt.Errorf("open session file: %w", err)
 	}
 	defer file.Close() //nolint:errcheck
 
-	scanner := newScanner(file)
+	scanner := model.NewLineScanner(file)
 	for scanner.Scan() {
 		recBytes := scanner.Bytes()
 		event, err := parseEvent(recBytes)
@@ -176,15 +258,6 @@ func buildSummaryText(blocks []model.ContentBlock) string {
 	return builder.String()
 }
 
-func newScanner(file *os.File) *bufio.Scanner {
-	scanner := bufio.NewScanner(file)
-	// Allow large payloads
-	const maxCapacity = 8 * 1024 * 1024
-	buf := make([]byte, 1024)
-	scanner.Buffer(buf, maxCapacity)
-	return scanner
-}
-
 type rawEntry struct {
 	Type       string          `json:"type"`
 	UUID       string          `json:"uuid"`
@@ -193,9 +266,12 @@ type rawEntry struct {
 	CWD        string          `json:"cwd"`
 	Version    string          `json:"version"`
 	Timestamp  string          `json:"timestamp"`
+	RequestID  string          `json:"requestId"`
 	Message    json.RawMessage `json:"message"`
 	Summary    string          `json:"summary"`
 	LeafUUID   string          `json:"leafUuid"`
+	IsMeta     bool            `json:"isMeta"`
+	Content    json.RawMessage `json:"content"` // system entries carry their text here
 }
 
 type messagePayload struct {
@@ -216,12 +292,33 @@ type messagePayload struct {
 type contentBlock struct {
 	Type      string          `json:"type"`
 	Text      string          `json:"text"`
+	Thinking  string          `json:"thinking"`
 	ID        string          `json:"id"`
 	Name      string          `json:"name"`
 	Input     json.RawMessage `json:"input"`
 	ToolUseID string          `json:"tool_use_id"`
 	Content   json.RawMessage `json:"content"`
 	IsError   bool            `json:"is_error"`
+	Source    *blockSource    `json:"source"`
+}
+
+// blockSource holds the payload of image and document blocks.
+type blockSource struct {
+	Type      string `json:"type"` // "base64" or "url"
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+	URL       string `json:"url"`
+}
+
+// describe returns the placeholder text for the block's source.
+func (s *blockSource) describe() string {
+	if s == nil {
+		return model.DescribeAttachment("", "")
+	}
+	if s.Type == "url" && s.URL != "" {
+		return s.URL
+	}
+	return model.DescribeAttachment(s.MediaType, s.Data)
 }
 
 func parseEvent(raw []byte) (ClaudeEvent, error) {
@@ -248,6 +345,7 @@ func parseEvent(raw []byte) (ClaudeEvent, error) {
 		CWD:        entry.CWD,
 		Version:    entry.Version,
 		Raw:        string(raw),
+		IsMeta:     entry.IsMeta,
 	}
 
 	switch EntryType(entry.Type) {
@@ -260,6 +358,7 @@ func parseEvent(raw []byte) (ClaudeEvent, error) {
 
 			event.Role = msg.Role
 			event.MessageID = msg.ID
+			event.RequestID = entry.RequestID
 			event.Model = msg.Model
 
 			if msg.Usage != nil {
@@ -273,7 +372,20 @@ func parseEvent(raw []byte) (ClaudeEvent, error) {
 			}
 
 			event.Content = decodeContent(msg.Content)
+			event.ToolCallIDs = toolCallIDs(msg.Content, event.Content)
+
+			// Tool results arrive as "user" entries but carry tool output.
+			if event.Kind == EntryTypeUser && isToolResultOnly(event.Content) {
+				event.Role = RoleTool
+			}
 		}
+		if event.IsMeta {
+			event.Role = RoleSystem
+		}
+
+	case EntryTypeSystem:
+		event.Role = RoleSystem
+		event.Content = decodeContent(entry.Content)
 
 	case EntryTypeSummary:
 		event.SummaryText = entry.Summary
@@ -283,6 +395,50 @@ func parseEvent(raw []byte) (ClaudeEvent, error) {
 	return event, nil
 }
 
+// isToolResultOnly reports whether blocks is non-empty and holds only
+// tool_result blocks.
+func isToolResultOnly(blocks []model.ContentBlock) bool {
+	if len(blocks) == 0 {
+		return false
+	}
+	for _, block := range blocks {
+		if block.Type != string(ContentBlockTypeToolResult) {
+			return false
+		}
+	}
+	return true
+}
+
+// toolCallIDs returns the tool_use IDs named by the tool blocks of raw.
+// blocks is the decoded content, checked first so messages without tools
+// are not decoded twice.
+func toolCallIDs(raw json.RawMessage, blocks []model.ContentBlock) []string {
+	hasTools := false
+	for _, block := range blocks {
+		switch ContentBlockType(block.Type) {
+		case ContentBlockTypeToolUse, ContentBlockTypeToolResult:
+			hasTools = true
+		}
+	}
+	if !hasTools {
+		return nil
+	}
+	var decoded []contentBlock
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil
+	}
+	var ids []string
+	for _, block := range decoded {
+		switch ContentBlockType(block.Type) {
+		case ContentBlockTypeToolUse:
+			ids = append(ids, block.ID)
+		case ContentBlockTypeToolResult:
+			ids = append(ids, block.ToolUseID)
+		}
+	}
+	return ids
+}
+
 func decodeContent(raw json.RawMessage) []model.ContentBlock {
 	if len(raw) == 0 {
 		return nil
@@ -311,10 +467,15 @@ func decodeContent(raw json.RawMessage) []model.ContentBlock {
 				if len(block.Input) > 0 {
 					text += fmt.Sprintf("\nInput: %s", string(block.Input))
 				}
-				result = append(result, model.ContentBlock{
-					Type: "tool_use",
-					Text: text,
-				})
+				toolBlock := model.ContentBlock{
+					Type:     "tool_use",
+					Text:     text,
+					ToolName: block.Name,
+				}
+				if json.Valid(block.Input) {
+					toolBlock.ToolInput = block.Input
+				}
+				result = append(result, toolBlock)
 			case "tool_result":
 				// Decode nested content in tool_result
 				var resultText string
@@ -324,6 +485,10 @@ func decodeContent(raw json.RawMessage) []model.ContentBlock {
 					if err := json.Unmarshal(block.Content, &nestedBlocks); err == nil {
 						var parts []string
 						for _, nb := range nestedBlocks {
+							if nb.Type == "image" {
+								parts = append(parts, fmt.Sprintf("[image: %s]", nb.Source.describe()))
+								continue
+							}
 							if nb.Text != "" {
 								parts = append(parts, nb.Text)
 							}
@@ -342,6 +507,26 @@ func decodeContent(raw json.RawMessage) []model.ContentBlock {
 					Type: "tool_result",
 					Text: text,
 				})
+			case "thinking":
+				result = append(result, model.ContentBlock{
+					Type: "reasoning",
+					Text: block.Thinking,
+				})
+			case "redacted_thinking":
+				result = append(result, model.ContentBlock{
+					Type: "reasoning",
+					Text: RedactedThinkingPlaceholder,
+				})
+			case "image":
+				result = append(result, model.ContentBlock{
+					Type: "image",
+					Text: block.Source.describe(),
+				})
+			case "document":
+				result = append(result, model.ContentBlock{
+					Type: "attachment",
+					Text: block.Source.describe(),
+				})
 			default:
 				// Unknown type, store as JSON
 				result = append(result, model.ContentBlock{
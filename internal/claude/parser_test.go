@@ -1,7 +1,9 @@
 package claude
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -33,6 +35,29 @@ func TestReadSessionMeta(t *testing.T) {
 	}
 }
 
+func TestReadSessionMetaContinuedFrom(t *testing.T) {
+	meta, err := ReadSessionMeta(fixturePath("sample-compacted.jsonl"))
+	if err != nil {
+		t.Fatalf("ReadSessionMeta returned error: %v", err)
+	}
+	if meta.ID != "test-claude-compacted" {
+		t.Fatalf("unexpected session id: %s", meta.ID)
+	}
+	if len(meta.ContinuedFrom) != 1 || meta.ContinuedFrom[0] != "Set up the CI workflow for the Go module" {
+		t.Fatalf("unexpected continuation summaries: %q", meta.ContinuedFrom)
+	}
+
+	// A summary written after the messages describes this file, not an
+	// earlier one.
+	meta, err = ReadSessionMeta(fixturePath("sample-with-tools.jsonl"))
+	if err != nil {
+		t.Fatalf("ReadSessionMeta returned error: %v", err)
+	}
+	if meta.ContinuedFrom != nil {
+		t.Fatalf("expected no continuation, got %q", meta.ContinuedFrom)
+	}
+}
+
 func TestFirstUserSummary(t *testing.T) {
 	path := fixturePath("sample-simple.jsonl")
 
@@ -72,6 +97,43 @@ func TestFirstUserSummary_WithSummaryEntry(t *testing.T) {
 	}
 }
 
+func TestFirstUserSummary_SkipsLeadingToolResult(t *testing.T) {
+	path := fixturePath("sample-resumed-tool-result.jsonl")
+
+	summary, count, _, err := FirstUserSummary(path)
+	if err != nil {
+		t.Fatalf("FirstUserSummary returned error: %v", err)
+	}
+	// Neither the leading tool_result entry nor the summary entry should win.
+	if summary != "Add a README to the project" {
+		t.Fatalf("unexpected summary: %q", summary)
+	}
+	if count != 4 {
+		t.Fatalf("unexpected message count: %d", count)
+	}
+}
+
+func TestFirstUserSummary_FallsBackToSummaryEntry(t *testing.T) {
+	source, err := os.ReadFile(fixturePath("sample-resumed-tool-result.jsonl"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	// Keep only the tool_result entry, the summary entry, and the first reply.
+	lines := strings.SplitAfter(string(source), "\n")
+	path := filepath.Join(t.TempDir(), "tool-only.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines[:3], "")), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	summary, _, _, err := FirstUserSummary(path)
+	if err != nil {
+		t.Fatalf("FirstUserSummary returned error: %v", err)
+	}
+	if summary != "Listing the project tree" {
+		t.Fatalf("expected the summary entry as fallback, got %q", summary)
+	}
+}
+
 func TestIterateEvents_Simple(t *testing.T) {
 	path := fixturePath("sample-simple.jsonl")
 
@@ -159,6 +221,18 @@ func TestIterateEvents_WithTools(t *testing.T) {
 	if toolResultEvent.Content[0].Type != "tool_result" {
 		t.Fatalf("expected tool_result content, got %s", toolResultEvent.Content[0].Type)
 	}
+	if ids := toolUseEvent.GetToolCallIDs(); len(ids) != 1 || ids[0] != "toolu_01abc" {
+		t.Fatalf("unexpected tool_use IDs: %v", ids)
+	}
+	if ids := toolResultEvent.GetToolCallIDs(); len(ids) != 1 || ids[0] != "toolu_01abc" {
+		t.Fatalf("unexpected tool_result IDs: %v", ids)
+	}
+	if toolResultEvent.GetRole() != "tool" {
+		t.Fatalf("expected tool role for tool result, got %s", toolResultEvent.GetRole())
+	}
+	if events[0].GetRole() != "user" {
+		t.Fatalf("expected genuine user message to keep user role, got %s", events[0].GetRole())
+	}
 
 	// Check summary entry
 	summaryEvent := events[4]
@@ -172,3 +246,131 @@ func TestIterateEvents_WithTools(t *testing.T) {
 		t.Fatalf("unexpected leaf uuid: %s", summaryEvent.LeafUUID)
 	}
 }
+
+func TestCountMessages_ExcludesSummary(t *testing.T) {
+	path := fixturePath("sample-with-tools.jsonl")
+
+	count, err := CountMessages(path)
+	if err != nil {
+		t.Fatalf("CountMessages returned error: %v", err)
+	}
+	if count != 4 {
+		t.Fatalf("expected 4 messages, got %d", count)
+	}
+}
+
+func TestIterateEvents_RequestIDAndServiceTier(t *testing.T) {
+	path := fixturePath("sample-priority-tier.jsonl")
+
+	var events []ClaudeEvent
+	if err := IterateEvents(path, func(evt ClaudeEvent) error {
+		events = append(events, evt)
+		return nil
+	}); err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	assistant := events[1]
+	if assistant.RequestID != "req_011CPriorityTier" {
+		t.Fatalf("unexpected request id: %q", assistant.RequestID)
+	}
+	meta := assistant.GetMetadata()
+	if meta["service_tier"] != "priority" || meta["request_id"] != "req_011CPriorityTier" {
+		t.Fatalf("unexpected metadata: %#v", meta)
+	}
+	if len(events[0].GetMetadata()) != 0 {
+		t.Fatalf("expected no metadata for user message, got %#v", events[0].GetMetadata())
+	}
+}
+
+func TestIterateEvents_ImageAndDocument(t *testing.T) {
+	path := fixturePath("sample-image.jsonl")
+
+	var first ClaudeEvent
+	err := IterateEvents(path, func(evt ClaudeEvent) error {
+		if first.UUID == "" {
+			first = evt
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	if len(first.Content) != 3 {
+		t.Fatalf("expected text, image, and document blocks, got %+v", first.Content)
+	}
+	if got := first.Content[1]; got.Type != "image" || got.Text != "png, 3KB" {
+		t.Fatalf("unexpected image block: %+v", got)
+	}
+	if got := first.Content[2]; got.Type != "attachment" || got.Text != "pdf, 9B" {
+		t.Fatalf("unexpected document block: %+v", got)
+	}
+}
+
+func TestIterateEvents_Thinking(t *testing.T) {
+	path := fixturePath("sample-thinking.jsonl")
+
+	var events []ClaudeEvent
+	err := IterateEvents(path, func(evt ClaudeEvent) error {
+		events = append(events, evt)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+
+	thinking, redacted, reply := events[1], events[2], events[3]
+	if len(thinking.Content) != 1 || thinking.Content[0].Type != "reasoning" || thinking.Content[0].Text != "221 = 13 * 17, so it is composite." {
+		t.Fatalf("unexpected thinking blocks: %+v", thinking.Content)
+	}
+	if len(redacted.Content) != 1 || redacted.Content[0].Type != "reasoning" || redacted.Content[0].Text != RedactedThinkingPlaceholder {
+		t.Fatalf("unexpected redacted thinking blocks: %+v", redacted.Content)
+	}
+	for _, evt := range []ClaudeEvent{thinking, redacted} {
+		if got := evt.GetPayloadType(); got != "reasoning" {
+			t.Fatalf("expected thinking to have payload type reasoning, got %q", got)
+		}
+	}
+	if got := reply.GetPayloadType(); got != "message" {
+		t.Fatalf("expected reply to have payload type message, got %q", got)
+	}
+}
+
+func TestIterateEvents_MetaEntriesAreSystem(t *testing.T) {
+	path := fixturePath("sample-meta.jsonl")
+
+	var roles []string
+	err := IterateEvents(path, func(event ClaudeEvent) error {
+		roles = append(roles, event.GetRole())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	want := []string{RoleSystem, RoleSystem, "user", "assistant"}
+	if strings.Join(roles, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected roles: got %v, want %v", roles, want)
+	}
+
+	count, err := CountMessages(path)
+	if err != nil {
+		t.Fatalf("CountMessages returned error: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected meta entries to be excluded from the message count, got %d", count)
+	}
+
+	summary, _, _, err := FirstUserSummary(path)
+	if err != nil {
+		t.Fatalf("FirstUserSummary returned error: %v", err)
+	}
+	if summary != "Explain the build script" {
+		t.Fatalf("expected the first real prompt as summary, got %q", summary)
+	}
+}
@@ -39,6 +39,13 @@ const (
 	EventMsgTypeUserMessage    EventMsgType = "user_message"
 	EventMsgTypeAgentMessage   EventMsgType = "agent_message"
 	EventMsgTypeTurnAborted    EventMsgType = "turn_aborted"
+	EventMsgTypeError          EventMsgType = "error"
+	EventMsgTypeTaskStarted    EventMsgType = "task_started"
+	EventMsgTypeTaskComplete   EventMsgType = "task_complete"
+	EventMsgTypeExecBegin      EventMsgType = "exec_command_begin"
+	EventMsgTypeExecEnd        EventMsgType = "exec_command_end"
+	EventMsgTypeMCPToolBegin   EventMsgType = "mcp_tool_call_begin"
+	EventMsgTypeMCPToolEnd     EventMsgType = "mcp_tool_call_end"
 )
 
 // PayloadRole captures the "payload.role" values observed in Codex response items.
@@ -62,6 +69,8 @@ type CodexSessionSummary struct {
 	Summary         string
 	MessageCount    int
 	DurationSeconds int
+	Model           string // model from the latest turn_context
+	Effort          string // reasoning effort from the latest turn_context
 }
 
 // GetID returns the session ID.
@@ -85,6 +94,12 @@ func (s *CodexSessionSummary) GetMessageCount() int { return s.MessageCount }
 // GetDurationSeconds returns the session duration in seconds.
 func (s *CodexSessionSummary) GetDurationSeconds() int { return s.DurationSeconds }
 
+// GetModel returns the model from the latest turn_context.
+func (s *CodexSessionSummary) GetModel() string { return s.Model }
+
+// GetEffort returns the reasoning effort from the latest turn_context.
+func (s *CodexSessionSummary) GetEffort() string { return s.Effort }
+
 // CodexSessionMeta represents metadata stored in the session_meta payload.
 type CodexSessionMeta struct {
 	ID         string
@@ -93,6 +108,10 @@ type CodexSessionMeta struct {
 	Originator string
 	CLIVersion string
 	StartedAt  time.Time
+	ParentID   string // session this one resumes, if any
+	// Instructions is the system prompt recorded in session_meta, which can
+	// run to many kilobytes.
+	Instructions string
 }
 
 // GetID returns the session ID.
@@ -107,6 +126,12 @@ func (m *CodexSessionMeta) GetCWD() string { return m.CWD }
 // GetStartedAt returns the start timestamp.
 func (m *CodexSessionMeta) GetStartedAt() time.Time { return m.StartedAt }
 
+// GetInstructions returns the system prompt recorded in session_meta.
+func (m *CodexSessionMeta) GetInstructions() string { return m.Instructions }
+
+// GetParentSessionID returns the ID of the session this one resumes.
+func (m *CodexSessionMeta) GetParentSessionID() string { return m.ParentID }
+
 // CodexEvent represents a single entry in the Codex session JSONL stream.
 type CodexEvent struct {
 	Timestamp   time.Time
@@ -115,6 +140,17 @@ type CodexEvent struct {
 	PayloadType string // response_item: ResponseItemType, event_msg: EventMsgType
 	Content     []model.ContentBlock
 	Raw         string
+
+	// turn_context fields
+	Model  string
+	Effort string
+
+	// token_count fields: usage of the last turn
+	InputTokens  int
+	OutputTokens int
+
+	// CallID ties an exec_command_end to its exec_command_begin.
+	CallID string
 }
 
 // GetTimestamp returns the event timestamp.
@@ -126,6 +162,13 @@ func (e *CodexEvent) GetContent() []model.ContentBlock { return e.Content }
 // GetRaw returns the raw JSON string.
 func (e *CodexEvent) GetRaw() string { return e.Raw }
 
+// GetEntryType returns the top-level entry type.
+func (e *CodexEvent) GetEntryType() string { return string(e.Kind) }
+
+// GetPayloadType returns the payload type of response_item and event_msg
+// entries.
+func (e *CodexEvent) GetPayloadType() string { return e.PayloadType }
+
 // GetRole returns a normalized role string for the event.
 // For Codex events, we use the PayloadRole if available, otherwise Kind.
 func (e *CodexEvent) GetRole() string {
@@ -134,3 +177,42 @@ func (e *CodexEvent) GetRole() string {
 	}
 	return string(e.Kind)
 }
+
+// GetModel returns the model configured by a turn_context event.
+func (e *CodexEvent) GetModel() string { return e.Model }
+
+// GetEffort returns the reasoning effort configured by a turn_context event.
+func (e *CodexEvent) GetEffort() string { return e.Effort }
+
+// GetTokenUsage returns the last-turn usage recorded by a token_count event.
+func (e *CodexEvent) GetTokenUsage() (input, output int) { return e.InputTokens, e.OutputTokens }
+
+// GetToolCallIDs returns the call id of exec_command_begin and
+// exec_command_end events, so --flat-tools can pair them.
+func (e *CodexEvent) GetToolCallIDs() []string {
+	if e.CallID == "" {
+		return nil
+	}
+	return []string{e.CallID}
+}
+
+// IsTurnAborted reports whether the event is a turn_aborted event_msg.
+func (e *CodexEvent) IsTurnAborted() bool {
+	return e.Kind == EntryTypeEventMsg && e.PayloadType == string(EventMsgTypeTurnAborted)
+}
+
+// GetMetadata returns the entry kind and payload type of the event, plus the
+// model and effort for turn_context events.
+func (e *CodexEvent) GetMetadata() map[string]string {
+	meta := map[string]string{"entry_type": string(e.Kind)}
+	if e.PayloadType != "" {
+		meta["payload_type"] = e.PayloadType
+	}
+	if e.Model != "" {
+		meta["model"] = e.Model
+	}
+	if e.Effort != "" {
+		meta["effort"] = e.Effort
+	}
+	return meta
+}
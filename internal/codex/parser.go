@@ -2,11 +2,9 @@ package codex
 
 import (
 	"agentlog/internal/model"
-	"bufio"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 )
@@ -15,7 +13,10 @@ import (
 type CodexParser struct{}
 
 // Ensure CodexParser implements model.Parser
-var _ model.Parser = (*CodexParser)(nil)
+var (
+	_ model.Parser          = (*CodexParser)(nil)
+	_ model.EventLineParser = (*CodexParser)(nil)
+)
 
 func init() {
 	model.RegisterCodexParser(func() model.Parser {
@@ -26,6 +27,10 @@ func init() {
 // ErrSessionMetaNotFound is returned when a JSONL file lacks session_meta.
 var ErrSessionMetaNotFound = errors.New("session_meta record not found")
 
+// EncryptedReasoningPlaceholder is shown for reasoning items whose content is
+// fully encrypted and carry no summary.
+const EncryptedReasoningPlaceholder = "(encrypted reasoning)"
+
 // ReadSessionMeta loads metadata from the first session_meta record in path.
 // This is the implementation of model.Parser.ReadSessionMeta.
 func (p *CodexParser) ReadSessionMeta(path string) (model.SessionMetaProvider, error) {
@@ -39,6 +44,22 @@ func (p *CodexParser) FirstUserSummary(path string) (string, error) {
 	return summary, err
 }
 
+// CountMessages returns the number of user and assistant messages in the session.
+// This is the implementation of model.Parser.CountMessages.
+func (p *CodexParser) CountMessages(path string) (int, error) {
+	return CountMessages(path)
+}
+
+// ParseEventLine decodes a single JSONL record.
+// This is the implementation of model.EventLineParser.
+func (p *CodexParser) ParseEventLine(line []byte) (model.EventProvider, error) {
+	event, err := parseEvent(line)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
 // IterateEvents iterates through all events in the session.
 // This is the implementation of model.Parser.IterateEvents.
 func (p *CodexParser) IterateEvents(path string, fn func(model.EventProvider) error) error {
@@ -47,18 +68,19 @@ func (p *CodexParser) IterateEvents(path string, fn func(model.EventProvider) er
 	})
 }
 
-// ReadSessionMeta loads metadata from the first session_meta record in path.
+// ReadSessionMeta loads metadata from the first session_meta record in path,
+// reading no further. Codex records the model and effort only in
+// turn_context entries; callers that need them take them from the events.
 func ReadSessionMeta(path string) (*CodexSessionMeta, error) {
-	file, err := os.Open(path)
+	file, err := model.OpenSessionFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("open session file: %w", err)
 	}
 	defer file.Close() //nolint:errcheck
 
-	scanner := newScanner(file)
+	scanner := model.NewLineScanner(file)
 	for scanner.Scan() {
-		recBytes := scanner.Bytes()
-		meta, ok, err := tryParseMeta(recBytes)
+		meta, ok, err := tryParseMeta(scanner.Bytes())
 		if err != nil {
 			return nil, fmt.Errorf("parse session_meta: %w", err)
 		}
@@ -71,20 +93,19 @@ func ReadSessionMeta(path string) (*CodexSessionMeta, error) {
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("scan session: %w", err)
 	}
-
 	return nil, ErrSessionMetaNotFound
 }
 
-// FirstUserSummary returns the first user message text (trimmed) and total
-// number of response_item entries found in the session.
+// FirstUserSummary returns the first user message text (trimmed) and the
+// number of user and assistant messages found in the session.
 func FirstUserSummary(path string) (summary string, messageCount int, lastTimestamp time.Time, err error) {
-	file, err := os.Open(path)
+	file, err := model.OpenSessionFile(path)
 	if err != nil {
 		return "", 0, time.Time{}, fmt.Errorf("open session file: %w", err)
 	}
 	defer file.Close() //nolint:errcheck
 
-	scanner := newScanner(file)
+	scanner := model.NewLineScanner(file)
 	for scanner.Scan() {
 		recBytes := scanner.Bytes()
 		event, err := parseEvent(recBytes)
@@ -96,11 +117,11 @@ func FirstUserSummary(path string) (summary string, messageCount int, lastTimest
 			lastTimestamp = event.Timestamp
 		}
 
-		if event.Kind == EntryTypeResponseItem {
+		if isMessage(event) {
 			messageCount++
-			if summary == "" && event.Role == PayloadRoleUser {
-				summary = buildSummaryText(event.Content)
-			}
+		}
+		if summary == "" && event.Kind == EntryTypeResponseItem && event.Role == PayloadRoleUser {
+			summary = buildSummaryText(event.Content)
 		}
 	}
 
@@ -111,16 +132,37 @@ func FirstUserSummary(path string) (summary string, messageCount int, lastTimest
 	return summary, messageCount, lastTimestamp, nil
 }
 
+// CountMessages returns the number of user and assistant message
+// response_items in the session.
+func CountMessages(path string) (int, error) {
+	var count int
+	err := IterateEvents(path, func(event CodexEvent) error {
+		if isMessage(event) {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// isMessage reports whether event is a user or assistant message response_item.
+func isMessage(event CodexEvent) bool {
+	if event.Kind != EntryTypeResponseItem || event.PayloadType != string(ResponseItemTypeMessage) {
+		return false
+	}
+	return event.Role == PayloadRoleUser || event.Role == PayloadRoleAssistant
+}
+
 // IterateEvents walks through the session JSONL file and calls fn for each
 // decoded event.
 func IterateEvents(path string, fn func(CodexEvent) error) error {
-	file, err := os.Open(path)
+	file, err := model.OpenSessionFile(path)
 	if err != nil {
 		return fmt.Errorf("open session file: %w", err)
 	}
 	defer file.Close() //nolint:errcheck
 
-	scanner := newScanner(file)
+	scanner := model.NewLineScanner(file)
 	for scanner.Scan() {
 		recBytes := scanner.Bytes()
 		event, err := parseEvent(recBytes)
@@ -140,6 +182,25 @@ func IterateEvents(path string, fn func(CodexEvent) error) error {
 	return nil
 }
 
+// decodeCustomToolCall renders a custom_tool_call with its tool name always
+// present. Custom tools send free-form input rather than JSON arguments.
+func decodeCustomToolCall(payload functionCallPayload) []model.ContentBlock {
+	name := payload.Name
+	if name == "" {
+		name = "(unnamed)"
+	}
+	blocks := []model.ContentBlock{{Type: "custom_tool_name", Text: name}}
+
+	input := payload.Input
+	if input == "" {
+		input = payload.Arguments
+	}
+	if input != "" {
+		return append(blocks, model.ContentBlock{Type: "function_arguments", Text: input})
+	}
+	return append(blocks, decodeContentBlocks(payload.Content)...)
+}
+
 // buildSummaryText concatenates the first content block texts.
 func buildSummaryText(blocks []model.ContentBlock) string {
 	if len(blocks) == 0 {
@@ -163,15 +224,6 @@ func buildSummaryText(blocks []model.ContentBlock) string {
 	return builder.String()
 }
 
-func newScanner(file *os.File) *bufio.Scanner {
-	scanner := bufio.NewScanner(file)
-	// Allow large payloads such as instructions blocks.
-	const maxCapacity = 8 * 1024 * 1024
-	buf := make([]byte, 1024)
-	scanner.Buffer(buf, maxCapacity)
-	return scanner
-}
-
 type rawRecord struct {
 	Timestamp string          `json:"timestamp"`
 	Type      string          `json:"type"`
@@ -184,11 +236,18 @@ type sessionMetaPayload struct {
 	CWD        string `json:"cwd"`
 	Originator string `json:"originator"`
 	CLIVersion string `json:"cli_version"`
+	// Resumed sessions link back to the session they continue. Both names
+	// have been seen in the wild.
+	ParentSessionID   string `json:"parent_session_id"`
+	PreviousSessionID string `json:"previous_session_id"`
+	// Instructions is the system prompt the session started with.
+	Instructions string `json:"instructions"`
 }
 
 type contentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	ImageURL string `json:"image_url"`
 }
 
 type legacyMeta struct {
@@ -204,6 +263,7 @@ type functionCallPayload struct {
 	Role      string          `json:"role"`
 	Name      string          `json:"name"`
 	Arguments string          `json:"arguments"`
+	Input     string          `json:"input"` // custom_tool_call arguments
 	Output    string          `json:"output"`
 	Content   json.RawMessage `json:"content"`
 	Summary   json.RawMessage `json:"summary"`
@@ -228,6 +288,25 @@ type eventMsgPayload struct {
 	Text    string          `json:"text"`
 	Message string          `json:"message"`
 	Info    *tokenCountInfo `json:"info"`
+	// task_started
+	ModelContextWindow int `json:"model_context_window"`
+	// exec_command_begin and exec_command_end
+	CallID           string   `json:"call_id"`
+	Command          []string `json:"command"`
+	ExitCode         *int     `json:"exit_code"`
+	AggregatedOutput string   `json:"aggregated_output"`
+	FormattedOutput  string   `json:"formatted_output"`
+	Stdout           string   `json:"stdout"`
+	Stderr           string   `json:"stderr"`
+	// mcp_tool_call_begin and mcp_tool_call_end
+	Invocation *mcpInvocation  `json:"invocation"`
+	Result     json.RawMessage `json:"result"`
+}
+
+type mcpInvocation struct {
+	Server    string          `json:"server"`
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments"`
 }
 
 type turnContextPayload struct {
@@ -296,7 +375,12 @@ func tryParseMeta(raw []byte) (*CodexSessionMeta, bool, error) {
 		Originator: payload.Originator,
 		CLIVersion: payload.CLIVersion,
 		StartedAt:  start,
+		ParentID:   payload.ParentSessionID,
 	}
+	if meta.ParentID == "" {
+		meta.ParentID = payload.PreviousSessionID
+	}
+	meta.Instructions = payload.Instructions
 
 	return meta, true, nil
 }
@@ -343,7 +427,9 @@ func parseEvent(raw []byte) (CodexEvent, error) {
 
 		// Handle function_call and custom_tool_call types
 		switch payload.Type {
-		case "function_call", "custom_tool_call":
+		case "custom_tool_call":
+			event.Content = decodeCustomToolCall(payload)
+		case "function_call":
 			if payload.Name != "" {
 				event.Content = []model.ContentBlock{
 					{Type: "function_name", Text: payload.Name},
@@ -361,12 +447,10 @@ func parseEvent(raw []byte) (CodexEvent, error) {
 			} else {
 				event.Content = decodeContentBlocks(payload.Content)
 			}
+		case "reasoning":
+			event.Content = decodeReasoningBlocks(payload)
 		default:
 			event.Content = decodeContentBlocks(payload.Content)
-			// If content is empty or null, try summary (for encrypted reasoning)
-			if len(event.Content) == 0 && len(payload.Summary) > 0 {
-				event.Content = decodeContentBlocks(payload.Summary)
-			}
 		}
 	case EntryTypeEventMsg:
 		var payload eventMsgPayload
@@ -397,6 +481,8 @@ func parseEvent(raw []byte) (CodexEvent, error) {
 					text += fmt.Sprintf(" [%d reasoning]", usage.ReasoningTokens)
 				}
 				blocks = append(blocks, model.ContentBlock{Type: "text", Text: text})
+				event.InputTokens = payload.Info.LastTokenUsage.InputTokens
+				event.OutputTokens = payload.Info.LastTokenUsage.OutputTokens
 			} else {
 				blocks = append(blocks, model.ContentBlock{Type: "text", Text: "Token usage unavailable"})
 			}
@@ -406,9 +492,32 @@ func parseEvent(raw []byte) (CodexEvent, error) {
 			}
 		case "turn_aborted":
 			blocks = append(blocks, model.ContentBlock{Type: "text", Text: "Turn aborted"})
+		case "error":
+			blocks = append(blocks, model.ContentBlock{Type: "text", Text: "Error: " + payload.Message})
+		case "task_started":
+			text := "Task started"
+			if payload.ModelContextWindow > 0 {
+				text += fmt.Sprintf(" (context window: %d tokens)", payload.ModelContextWindow)
+			}
+			blocks = append(blocks, model.ContentBlock{Type: "text", Text: text})
+		case "task_complete":
+			blocks = append(blocks, model.ContentBlock{Type: "text", Text: "Task complete"})
+		case "exec_command_begin":
+			event.CallID = payload.CallID
+			blocks = append(blocks, model.ContentBlock{Type: "command", Text: strings.Join(payload.Command, " ")})
+		case "exec_command_end":
+			event.CallID = payload.CallID
+			blocks = execEndBlocks(payload)
+		case "mcp_tool_call_begin", "mcp_tool_call_end":
+			blocks = mcpToolBlocks(payload)
 		default:
-			// Fallback to JSON for unknown event_msg types
-			blocks = decodeContentBlocks(rec.Payload)
+			// Unknown event_msg types: show their message when they have
+			// one, otherwise fall back to JSON.
+			if text := firstNonEmpty(payload.Message, payload.Text, payload.Content); text != "" {
+				blocks = append(blocks, model.ContentBlock{Type: "text", Text: text})
+			} else {
+				blocks = decodeContentBlocks(rec.Payload)
+			}
 		}
 		event.Content = blocks
 	case EntryTypeTurnContext:
@@ -417,6 +526,8 @@ func parseEvent(raw []byte) (CodexEvent, error) {
 			return CodexEvent{}, fmt.Errorf("unmarshal turn_context payload: %w", err)
 		}
 		event.PayloadType = "turn_context"
+		event.Model = payload.Model
+		event.Effort = payload.Effort
 
 		// Build content based on available fields
 		var text string
@@ -451,6 +562,51 @@ func parseEvent(raw []byte) (CodexEvent, error) {
 	return event, nil
 }
 
+// execEndBlocks renders an exec_command_end event as the command output
+// followed by its exit code, so it reads on from the "$ <command>" line of
+// the begin event. The output's final newline is dropped so the exit code
+// follows it directly.
+func execEndBlocks(payload eventMsgPayload) []model.ContentBlock {
+	var blocks []model.ContentBlock
+	output := firstNonEmpty(payload.AggregatedOutput, payload.FormattedOutput, payload.Stdout+payload.Stderr)
+	output = strings.TrimSuffix(output, "\n")
+	if output != "" {
+		blocks = append(blocks, model.ContentBlock{Type: "function_output", Text: output})
+	}
+	if payload.ExitCode != nil {
+		blocks = append(blocks, model.ContentBlock{Type: "text", Text: fmt.Sprintf("Exit code: %d", *payload.ExitCode)})
+	}
+	return blocks
+}
+
+// mcpToolBlocks renders an MCP tool call as server.tool with its arguments,
+// or with its result once the call has ended.
+func mcpToolBlocks(payload eventMsgPayload) []model.ContentBlock {
+	if payload.Invocation == nil {
+		return nil
+	}
+	name := payload.Invocation.Tool
+	if payload.Invocation.Server != "" {
+		name = payload.Invocation.Server + "." + name
+	}
+	blocks := []model.ContentBlock{{Type: "function_name", Text: name}}
+	if len(payload.Result) > 0 {
+		blocks = append(blocks, model.ContentBlock{Type: "function_output", Text: string(payload.Result)})
+	} else if len(payload.Invocation.Arguments) > 0 {
+		blocks = append(blocks, model.ContentBlock{Type: "function_arguments", Text: string(payload.Invocation.Arguments)})
+	}
+	return blocks
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
 func decodeContentBlocks(raw json.RawMessage) []model.ContentBlock {
 	if len(raw) == 0 {
 		return nil
@@ -460,6 +616,10 @@ func decodeContentBlocks(raw json.RawMessage) []model.ContentBlock {
 	if err := json.Unmarshal(raw, &array); err == nil {
 		blocks := make([]model.ContentBlock, 0, len(array))
 		for _, item := range array {
+			if item.Type == "input_image" {
+				blocks = append(blocks, model.ContentBlock{Type: "image", Text: describeImageURL(item.ImageURL)})
+				continue
+			}
 			blocks = append(blocks, model.ContentBlock{
 				Type: item.Type,
 				Text: item.Text,
@@ -477,6 +637,44 @@ func decodeContentBlocks(raw json.RawMessage) []model.ContentBlock {
 	return []model.ContentBlock{{Type: "json", Text: string(raw)}}
 }
 
+// describeImageURL returns the placeholder for an input_image. Inline images
+// are data URIs ("data:image/png;base64,..."); anything else is shown as is.
+func describeImageURL(url string) string {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		if url == "" {
+			return model.DescribeAttachment("", "")
+		}
+		return url
+	}
+	header, data, _ := strings.Cut(rest, ",")
+	mediaType, _, _ := strings.Cut(header, ";")
+	return model.DescribeAttachment(mediaType, data)
+}
+
+// decodeReasoningBlocks normalizes a reasoning payload into "reasoning" blocks.
+// Encrypted reasoning has null content, so the summary is used instead; when
+// neither carries text a placeholder is returned so the entry is not empty.
+func decodeReasoningBlocks(payload functionCallPayload) []model.ContentBlock {
+	source := decodeContentBlocks(payload.Content)
+	if len(source) == 0 && len(payload.Summary) > 0 {
+		source = decodeContentBlocks(payload.Summary)
+	}
+
+	blocks := make([]model.ContentBlock, 0, len(source))
+	for _, block := range source {
+		if strings.TrimSpace(block.Text) == "" {
+			continue
+		}
+		blocks = append(blocks, model.ContentBlock{Type: "reasoning", Text: block.Text})
+	}
+
+	if len(blocks) == 0 {
+		return []model.ContentBlock{{Type: "reasoning", Text: EncryptedReasoningPlaceholder}}
+	}
+	return blocks
+}
+
 func parseTimestamp(value string) (time.Time, error) {
 	if value == "" {
 		return time.Time{}, errors.New("missing timestamp")
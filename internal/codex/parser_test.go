@@ -1,7 +1,10 @@
 package codex
 
 import (
+	"agentlog/internal/model"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -67,3 +70,200 @@ func TestIterateEvents_Filtered(t *testing.T) {
 		t.Fatalf("expected 4 response events, got %d", len(events))
 	}
 }
+
+func TestIterateEvents_Reasoning(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "reasoning.jsonl")
+
+	var reasoning []CodexEvent
+	err := IterateEvents(path, func(evt CodexEvent) error {
+		if evt.PayloadType == string(ResponseItemTypeReasoning) {
+			reasoning = append(reasoning, evt)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+
+	if len(reasoning) != 3 {
+		t.Fatalf("expected 3 reasoning events, got %d", len(reasoning))
+	}
+
+	want := []string{
+		"**Inspecting test output**",
+		EncryptedReasoningPlaceholder,
+		"The assertion compares the wrong field",
+	}
+	for i, evt := range reasoning {
+		if len(evt.Content) != 1 {
+			t.Fatalf("event %d: expected 1 content block, got %d", i, len(evt.Content))
+		}
+		if evt.Content[0].Type != "reasoning" {
+			t.Fatalf("event %d: expected reasoning block, got %s", i, evt.Content[0].Type)
+		}
+		if evt.Content[0].Text != want[i] {
+			t.Fatalf("event %d: unexpected text: %q", i, evt.Content[0].Text)
+		}
+	}
+}
+
+func TestReadSessionMeta_Instructions(t *testing.T) {
+	meta, err := ReadSessionMeta(filepath.Join("..", "..", "testdata", "codex-edge-cases", "instructions.jsonl"))
+	if err != nil {
+		t.Fatalf("ReadSessionMeta returned error: %v", err)
+	}
+	if !strings.HasPrefix(meta.GetInstructions(), "You are Codex") || !strings.HasSuffix(meta.GetInstructions(), "files you changed.") {
+		t.Fatalf("unexpected instructions: %q", meta.GetInstructions())
+	}
+
+	meta, err = ReadSessionMeta(filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl"))
+	if err != nil {
+		t.Fatalf("ReadSessionMeta returned error: %v", err)
+	}
+	if meta.Instructions != "" {
+		t.Fatalf("expected no instructions, got %q", meta.Instructions)
+	}
+}
+
+func TestIterateEvents_TurnContext(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "turn-context.jsonl")
+
+	var models, efforts []string
+	if err := IterateEvents(path, func(evt CodexEvent) error {
+		if evt.GetModel() != "" {
+			models = append(models, evt.GetModel())
+		}
+		if evt.GetEffort() != "" {
+			efforts = append(efforts, evt.GetEffort())
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	if len(models) != 2 || models[0] != "gpt-5-codex" || models[1] != "gpt-5" {
+		t.Fatalf("unexpected turn_context models: %v", models)
+	}
+	if len(efforts) == 0 || efforts[len(efforts)-1] != "high" {
+		t.Fatalf("expected the latest turn_context effort to be high, got %v", efforts)
+	}
+}
+
+func TestIterateEvents_CustomToolCall(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "custom-tool-call.jsonl")
+
+	var calls []CodexEvent
+	err := IterateEvents(path, func(evt CodexEvent) error {
+		if evt.PayloadType == "custom_tool_call" {
+			calls = append(calls, evt)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 custom tool calls, got %d", len(calls))
+	}
+
+	first := calls[0].Content
+	if len(first) != 2 || first[0].Type != "custom_tool_name" || first[0].Text != "apply_patch" {
+		t.Fatalf("expected apply_patch name block, got %+v", first)
+	}
+	if first[1].Type != "function_arguments" || !strings.HasPrefix(first[1].Text, "*** Begin Patch") {
+		t.Fatalf("expected patch input as arguments, got %+v", first[1])
+	}
+
+	if unnamed := calls[1].Content; len(unnamed) == 0 || unnamed[0].Text != "(unnamed)" {
+		t.Fatalf("expected placeholder name for unnamed call, got %+v", unnamed)
+	}
+}
+
+func TestIterateEvents_InputImage(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "image.jsonl")
+
+	var images []model.ContentBlock
+	err := IterateEvents(path, func(evt CodexEvent) error {
+		for _, block := range evt.Content {
+			if block.Type == "image" {
+				images = append(images, block)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	if len(images) != 1 || images[0].Text != "png, 3KB" {
+		t.Fatalf("expected one png image placeholder, got %+v", images)
+	}
+}
+
+func TestDescribeImageURL(t *testing.T) {
+	if got := describeImageURL("https://example.com/shot.png"); got != "https://example.com/shot.png" {
+		t.Fatalf("remote image: got %q", got)
+	}
+	if got := describeImageURL("data:image/jpeg;base64,/9j/4AAQ"); got != "jpeg, 6B" {
+		t.Fatalf("data URI: got %q", got)
+	}
+	if got := describeImageURL(""); got != "unknown" {
+		t.Fatalf("missing URL: got %q", got)
+	}
+}
+
+func TestIterateEvents_EventMsgTypes(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "event-msgs.jsonl")
+
+	rendered := map[string]string{}
+	if err := IterateEvents(path, func(evt CodexEvent) error {
+		if evt.Kind != EntryTypeEventMsg {
+			return nil
+		}
+		var parts []string
+		for _, block := range evt.Content {
+			parts = append(parts, block.Type+":"+block.Text)
+		}
+		rendered[evt.PayloadType] = strings.Join(parts, "|")
+		return nil
+	}); err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+
+	expected := map[string]string{
+		"task_started":       "text:Task started (context window: 272000 tokens)",
+		"exec_command_begin": "command:ls -la",
+		"exec_command_end":   "function_output:README.md|text:Exit code: 0",
+		"mcp_tool_call_end":  `function_name:docs.search|function_output:{"Ok":{"content":[{"type":"text","text":"found"}]}}`,
+		"error":              "text:Error: stream disconnected before completion",
+		"background_event":   "text:Retrying request",
+		"task_complete":      "text:Task complete",
+	}
+	for payloadType, want := range expected {
+		if got := rendered[payloadType]; got != want {
+			t.Fatalf("%s rendered as %q, want %q", payloadType, got, want)
+		}
+	}
+}
+
+func TestIterateEvents_LineLongerThanScannerBuffer(t *testing.T) {
+	// A single record larger than bufio.Scanner's old 8MB cap.
+	text := strings.Repeat("a", 9*1024*1024)
+	content := `{"timestamp":"2025-11-05T09:00:00Z","type":"session_meta","payload":{"id":"huge-line","timestamp":"2025-11-05T09:00:00Z","cwd":"/tmp"}}` + "\n" +
+		`{"timestamp":"2025-11-05T09:00:01Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"` + text + `"}]}}` + "\n"
+	path := filepath.Join(t.TempDir(), "huge.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	var got int
+	if err := IterateEvents(path, func(evt CodexEvent) error {
+		if evt.Role == PayloadRoleUser && len(evt.Content) > 0 {
+			got = len(evt.Content[0].Text)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	if got != len(text) {
+		t.Fatalf("expected %d bytes of text, got %d", len(text), got)
+	}
+}
@@ -0,0 +1,222 @@
+package format
+
+import (
+	"agentlog/internal/model"
+	"agentlog/internal/util"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/mattn/go-runewidth"
+)
+
+// column is one column of the table, plain, and csv list formats.
+type column struct {
+	name   string // --columns name
+	header string // table header label
+	field  string // plain and csv header
+	align  text.Align
+	empty  interface{} // table cell of the "(no sessions)" row
+	// value returns the cell text for plain and csv output.
+	value func(item model.SessionSummaryProvider, opts SummaryOptions) string
+	// cell returns the table cell; when nil, value is used.
+	cell func(item model.SessionSummaryProvider, opts SummaryOptions) interface{}
+	// extra columns are shown only when named in --columns.
+	extra bool
+}
+
+// columnRegistry lists every known column, the default ones first in the
+// default order.
+var columnRegistry = []column{
+	{
+		name: "time", header: "Timestamp", field: "timestamp", align: text.AlignLeft, empty: "-",
+		value: func(item model.SessionSummaryProvider, opts SummaryOptions) string {
+			return opts.Time.Format(item.GetStartedAt())
+		},
+	},
+	{
+		name: "id", header: "Session ID", field: "session_id", align: text.AlignLeft, empty: "(no sessions)",
+		value: func(item model.SessionSummaryProvider, _ SummaryOptions) string {
+			return item.GetID()
+		},
+		cell: func(item model.SessionSummaryProvider, opts SummaryOptions) interface{} {
+			return opts.Links.SessionID(item.GetID(), item.GetPath())
+		},
+	},
+	{
+		name: "cwd", header: "CWD", field: "cwd", align: text.AlignLeft, empty: "-",
+		value: func(item model.SessionSummaryProvider, _ SummaryOptions) string {
+			return item.GetCWD()
+		},
+	},
+	{
+		name: "duration", header: "Duration", field: "duration", align: text.AlignCenter, empty: "00:00:00",
+		value: func(item model.SessionSummaryProvider, opts SummaryOptions) string {
+			return opts.Duration.Format(item.GetDurationSeconds())
+		},
+	},
+	{
+		name: "messages", header: "Messages", field: "message_count", align: text.AlignRight, empty: 0,
+		value: func(item model.SessionSummaryProvider, _ SummaryOptions) string {
+			return strconv.Itoa(item.GetMessageCount())
+		},
+		cell: func(item model.SessionSummaryProvider, _ SummaryOptions) interface{} {
+			return item.GetMessageCount()
+		},
+	},
+	{
+		name: "summary", header: "Summary", field: "summary", align: text.AlignLeft, empty: "-",
+		value: summaryText,
+		cell: func(item model.SessionSummaryProvider, opts SummaryOptions) interface{} {
+			summary := summaryText(item, opts)
+			width := opts.summaryColumnWidth()
+			if opts.FullSummary {
+				return breakLongWords(summary, width)
+			}
+			if opts.SummaryLines > 0 {
+				return breakLongWords(firstLines(summary, opts.SummaryLines), width)
+			}
+			// Truncate by display width so CJK and emoji, which take two
+			// columns each, cannot push the row past the column limit.
+			return runewidth.Truncate(escapeNewlines(summary), width, util.Ellipsis)
+		},
+	},
+	{
+		name: "path", header: "Path", field: "path", align: text.AlignLeft, empty: "-", extra: true,
+		value: func(item model.SessionSummaryProvider, _ SummaryOptions) string {
+			return item.GetPath()
+		},
+	},
+	{
+		name: "tags", header: "Tags", field: "tags", align: text.AlignLeft, empty: "-", extra: true,
+		value: func(item model.SessionSummaryProvider, opts SummaryOptions) string {
+			return strings.Join(opts.Tags[item.GetID()], ", ")
+		},
+	},
+}
+
+// ColumnNames returns the names accepted by --columns, the default ones
+// first in the default order.
+func ColumnNames() []string {
+	names := make([]string, len(columnRegistry))
+	for i, col := range columnRegistry {
+		names[i] = col.name
+	}
+	return names
+}
+
+// DefaultColumnNames returns the names of the columns shown when --columns
+// is not given.
+func DefaultColumnNames() []string {
+	var names []string
+	for _, col := range defaultColumns() {
+		names = append(names, col.name)
+	}
+	return names
+}
+
+// defaultColumns returns the registry without the extra columns.
+func defaultColumns() []column {
+	columns := make([]column, 0, len(columnRegistry))
+	for _, col := range columnRegistry {
+		if !col.extra {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// ValidateColumns reports an error for unknown or repeated column names.
+func ValidateColumns(names []string) error {
+	_, err := resolveColumns(names)
+	return err
+}
+
+// resolveColumns looks up the named columns in order. No names selects
+// the default columns.
+func resolveColumns(names []string) ([]column, error) {
+	if len(names) == 0 {
+		return defaultColumns(), nil
+	}
+	columns := make([]column, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		col, ok := lookupColumn(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (expected %s)", name, strings.Join(ColumnNames(), ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("column %q given more than once", name)
+		}
+		seen[name] = true
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// selectedColumns returns the columns resolved by WriteSummaries, or the
+// default columns when opts did not pass through it.
+func (opts SummaryOptions) selectedColumns() []column {
+	if opts.columns == nil {
+		return defaultColumns()
+	}
+	return opts.columns
+}
+
+func lookupColumn(name string) (column, bool) {
+	for _, col := range columnRegistry {
+		if col.name == name {
+			return col, true
+		}
+	}
+	return column{}, false
+}
+
+// columnFields returns the plain and csv header of columns.
+func columnFields(columns []column) []string {
+	fields := make([]string, len(columns))
+	for i, col := range columns {
+		fields[i] = col.field
+	}
+	return fields
+}
+
+// columnValues returns the plain and csv cells of item.
+func columnValues(columns []column, item model.SessionSummaryProvider, opts SummaryOptions) []string {
+	values := make([]string, len(columns))
+	for i, col := range columns {
+		values[i] = col.value(item, opts)
+	}
+	return values
+}
+
+// tableRow returns the table cells of item.
+func tableRow(columns []column, item model.SessionSummaryProvider, opts SummaryOptions) table.Row {
+	row := make(table.Row, len(columns))
+	for i, col := range columns {
+		if col.cell != nil {
+			row[i] = col.cell(item, opts)
+		} else {
+			row[i] = col.value(item, opts)
+		}
+	}
+	return row
+}
+
+// emptyTableRow returns the row shown when there are no sessions. The
+// "(no sessions)" note moves to the first column when id is not selected.
+func emptyTableRow(columns []column) table.Row {
+	row := make(table.Row, len(columns))
+	hasID := false
+	for i, col := range columns {
+		row[i] = col.empty
+		hasID = hasID || col.name == "id"
+	}
+	if !hasID && len(row) > 0 {
+		row[0] = "(no sessions)"
+	}
+	return row
+}
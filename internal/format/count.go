@@ -0,0 +1,31 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NewJSONEncoder returns an encoder for single JSON documents: indented by
+// two spaces, or on one line when compact is set. Line-delimited formats
+// never indent.
+func NewJSONEncoder(w io.Writer, compact bool) *json.Encoder {
+	enc := json.NewEncoder(w)
+	if !compact {
+		enc.SetIndent("", "  ")
+	}
+	return enc
+}
+
+// WriteCount writes a match count to w. JSON formats emit {"count": N};
+// every other format prints the bare integer.
+func WriteCount(w io.Writer, count int, format string) error {
+	switch strings.ToLower(format) {
+	case "json", "jsonl":
+		return json.NewEncoder(w).Encode(map[string]int{"count": count})
+	default:
+		_, err := fmt.Fprintln(w, count)
+		return err
+	}
+}
@@ -0,0 +1,66 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DurationFormat selects how session durations are displayed.
+type DurationFormat string
+
+const (
+	// DurationClock shows HH:MM:SS, with hours growing past 24. It is the
+	// default.
+	DurationClock DurationFormat = "clock"
+	// DurationHuman shows the largest units only, e.g. 1d 3h 10m or 45s.
+	DurationHuman DurationFormat = "human"
+)
+
+// ParseDurationFormat validates a --duration-format value. An empty value
+// selects DurationClock.
+func ParseDurationFormat(value string) (DurationFormat, error) {
+	switch DurationFormat(strings.ToLower(strings.TrimSpace(value))) {
+	case "", DurationClock:
+		return DurationClock, nil
+	case DurationHuman:
+		return DurationHuman, nil
+	default:
+		return "", fmt.Errorf("invalid duration format %q (expected clock or human)", value)
+	}
+}
+
+// Format renders a duration given in whole seconds. Negative durations are
+// shown as zero.
+func (f DurationFormat) Format(seconds int) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	if f == DurationHuman {
+		return humanDuration(seconds)
+	}
+	h := seconds / 3600
+	m := (seconds % 3600) / 60
+	s := seconds % 60
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+}
+
+// humanDuration names days, hours, minutes, and seconds, leaving out units
+// that are zero. Seconds are dropped once a duration reaches an hour.
+func humanDuration(seconds int) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%ds", seconds)
+	}
+	var parts []string
+	add := func(value int, unit string) {
+		if value > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", value, unit))
+		}
+	}
+	add(seconds/86400, "d")
+	add(seconds%86400/3600, "h")
+	add(seconds%3600/60, "m")
+	if seconds < 3600 {
+		add(seconds%60, "s")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,44 @@
+package format
+
+import "testing"
+
+func TestDurationFormat(t *testing.T) {
+	tests := []struct {
+		seconds int
+		clock   string
+		human   string
+	}{
+		{seconds: 0, clock: "00:00:00", human: "0s"},
+		{seconds: 45, clock: "00:00:45", human: "45s"},
+		{seconds: 125, clock: "00:02:05", human: "2m 5s"},
+		{seconds: 3*3600 + 10*60 + 7, clock: "03:10:07", human: "3h 10m"},
+		{seconds: 2 * 3600, clock: "02:00:00", human: "2h"},
+		{seconds: 27*3600 + 10*60 + 5, clock: "27:10:05", human: "1d 3h 10m"},
+		{seconds: 2*86400 + 30, clock: "48:00:30", human: "2d"},
+		{seconds: -5, clock: "00:00:00", human: "0s"},
+	}
+
+	for _, tt := range tests {
+		if got := DurationClock.Format(tt.seconds); got != tt.clock {
+			t.Fatalf("clock format of %ds = %q, want %q", tt.seconds, got, tt.clock)
+		}
+		if got := DurationHuman.Format(tt.seconds); got != tt.human {
+			t.Fatalf("human format of %ds = %q, want %q", tt.seconds, got, tt.human)
+		}
+	}
+}
+
+func TestParseDurationFormat(t *testing.T) {
+	for value, want := range map[string]DurationFormat{"": DurationClock, "clock": DurationClock, "Human": DurationHuman} {
+		got, err := ParseDurationFormat(value)
+		if err != nil {
+			t.Fatalf("ParseDurationFormat(%q) returned error: %v", value, err)
+		}
+		if got != want {
+			t.Fatalf("ParseDurationFormat(%q) = %q, want %q", value, got, want)
+		}
+	}
+	if _, err := ParseDurationFormat("minutes"); err == nil {
+		t.Fatal("expected error for unknown duration format")
+	}
+}
@@ -0,0 +1,72 @@
+package format
+
+import (
+	"agentlog/internal/model"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// EventRecord is the normalized, agent-agnostic JSON shape of a single event.
+type EventRecord struct {
+	Index     int               `json:"index"`
+	Timestamp string            `json:"timestamp,omitempty"`
+	Role      string            `json:"role"`
+	Content   []ContentRecord   `json:"content"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// ContentRecord is the JSON shape of a content block.
+type ContentRecord struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ToolName  string          `json:"tool_name,omitempty"`
+	ToolInput json.RawMessage `json:"tool_input,omitempty"`
+}
+
+// NewEventRecord converts event into its normalized JSON shape. Timestamps are
+// always emitted as UTC RFC3339.
+func NewEventRecord(event model.EventProvider, index int) EventRecord {
+	record := EventRecord{
+		Index:   index,
+		Role:    event.GetRole(),
+		Content: make([]ContentRecord, 0, len(event.GetContent())),
+	}
+	if ts := event.GetTimestamp(); !ts.IsZero() {
+		record.Timestamp = ts.UTC().Format(time.RFC3339Nano)
+	}
+	for _, block := range event.GetContent() {
+		record.Content = append(record.Content, ContentRecord{
+			Type:      block.Type,
+			Text:      block.Text,
+			ToolName:  block.ToolName,
+			ToolInput: block.ToolInput,
+		})
+	}
+	if provider, ok := event.(model.EventMetadataProvider); ok {
+		if meta := provider.GetMetadata(); len(meta) > 0 {
+			record.Metadata = meta
+		}
+	}
+	return record
+}
+
+// WriteEventJSON writes event as a single line of JSON. HTML characters are
+// left unescaped since transcripts routinely contain code.
+func WriteEventJSON(w io.Writer, event model.EventProvider, index int) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(NewEventRecord(event, index))
+}
+
+// WriteEventJSONArray writes records as one indented JSON array, for
+// consumers that cannot read one object per line. An empty slice is written
+// as [].
+func WriteEventJSONArray(w io.Writer, records []EventRecord) error {
+	if records == nil {
+		records = []EventRecord{}
+	}
+	enc := NewJSONEncoder(w, false)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(records)
+}
@@ -0,0 +1,166 @@
+package format
+
+import (
+	"agentlog/internal/model"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// summaryGroup holds the sessions that share a --group-by key.
+type summaryGroup struct {
+	Key   string
+	Items []model.SessionSummaryProvider
+}
+
+// groupSummaries partitions items by the requested dimension ("day" or "cwd").
+// Groups appear in the order of their first session, so reverse-chronological
+// input yields the most recently active group first, and sessions keep their
+// relative order within each group.
+func groupSummaries(items []model.SessionSummaryProvider, groupBy string, tf TimeFormatter) ([]summaryGroup, error) {
+	keyFn, err := groupKeyFunc(groupBy, tf)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []summaryGroup
+	index := make(map[string]int)
+	for _, item := range items {
+		key := keyFn(item)
+		idx, ok := index[key]
+		if !ok {
+			idx = len(groups)
+			index[key] = idx
+			groups = append(groups, summaryGroup{Key: key})
+		}
+		groups[idx].Items = append(groups[idx].Items, item)
+	}
+	return groups, nil
+}
+
+func groupKeyFunc(groupBy string, tf TimeFormatter) (func(model.SessionSummaryProvider) string, error) {
+	switch strings.ToLower(groupBy) {
+	case "day":
+		return func(item model.SessionSummaryProvider) string {
+			ts := item.GetStartedAt()
+			if ts.IsZero() {
+				return "(unknown)"
+			}
+			if tf.Location != nil {
+				ts = ts.In(tf.Location)
+			}
+			return ts.Format("2006-01-02")
+		}, nil
+	case "cwd":
+		return func(item model.SessionSummaryProvider) string {
+			if item.GetCWD() == "" {
+				return "(unknown)"
+			}
+			return item.GetCWD()
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported group-by value: %s", groupBy)
+	}
+}
+
+// groupHeader renders the separator line shown above each group.
+func groupHeader(group summaryGroup) string {
+	noun := "sessions"
+	if len(group.Items) == 1 {
+		noun = "session"
+	}
+	return fmt.Sprintf("── %s (%d %s) ──", group.Key, len(group.Items), noun)
+}
+
+// writeGroupedSummaries renders items with a header above each group.
+func writeGroupedSummaries(w io.Writer, items []model.SessionSummaryProvider, opts SummaryOptions) error {
+	groups, err := groupSummaries(items, opts.GroupBy, opts.Time)
+	if err != nil {
+		return err
+	}
+
+	columns := opts.selectedColumns()
+	format := strings.ToLower(opts.Format)
+	switch format {
+	case "", "table":
+		tw := newSummaryTable(w, opts)
+		for _, group := range groups {
+			header := groupHeader(group)
+			row := make(table.Row, len(columns))
+			for i := range row {
+				row[i] = header
+			}
+			tw.AppendRow(row, table.RowConfig{AutoMerge: true, AutoMergeAlign: text.AlignLeft})
+			appendTableRows(tw, group.Items, opts)
+		}
+		if len(groups) == 0 {
+			tw.AppendRow(emptyTableRow(columns))
+		}
+		_ = tw.Render()
+		return nil
+	case "plain":
+		if opts.IncludeHeader {
+			if err := writePlainHeader(w, opts); err != nil {
+				return err
+			}
+		}
+		for _, group := range groups {
+			if _, err := fmt.Fprintln(w, groupHeader(group)); err != nil {
+				return err
+			}
+			if err := writePlainRows(w, group.Items, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		output := make([]map[string]interface{}, len(groups))
+		for i, group := range groups {
+			sessions := make([]map[string]interface{}, len(group.Items))
+			for j, item := range group.Items {
+				sessions[j] = summaryRecord(item)
+			}
+			output[i] = map[string]interface{}{
+				"group":    group.Key,
+				"count":    len(group.Items),
+				"sessions": sessions,
+			}
+		}
+		return NewJSONEncoder(w, opts.CompactJSON).Encode(output)
+	case "jsonl":
+		enc := json.NewEncoder(w)
+		for _, group := range groups {
+			for _, item := range group.Items {
+				record := summaryRecord(item)
+				record["group"] = group.Key
+				if err := enc.Encode(record); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		if opts.IncludeHeader {
+			if err := cw.Write(append([]string{"group"}, columnFields(columns)...)); err != nil {
+				return err
+			}
+		}
+		for _, group := range groups {
+			for _, item := range group.Items {
+				if err := cw.Write(append([]string{group.Key}, columnValues(columns, item, opts)...)); err != nil {
+					return err
+				}
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
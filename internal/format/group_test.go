@@ -0,0 +1,110 @@
+package format
+
+import (
+	"agentlog/internal/codex"
+	"agentlog/internal/model"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func groupedSummaries() []model.SessionSummaryProvider {
+	codexSummaries := []codex.CodexSessionSummary{
+		{
+			ID:        "session-c",
+			CWD:       "/tmp/project",
+			StartedAt: time.Date(2025, 10, 2, 18, 0, 0, 0, time.UTC),
+			Summary:   "Gamma",
+		},
+		{
+			ID:        "session-b",
+			CWD:       "/tmp/other",
+			StartedAt: time.Date(2025, 10, 2, 9, 30, 0, 0, time.UTC),
+			Summary:   "Beta",
+		},
+		{
+			ID:        "session-a",
+			CWD:       "/tmp/project",
+			StartedAt: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC),
+			Summary:   "Alpha",
+		},
+	}
+	result := make([]model.SessionSummaryProvider, len(codexSummaries))
+	for i := range codexSummaries {
+		result[i] = &codexSummaries[i]
+	}
+	return result
+}
+
+func TestWriteSummariesGroupByCWDPlain(t *testing.T) {
+	var buf bytes.Buffer
+	opts := SummaryOptions{Format: "plain", GroupBy: "cwd"}
+	if err := WriteSummaries(&buf, groupedSummaries(), opts); err != nil {
+		t.Fatalf("WriteSummaries returned error: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"── /tmp/project (2 sessions) ──",
+		"2025-10-02T18:00:00Z\tsession-c\t/tmp/project\t00:00:00\t0\tGamma",
+		"2025-10-01T12:00:00Z\tsession-a\t/tmp/project\t00:00:00\t0\tAlpha",
+		"── /tmp/other (1 session) ──",
+		"2025-10-02T09:30:00Z\tsession-b\t/tmp/other\t00:00:00\t0\tBeta",
+	}, "\n") + "\n"
+	if got := buf.String(); got != expected {
+		t.Fatalf("grouped output mismatch:\nexpected: %q\nactual:   %q", expected, got)
+	}
+}
+
+func TestWriteSummariesGroupByDayTable(t *testing.T) {
+	var buf bytes.Buffer
+	opts := SummaryOptions{Format: "table", IncludeHeader: true, GroupBy: "day"}
+	if err := WriteSummaries(&buf, groupedSummaries(), opts); err != nil {
+		t.Fatalf("WriteSummaries returned error: %v", err)
+	}
+
+	output := buf.String()
+	first := strings.Index(output, "── 2025-10-02 (2 sessions) ──")
+	second := strings.Index(output, "── 2025-10-01 (1 session) ──")
+	if first < 0 || second < 0 || first > second {
+		t.Fatalf("expected day headers in reverse-chronological order, got:\n%s", output)
+	}
+	if gamma, beta := strings.Index(output, "session-c"), strings.Index(output, "session-b"); gamma > beta {
+		t.Fatalf("expected newest session first within group, got:\n%s", output)
+	}
+}
+
+func TestWriteSummariesGroupByDayJSON(t *testing.T) {
+	var buf bytes.Buffer
+	opts := SummaryOptions{Format: "json", GroupBy: "day"}
+	if err := WriteSummaries(&buf, groupedSummaries(), opts); err != nil {
+		t.Fatalf("WriteSummaries returned error: %v", err)
+	}
+
+	var groups []struct {
+		Group    string                   `json:"group"`
+		Count    int                      `json:"count"`
+		Sessions []map[string]interface{} `json:"sessions"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &groups); err != nil {
+		t.Fatalf("failed to decode grouped JSON: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+	if groups[0].Group != "2025-10-02" || groups[0].Count != 2 || len(groups[0].Sessions) != 2 {
+		t.Fatalf("unexpected first group: %+v", groups[0])
+	}
+	if groups[1].Group != "2025-10-01" || groups[1].Sessions[0]["id"] != "session-a" {
+		t.Fatalf("unexpected second group: %+v", groups[1])
+	}
+}
+
+func TestWriteSummariesGroupByUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteSummaries(&buf, groupedSummaries(), SummaryOptions{GroupBy: "month"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported group-by") {
+		t.Fatalf("expected unsupported group-by error, got %v", err)
+	}
+}
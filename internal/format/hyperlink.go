@@ -0,0 +1,59 @@
+package format
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Hyperlinks turns paths and session IDs into OSC 8 terminal hyperlinks.
+// The zero value leaves text unchanged.
+type Hyperlinks struct {
+	Enabled bool
+	// SessionURL is the link target for session IDs. "{id}" and "{path}" are
+	// replaced with the session ID and file path; empty links to the file.
+	SessionURL string
+}
+
+// Path returns path linked to its file:// URL.
+func (h Hyperlinks) Path(path string) string {
+	if !h.Enabled || path == "" {
+		return path
+	}
+	return osc8Link(path, fileURL(path))
+}
+
+// SessionID returns id linked to the SessionURL template, or to the session
+// file when no template is set.
+func (h Hyperlinks) SessionID(id, path string) string {
+	if !h.Enabled || id == "" {
+		return id
+	}
+	if h.SessionURL == "" {
+		if path == "" {
+			return id
+		}
+		return osc8Link(id, fileURL(path))
+	}
+	target := strings.NewReplacer(
+		"{id}", url.PathEscape(id),
+		"{path}", url.PathEscape(path),
+	).Replace(h.SessionURL)
+	return osc8Link(id, target)
+}
+
+// osc8Link wraps text in an OSC 8 escape sequence pointing at target.
+// Terminals without OSC 8 support print text unchanged.
+func osc8Link(text, target string) string {
+	if target == "" {
+		return text
+	}
+	return "\x1b]8;;" + target + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+func fileURL(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
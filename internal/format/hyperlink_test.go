@@ -0,0 +1,61 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOSC8Link(t *testing.T) {
+	got := osc8Link("session-a", "https://example.com/s/session-a")
+	want := "\x1b]8;;https://example.com/s/session-a\x1b\\session-a\x1b]8;;\x1b\\"
+	if got != want {
+		t.Fatalf("osc8Link = %q, want %q", got, want)
+	}
+	if got := osc8Link("plain", ""); got != "plain" {
+		t.Fatalf("osc8Link without target = %q", got)
+	}
+}
+
+func TestHyperlinks(t *testing.T) {
+	disabled := Hyperlinks{SessionURL: "https://example.com/{id}"}
+	if got := disabled.Path("/tmp/a.jsonl"); got != "/tmp/a.jsonl" {
+		t.Fatalf("disabled Path = %q", got)
+	}
+	if got := disabled.SessionID("session-a", "/tmp/a.jsonl"); got != "session-a" {
+		t.Fatalf("disabled SessionID = %q", got)
+	}
+
+	enabled := Hyperlinks{Enabled: true}
+	if got := enabled.Path("/tmp/a b.jsonl"); got != osc8Link("/tmp/a b.jsonl", "file:///tmp/a%20b.jsonl") {
+		t.Fatalf("enabled Path = %q", got)
+	}
+	if got := enabled.SessionID("session-a", "/tmp/a.jsonl"); got != osc8Link("session-a", "file:///tmp/a.jsonl") {
+		t.Fatalf("enabled SessionID without template = %q", got)
+	}
+
+	enabled.SessionURL = "https://example.com/s/{id}"
+	if got := enabled.SessionID("session-a", "/tmp/a.jsonl"); got != osc8Link("session-a", "https://example.com/s/session-a") {
+		t.Fatalf("enabled SessionID with template = %q", got)
+	}
+}
+
+func TestWriteSummariesTableHyperlinks(t *testing.T) {
+	var buf bytes.Buffer
+	opts := SummaryOptions{Format: "table", IncludeHeader: true, Links: Hyperlinks{Enabled: true, SessionURL: "https://example.com/{id}"}}
+	if err := WriteSummaries(&buf, sampleSummaries(), opts); err != nil {
+		t.Fatalf("WriteSummaries returned error: %v", err)
+	}
+	if !strings.Contains(buf.String(), osc8Link("session-a", "https://example.com/session-a")) {
+		t.Fatalf("expected linked session ID in table:\n%q", buf.String())
+	}
+
+	buf.Reset()
+	opts.Links = Hyperlinks{}
+	if err := WriteSummaries(&buf, sampleSummaries(), opts); err != nil {
+		t.Fatalf("WriteSummaries returned error: %v", err)
+	}
+	if strings.Contains(buf.String(), "\x1b]8;;") {
+		t.Fatalf("expected no hyperlinks when disabled:\n%q", buf.String())
+	}
+}
@@ -0,0 +1,84 @@
+package format
+
+import (
+	"agentlog/internal/store"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+type issuesPayload struct {
+	FilesScanned int           `json:"files_scanned"`
+	Errors       int           `json:"errors"`
+	Warnings     int           `json:"warnings"`
+	Issues       []store.Issue `json:"issues"`
+}
+
+// WriteIssues writes validation results to w in the requested format.
+func WriteIssues(w io.Writer, result store.ValidateResult, format string) error {
+	format = strings.ToLower(format)
+	switch format {
+	case "", "table":
+		return writeIssuesTable(w, result)
+	case "json":
+		return writeIssuesJSON(w, result)
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+func writeIssuesJSON(w io.Writer, result store.ValidateResult) error {
+	issues := result.Issues
+	if issues == nil {
+		issues = []store.Issue{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issuesPayload{
+		FilesScanned: result.FilesScanned,
+		Errors:       result.ErrorCount(),
+		Warnings:     result.WarningCount(),
+		Issues:       issues,
+	})
+}
+
+func writeIssuesTable(w io.Writer, result store.ValidateResult) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(w)
+	tw.SetStyle(table.StyleRounded)
+	tw.Style().Options.SeparateRows = false
+	tw.Style().Options.SeparateHeader = true
+	tw.Style().Options.DrawBorder = true
+
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Number: 2, Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Number: 3, Align: text.AlignLeft, AlignHeader: text.AlignCenter},
+		{Number: 4, Align: text.AlignRight, AlignHeader: text.AlignCenter},
+		{Number: 5, Align: text.AlignLeft, AlignHeader: text.AlignCenter, WidthMax: 80},
+	})
+
+	tw.AppendHeader(table.Row{"Severity", "Kind", "Path", "Line", "Message"})
+
+	for _, issue := range result.Issues {
+		line := "-"
+		if issue.Line > 0 {
+			line = fmt.Sprintf("%d", issue.Line)
+		}
+		tw.AppendRow(table.Row{issue.Severity, issue.Kind, issue.Path, line, issue.Message})
+	}
+
+	if len(result.Issues) == 0 {
+		tw.AppendRow(table.Row{"-", "(no issues)", "-", "-", "-"})
+	}
+
+	_ = tw.Render()
+
+	_, err := fmt.Fprintf(w, "Scanned %d files: %d errors, %d warnings\n",
+		result.FilesScanned, result.ErrorCount(), result.WarningCount())
+	return err
+}
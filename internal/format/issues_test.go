@@ -0,0 +1,53 @@
+package format
+
+import (
+	"agentlog/internal/store"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func sampleValidateResult() store.ValidateResult {
+	return store.ValidateResult{
+		FilesScanned: 3,
+		Issues: []store.Issue{
+			{Path: "/tmp/a.jsonl", Line: 2, Kind: store.IssueMalformedLine, Severity: store.SeverityError, Message: "line is not valid JSON"},
+			{Path: "/tmp/b.jsonl", Kind: store.IssueNoEvents, Severity: store.SeverityWarning, Message: "file contains no events"},
+		},
+	}
+}
+
+func TestWriteIssuesTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteIssues(&buf, sampleValidateResult(), "table"); err != nil {
+		t.Fatalf("WriteIssues table returned error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "malformed_line") || !strings.Contains(out, "/tmp/b.jsonl") {
+		t.Fatalf("table missing issues:\n%s", out)
+	}
+	if !strings.Contains(out, "Scanned 3 files: 1 errors, 1 warnings") {
+		t.Fatalf("table missing summary line:\n%s", out)
+	}
+}
+
+func TestWriteIssuesJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteIssues(&buf, sampleValidateResult(), "json"); err != nil {
+		t.Fatalf("WriteIssues json returned error: %v", err)
+	}
+
+	var payload struct {
+		FilesScanned int           `json:"files_scanned"`
+		Errors       int           `json:"errors"`
+		Issues       []store.Issue `json:"issues"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("decode json output: %v", err)
+	}
+	if payload.FilesScanned != 3 || payload.Errors != 1 || len(payload.Issues) != 2 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
@@ -3,100 +3,249 @@ package format
 
 import (
 	"agentlog/internal/model"
+	"agentlog/internal/util"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
-	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/mattn/go-runewidth"
 )
 
+// SummaryOptions controls how session summaries are written.
+type SummaryOptions struct {
+	Format        string
+	IncludeHeader bool
+	GroupBy       string // "", "day", or "cwd"
+	FullSummary   bool   // wrap the table summary column and keep its line breaks
+	SummaryLines  int    // wrap the table summary column and show up to this many of its lines; 0 keeps one clipped line
+	Time          TimeFormatter
+	Links         Hyperlinks // link session IDs in the table format
+	Page          *PageInfo  // when set, json output wraps the sessions with paging details
+	CompactJSON   bool       // write json on a single line instead of indenting it
+	// MarkInterrupted prefixes the summary of sessions that ended mid-turn
+	// with InterruptedMarker in the table, plain, and csv formats.
+	MarkInterrupted bool
+	// Duration selects how the duration column is shown; empty means
+	// DurationClock.
+	Duration DurationFormat
+	// Columns selects and orders the columns of the table, plain, and csv
+	// formats by name (see ColumnNames); empty means the default columns.
+	Columns []string
+	// Width is the terminal width the table should fit. The summary column
+	// takes what the other columns leave, but at least summaryWidthMin.
+	// 0 caps the summary column at summaryWidthMax instead.
+	Width int
+	// Tags holds the tags of each session id for the tags column.
+	Tags map[string][]string
+
+	columns      []column // resolved from Columns by WriteSummaries
+	summaryWidth int      // fitted to Width by WriteSummaries for tables
+}
+
+// InterruptedMarker prefixes the summaries of interrupted sessions.
+const InterruptedMarker = "[interrupted] "
+
+// PageInfo describes the page of sessions being written.
+type PageInfo struct {
+	Page     int // 1-based
+	PageSize int
+	Total    int // sessions across all pages
+}
+
+// pages returns the number of pages needed for Total sessions.
+func (p PageInfo) pages() int {
+	if p.PageSize <= 0 {
+		return 1
+	}
+	return (p.Total + p.PageSize - 1) / p.PageSize
+}
+
 // WriteSummaries writes session summaries to w in the requested format.
-func WriteSummaries(w io.Writer, items []model.SessionSummaryProvider, includeHeader bool, format string) error {
-	format = strings.ToLower(format)
+func WriteSummaries(w io.Writer, items []model.SessionSummaryProvider, opts SummaryOptions) error {
+	columns, err := resolveColumns(opts.Columns)
+	if err != nil {
+		return err
+	}
+	opts.columns = columns
+	opts.summaryWidth = fitSummaryWidth(items, opts)
+
+	if opts.GroupBy != "" {
+		return writeGroupedSummaries(w, items, opts)
+	}
+
+	format := strings.ToLower(opts.Format)
 	switch format {
 	case "", "table":
-		return writeSummariesTable(w, items, includeHeader)
+		return writeSummariesTable(w, items, opts)
 	case "plain":
-		return writeSummariesPlain(w, items, includeHeader)
+		return writeSummariesPlain(w, items, opts)
 	case "json":
-		return writeSummariesJSON(w, items)
+		if opts.Page != nil {
+			return writeSummariesPageJSON(w, items, *opts.Page, opts.CompactJSON)
+		}
+		return writeSummariesJSON(w, items, opts.CompactJSON)
 	case "jsonl":
 		return writeSummariesJSONL(w, items)
+	case "csv":
+		return writeSummariesCSV(w, items, opts)
 	default:
 		return fmt.Errorf("unsupported format: %s", format)
 	}
 }
 
-func writeSummariesPlain(w io.Writer, items []model.SessionSummaryProvider, includeHeader bool) error {
-	if includeHeader {
-		if _, err := fmt.Fprintln(w, "timestamp\tsession_id\tcwd\tduration\tmessage_count\tsummary"); err != nil {
+func writeSummariesPlain(w io.Writer, items []model.SessionSummaryProvider, opts SummaryOptions) error {
+	if opts.IncludeHeader {
+		if err := writePlainHeader(w, opts); err != nil {
 			return err
 		}
 	}
+	return writePlainRows(w, items, opts)
+}
 
+func writePlainHeader(w io.Writer, opts SummaryOptions) error {
+	_, err := fmt.Fprintln(w, strings.Join(columnFields(opts.selectedColumns()), "\t"))
+	return err
+}
+
+// writePlainRows writes one tab-separated line per session. Newlines in
+// values are escaped as \n so each session stays on one line.
+func writePlainRows(w io.Writer, items []model.SessionSummaryProvider, opts SummaryOptions) error {
+	columns := opts.selectedColumns()
 	for _, item := range items {
-		line := fmt.Sprintf(
-			"%s\t%s\t%s\t%s\t%d\t%s",
-			item.GetStartedAt().Format(time.RFC3339),
-			item.GetID(),
-			item.GetCWD(),
-			formatDuration(item.GetDurationSeconds()),
-			item.GetMessageCount(),
-			escapeNewlines(item.GetSummary()),
-		)
-		if _, err := fmt.Fprintln(w, line); err != nil {
+		values := columnValues(columns, item, opts)
+		for i, value := range values {
+			values[i] = escapeNewlines(value)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(values, "\t")); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func writeSummariesJSON(w io.Writer, items []model.SessionSummaryProvider) error {
+// writeSummariesCSV writes RFC 4180 rows. Summaries keep their line breaks;
+// encoding/csv quotes fields that contain commas, quotes, or newlines.
+func writeSummariesCSV(w io.Writer, items []model.SessionSummaryProvider, opts SummaryOptions) error {
+	columns := opts.selectedColumns()
+	cw := csv.NewWriter(w)
+	if opts.IncludeHeader {
+		if err := cw.Write(columnFields(columns)); err != nil {
+			return err
+		}
+	}
+	for _, item := range items {
+		if err := cw.Write(columnValues(columns, item, opts)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// summaryText returns the summary of item, marked when requested and the
+// session was interrupted.
+func summaryText(item model.SessionSummaryProvider, opts SummaryOptions) string {
+	if opts.MarkInterrupted && isInterrupted(item) {
+		return InterruptedMarker + item.GetSummary()
+	}
+	return item.GetSummary()
+}
+
+func isInterrupted(item model.SessionSummaryProvider) bool {
+	provider, ok := item.(model.InterruptedProvider)
+	return ok && provider.IsInterrupted()
+}
+
+// summaryRecord converts a summary into its JSON representation.
+func summaryRecord(item model.SessionSummaryProvider) map[string]interface{} {
+	record := map[string]interface{}{
+		"id":               item.GetID(),
+		"path":             item.GetPath(),
+		"cwd":              item.GetCWD(),
+		"started_at":       item.GetStartedAt(),
+		"summary":          item.GetSummary(),
+		"message_count":    item.GetMessageCount(),
+		"duration_seconds": item.GetDurationSeconds(),
+	}
+	if provider, ok := item.(model.InterruptedProvider); ok {
+		record["interrupted"] = provider.IsInterrupted()
+	}
+	return record
+}
+
+func writeSummariesJSON(w io.Writer, items []model.SessionSummaryProvider, compact bool) error {
 	// Convert to a serializable format
 	output := make([]map[string]interface{}, len(items))
 	for i, item := range items {
-		output[i] = map[string]interface{}{
-			"id":              item.GetID(),
-			"path":            item.GetPath(),
-			"cwd":             item.GetCWD(),
-			"started_at":      item.GetStartedAt(),
-			"summary":         item.GetSummary(),
-			"message_count":   item.GetMessageCount(),
-			"duration_seconds": item.GetDurationSeconds(),
-		}
+		output[i] = summaryRecord(item)
 	}
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(output)
+	return NewJSONEncoder(w, compact).Encode(output)
+}
+
+// writeSummariesPageJSON writes one page of sessions together with the
+// totals clients need to request the other pages.
+func writeSummariesPageJSON(w io.Writer, items []model.SessionSummaryProvider, page PageInfo, compact bool) error {
+	sessions := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		sessions[i] = summaryRecord(item)
+	}
+	return NewJSONEncoder(w, compact).Encode(map[string]interface{}{
+		"page":      page.Page,
+		"page_size": page.PageSize,
+		"pages":     page.pages(),
+		"total":     page.Total,
+		"sessions":  sessions,
+	})
 }
 
 func writeSummariesJSONL(w io.Writer, items []model.SessionSummaryProvider) error {
 	enc := json.NewEncoder(w)
 	for _, item := range items {
-		output := map[string]interface{}{
-			"id":               item.GetID(),
-			"path":             item.GetPath(),
-			"cwd":              item.GetCWD(),
-			"started_at":       item.GetStartedAt(),
-			"summary":          item.GetSummary(),
-			"message_count":    item.GetMessageCount(),
-			"duration_seconds": item.GetDurationSeconds(),
-		}
-		if err := enc.Encode(output); err != nil {
+		if err := enc.Encode(summaryRecord(item)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// firstLines returns the first n lines of text, marking the last with "…"
+// when lines were dropped. Blank lines are skipped so they do not use up n.
+func firstLines(text string, n int) string {
+	var kept []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(kept) == n {
+			kept[n-1] += util.Ellipsis
+			break
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
 func escapeNewlines(text string) string {
 	return strings.ReplaceAll(text, "\n", "\\n")
 }
 
-func writeSummariesTable(w io.Writer, items []model.SessionSummaryProvider, includeHeader bool) error {
+func writeSummariesTable(w io.Writer, items []model.SessionSummaryProvider, opts SummaryOptions) error {
+	tw := newSummaryTable(w, opts)
+	appendTableRows(tw, items, opts)
+
+	if len(items) == 0 {
+		tw.AppendRow(emptyTableRow(opts.selectedColumns()))
+	}
+
+	_ = tw.Render()
+	return nil
+}
+
+func newSummaryTable(w io.Writer, opts SummaryOptions) table.Writer {
 	tw := table.NewWriter()
 	tw.SetOutputMirror(w)
 	tw.SetStyle(table.StyleRounded)
@@ -104,44 +253,102 @@ func writeSummariesTable(w io.Writer, items []model.SessionSummaryProvider, incl
 	tw.Style().Options.SeparateHeader = true
 	tw.Style().Options.DrawBorder = true
 
-	tw.SetColumnConfigs([]table.ColumnConfig{
-		{Number: 1, Align: text.AlignLeft, AlignHeader: text.AlignCenter},
-		{Number: 2, Align: text.AlignLeft, AlignHeader: text.AlignCenter},
-		{Number: 3, Align: text.AlignLeft, AlignHeader: text.AlignCenter},
-		{Number: 4, Align: text.AlignCenter, AlignHeader: text.AlignCenter},
-		{Number: 5, Align: text.AlignRight, AlignHeader: text.AlignCenter},
-		{Number: 6, Align: text.AlignLeft, AlignHeader: text.AlignCenter, WidthMax: 80},
-	})
+	columns := opts.selectedColumns()
+	configs := make([]table.ColumnConfig, len(columns))
+	header := make(table.Row, len(columns))
+	for i, col := range columns {
+		configs[i] = table.ColumnConfig{Number: i + 1, Align: col.align, AlignHeader: text.AlignCenter}
+		if col.name == "summary" {
+			configs[i] = summaryColumnConfig(i+1, opts.summaryColumnWidth(), opts.FullSummary || opts.SummaryLines > 0)
+		}
+		header[i] = col.header
+	}
+	tw.SetColumnConfigs(configs)
 
-	if includeHeader {
-		tw.AppendHeader(table.Row{"Timestamp", "Session ID", "CWD", "Duration", "Messages", "Summary"})
+	if opts.IncludeHeader {
+		tw.AppendHeader(header)
 	}
+	return tw
+}
 
-	for _, item := range items {
-		tw.AppendRow(table.Row{
-			item.GetStartedAt().Format(time.RFC3339),
-			item.GetID(),
-			item.GetCWD(),
-			formatDuration(item.GetDurationSeconds()),
-			item.GetMessageCount(),
-			escapeNewlines(item.GetSummary()),
-		})
+// summaryWidthMax is the widest the table summary column gets, in terminal
+// columns, when the terminal width is unknown.
+const summaryWidthMax = 80
+
+// summaryWidthMin is the narrowest the summary column is squeezed to on a
+// narrow terminal; the table overflows instead.
+const summaryWidthMin = 20
+
+// summaryColumnWidth returns the widest the table summary column may get.
+func (opts SummaryOptions) summaryColumnWidth() int {
+	if opts.summaryWidth > 0 {
+		return opts.summaryWidth
 	}
+	return summaryWidthMax
+}
 
-	if len(items) == 0 {
-		tw.AppendRow(table.Row{"-", "(no sessions)", "-", "00:00:00", 0, "-"})
+// fitSummaryWidth returns the summary column width that makes a table of
+// items as wide as opts.Width, or 0 when the width is unknown. Every column
+// is drawn with a space on either side and a border after it.
+func fitSummaryWidth(items []model.SessionSummaryProvider, opts SummaryOptions) int {
+	if opts.Width <= 0 {
+		return 0
+	}
+	used := 1 // left border
+	for _, col := range opts.selectedColumns() {
+		used += 3
+		if col.name == "summary" {
+			continue
+		}
+		width := 0
+		if opts.IncludeHeader {
+			width = text.RuneWidthWithoutEscSequences(col.header)
+		}
+		for _, item := range items {
+			var cell interface{}
+			if col.cell != nil {
+				cell = col.cell(item, opts)
+			} else {
+				cell = col.value(item, opts)
+			}
+			width = max(width, text.RuneWidthWithoutEscSequences(fmt.Sprint(cell)))
+		}
+		used += width
 	}
+	return max(opts.Width-used, summaryWidthMin)
+}
 
-	_ = tw.Render()
-	return nil
+// summaryColumnConfig configures the summary column, the number-th column of
+// the table, to be at most width wide. Full and multi-line summaries wrap on
+// word boundaries so long first messages stay readable.
+func summaryColumnConfig(number, width int, full bool) table.ColumnConfig {
+	cfg := table.ColumnConfig{Number: number, Align: text.AlignLeft, AlignHeader: text.AlignCenter, WidthMax: width}
+	if full {
+		cfg.WidthMaxEnforcer = text.WrapSoft
+	}
+	return cfg
 }
 
-func formatDuration(seconds int) string {
-	if seconds <= 0 {
-		return "00:00:00"
+func appendTableRows(tw table.Writer, items []model.SessionSummaryProvider, opts SummaryOptions) {
+	columns := opts.selectedColumns()
+	for _, item := range items {
+		tw.AppendRow(tableRow(columns, item, opts))
+	}
+}
+
+// breakLongWords puts line breaks inside every word wider than width, which
+// soft wrapping alone would leave overflowing. Text without spaces, as is
+// common in CJK, is one long word.
+func breakLongWords(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		words := strings.Split(line, " ")
+		for j, word := range words {
+			if runewidth.StringWidth(word) > width {
+				words[j] = strings.Join(splitLongWords([]string{word}, width), "\n")
+			}
+		}
+		lines[i] = strings.Join(words, " ")
 	}
-	h := seconds / 3600
-	m := (seconds % 3600) / 60
-	s := seconds % 60
-	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+	return strings.Join(lines, "\n")
 }
@@ -4,9 +4,12 @@ import (
 	"agentlog/internal/codex"
 	"agentlog/internal/model"
 	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/mattn/go-runewidth"
 )
 
 func sampleSummaries() []model.SessionSummaryProvider {
@@ -40,7 +43,7 @@ func TestWriteSummariesPlain(t *testing.T) {
 	var buf bytes.Buffer
 	items := sampleSummaries()
 
-	if err := WriteSummaries(&buf, items, true, "plain"); err != nil {
+	if err := WriteSummaries(&buf, items, SummaryOptions{Format: "plain", IncludeHeader: true}); err != nil {
 		t.Fatalf("WriteSummaries plain returned error: %v", err)
 	}
 
@@ -55,11 +58,32 @@ func TestWriteSummariesPlain(t *testing.T) {
 	}
 }
 
+func TestWriteSummariesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	items := sampleSummaries()
+	summary := items[0].(*codex.CodexSessionSummary)
+	summary.Summary = "Fix parser, then \"retry\"\nsecond line"
+
+	if err := WriteSummaries(&buf, items, SummaryOptions{Format: "csv", IncludeHeader: true}); err != nil {
+		t.Fatalf("WriteSummaries csv returned error: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"timestamp,session_id,cwd,duration,message_count,summary",
+		"2025-10-01T12:00:00Z,session-a,/tmp/project,00:01:30,10,\"Fix parser, then \"\"retry\"\"\nsecond line\"",
+		"2025-10-02T09:30:00Z,session-b,/tmp/other,00:00:45,20,Beta",
+	}, "\n") + "\n"
+
+	if got := buf.String(); got != expected {
+		t.Fatalf("csv output mismatch:\nexpected: %q\nactual:   %q", expected, got)
+	}
+}
+
 func TestWriteSummariesTable(t *testing.T) {
 	var buf bytes.Buffer
 	items := sampleSummaries()
 
-	if err := WriteSummaries(&buf, items, true, "table"); err != nil {
+	if err := WriteSummaries(&buf, items, SummaryOptions{Format: "table", IncludeHeader: true}); err != nil {
 		t.Fatalf("WriteSummaries table returned error: %v", err)
 	}
 
@@ -73,9 +97,90 @@ func TestWriteSummariesTable(t *testing.T) {
 	}
 }
 
+func TestWriteSummariesTableWidth(t *testing.T) {
+	items := sampleSummaries()
+	items[0].(*codex.CodexSessionSummary).Summary = strings.Repeat("lorem ipsum ", 30)
+
+	tableWidth := func(opts SummaryOptions) int {
+		t.Helper()
+		var buf bytes.Buffer
+		opts.Format = "table"
+		opts.IncludeHeader = true
+		if err := WriteSummaries(&buf, items, opts); err != nil {
+			t.Fatalf("WriteSummaries table returned error: %v", err)
+		}
+		widest := 0
+		for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+			widest = max(widest, runewidth.StringWidth(line))
+		}
+		return widest
+	}
+
+	// Without a width the summary column stops at summaryWidthMax.
+	fixed := tableWidth(SummaryOptions{})
+	for _, width := range []int{100, 200} {
+		if got := tableWidth(SummaryOptions{Width: width}); got != width {
+			t.Fatalf("Width %d: table is %d columns wide", width, got)
+		}
+	}
+	if got := tableWidth(SummaryOptions{Width: 40}); got != fixed-summaryWidthMax+summaryWidthMin {
+		t.Fatalf("narrow terminal: table is %d columns wide, want the summary at its minimum", got)
+	}
+	if got := tableWidth(SummaryOptions{Width: 200, Columns: []string{"id", "summary"}}); got != 200 {
+		t.Fatalf("selected columns: table is %d columns wide, want 200", got)
+	}
+}
+
+func TestWriteSummariesColumns(t *testing.T) {
+	items := sampleSummaries()
+	opts := SummaryOptions{IncludeHeader: true, Columns: []string{"messages", "id", "summary"}}
+
+	var buf bytes.Buffer
+	opts.Format = "plain"
+	if err := WriteSummaries(&buf, items, opts); err != nil {
+		t.Fatalf("WriteSummaries plain returned error: %v", err)
+	}
+	expected := strings.Join([]string{
+		"message_count\tsession_id\tsummary",
+		"10\tsession-a\tAlpha",
+		"20\tsession-b\tBeta",
+	}, "\n") + "\n"
+	if got := buf.String(); got != expected {
+		t.Fatalf("plain output mismatch:\nexpected: %q\nactual:   %q", expected, got)
+	}
+
+	buf.Reset()
+	opts.Format = "csv"
+	if err := WriteSummaries(&buf, items, opts); err != nil {
+		t.Fatalf("WriteSummaries csv returned error: %v", err)
+	}
+	if got := strings.SplitN(buf.String(), "\n", 2)[0]; got != "message_count,session_id,summary" {
+		t.Fatalf("unexpected csv header: %q", got)
+	}
+
+	buf.Reset()
+	opts.Format = "table"
+	if err := WriteSummaries(&buf, items, opts); err != nil {
+		t.Fatalf("WriteSummaries table returned error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "│ MESSAGES │ SESSION ID │ SUMMARY │") || !strings.Contains(out, "│       10 │ session-a  │ Alpha   │") {
+		t.Fatalf("table columns not in the requested order:\n%s", out)
+	}
+	if strings.Contains(out, "CWD") || strings.Contains(out, "/tmp/project") {
+		t.Fatalf("unselected cwd column should be omitted:\n%s", out)
+	}
+
+	for _, columns := range [][]string{{"id", "bogus"}, {"id", "id"}} {
+		if err := ValidateColumns(columns); err == nil {
+			t.Fatalf("expected ValidateColumns(%q) to fail", columns)
+		}
+	}
+}
+
 func TestWriteSummariesInvalidFormat(t *testing.T) {
 	var buf bytes.Buffer
-	err := WriteSummaries(&buf, sampleSummaries(), true, "xml")
+	err := WriteSummaries(&buf, sampleSummaries(), SummaryOptions{Format: "xml", IncludeHeader: true})
 	if err == nil {
 		t.Fatal("expected error for unsupported format")
 	}
@@ -85,7 +190,7 @@ func TestWriteSummariesJSONL(t *testing.T) {
 	var buf bytes.Buffer
 	items := sampleSummaries()
 
-	if err := WriteSummaries(&buf, items, false, "jsonl"); err != nil {
+	if err := WriteSummaries(&buf, items, SummaryOptions{Format: "jsonl"}); err != nil {
 		t.Fatalf("WriteSummaries jsonl returned error: %v", err)
 	}
 
@@ -98,3 +203,179 @@ func TestWriteSummariesJSONL(t *testing.T) {
 		t.Fatalf("first jsonl line unexpected: %s", lines[0])
 	}
 }
+
+func TestWriteSummariesCompactJSON(t *testing.T) {
+	items := sampleSummaries()
+
+	var pretty, compact, jsonl bytes.Buffer
+	if err := WriteSummaries(&pretty, items, SummaryOptions{Format: "json"}); err != nil {
+		t.Fatalf("WriteSummaries json returned error: %v", err)
+	}
+	if err := WriteSummaries(&compact, items, SummaryOptions{Format: "json", CompactJSON: true}); err != nil {
+		t.Fatalf("WriteSummaries compact json returned error: %v", err)
+	}
+	if err := WriteSummaries(&jsonl, items, SummaryOptions{Format: "jsonl", CompactJSON: false}); err != nil {
+		t.Fatalf("WriteSummaries jsonl returned error: %v", err)
+	}
+
+	if !strings.Contains(pretty.String(), "\n  {\n    \"cwd\"") {
+		t.Fatalf("expected indented json by default:\n%s", pretty.String())
+	}
+	if strings.Count(compact.String(), "\n") != 1 || !strings.HasPrefix(compact.String(), `[{"cwd":`) {
+		t.Fatalf("expected single-line json with CompactJSON:\n%s", compact.String())
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(compact.Bytes(), &decoded); err != nil || len(decoded) != len(items) {
+		t.Fatalf("compact json did not decode to %d sessions: %v", len(items), err)
+	}
+	if strings.Count(jsonl.String(), "\n") != len(items) {
+		t.Fatalf("jsonl must stay one compact record per line:\n%s", jsonl.String())
+	}
+}
+
+func TestWriteSummariesPagedJSON(t *testing.T) {
+	var buf bytes.Buffer
+	items := sampleSummaries()[1:]
+
+	page := &PageInfo{Page: 2, PageSize: 1, Total: 2}
+	if err := WriteSummaries(&buf, items, SummaryOptions{Format: "json", Page: page}); err != nil {
+		t.Fatalf("WriteSummaries json returned error: %v", err)
+	}
+
+	var got struct {
+		Page     int                      `json:"page"`
+		PageSize int                      `json:"page_size"`
+		Pages    int                      `json:"pages"`
+		Total    int                      `json:"total"`
+		Sessions []map[string]interface{} `json:"sessions"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("decode paged json: %v\n%s", err, buf.String())
+	}
+	if got.Page != 2 || got.PageSize != 1 || got.Pages != 2 || got.Total != 2 {
+		t.Fatalf("unexpected paging fields: %+v", got)
+	}
+	if len(got.Sessions) != 1 || got.Sessions[0]["id"] != "session-b" {
+		t.Fatalf("unexpected sessions: %v", got.Sessions)
+	}
+}
+
+func TestWriteSummariesFullSummaryNewlines(t *testing.T) {
+	long := strings.Repeat("word ", 40) + "\nsecond paragraph"
+	summaries := []codex.CodexSessionSummary{{
+		ID:        "session-long",
+		CWD:       "/tmp/project",
+		StartedAt: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC),
+		Summary:   long,
+	}}
+	items := []model.SessionSummaryProvider{&summaries[0]}
+
+	var plain bytes.Buffer
+	if err := WriteSummaries(&plain, items, SummaryOptions{Format: "plain", FullSummary: true}); err != nil {
+		t.Fatalf("WriteSummaries plain returned error: %v", err)
+	}
+	if lines := strings.Split(strings.TrimRight(plain.String(), "\n"), "\n"); len(lines) != 1 {
+		t.Fatalf("plain output should stay on one line, got %d lines", len(lines))
+	}
+	if !strings.Contains(plain.String(), `\nsecond paragraph`) {
+		t.Fatalf("plain output should escape newlines: %q", plain.String())
+	}
+
+	for _, formatName := range []string{"json", "jsonl"} {
+		var buf bytes.Buffer
+		if err := WriteSummaries(&buf, items, SummaryOptions{Format: formatName, FullSummary: true}); err != nil {
+			t.Fatalf("WriteSummaries %s returned error: %v", formatName, err)
+		}
+		if !strings.Contains(buf.String(), `"summary":`) || !strings.Contains(buf.String(), strings.TrimSpace(strings.Repeat("word ", 40))) {
+			t.Fatalf("%s output should keep the full summary: %s", formatName, buf.String())
+		}
+		if !strings.Contains(buf.String(), `\nsecond paragraph`) {
+			t.Fatalf("%s output should keep the newline as a JSON escape: %s", formatName, buf.String())
+		}
+	}
+
+	var tableBuf bytes.Buffer
+	if err := WriteSummaries(&tableBuf, items, SummaryOptions{Format: "table", FullSummary: true}); err != nil {
+		t.Fatalf("WriteSummaries table returned error: %v", err)
+	}
+	if strings.Contains(tableBuf.String(), "…") {
+		t.Fatalf("full summary table should wrap, not truncate:\n%s", tableBuf.String())
+	}
+	if !strings.Contains(tableBuf.String(), "second paragraph") {
+		t.Fatalf("full summary table should include the whole text:\n%s", tableBuf.String())
+	}
+}
+
+func TestWriteSummariesSummaryLines(t *testing.T) {
+	summaries := []codex.CodexSessionSummary{{
+		ID:        "session-lines",
+		CWD:       "/tmp/project",
+		StartedAt: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC),
+		Summary:   "Fix the login bug\n\nIt fails on Safari\nSee the attached log",
+	}}
+	items := []model.SessionSummaryProvider{&summaries[0]}
+
+	var buf bytes.Buffer
+	if err := WriteSummaries(&buf, items, SummaryOptions{Format: "table", SummaryLines: 2}); err != nil {
+		t.Fatalf("WriteSummaries returned error: %v", err)
+	}
+	out := buf.String()
+	first := strings.Index(out, "Fix the login bug")
+	second := strings.Index(out, "It fails on Safari…")
+	if first < 0 || second < 0 {
+		t.Fatalf("expected the first two summary lines, the second marked as clipped:\n%s", out)
+	}
+	if !strings.Contains(out[first:second], "\n") {
+		t.Fatalf("expected the summary lines on separate rows:\n%s", out)
+	}
+	if strings.Contains(out, "attached log") {
+		t.Fatalf("expected lines past the second to be dropped:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := WriteSummaries(&buf, items, SummaryOptions{Format: "table"}); err != nil {
+		t.Fatalf("WriteSummaries returned error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `Fix the login bug\n\nIt fails`) {
+		t.Fatalf("expected one escaped line without SummaryLines:\n%s", buf.String())
+	}
+}
+
+func TestWriteSummariesTableWideCharacters(t *testing.T) {
+	summaries := []codex.CodexSessionSummary{
+		{
+			ID:        "session-cjk",
+			CWD:       "/tmp/project",
+			StartedAt: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC),
+			Summary:   strings.Repeat("日本語の要約テキスト", 6) + " 🚀✨",
+		},
+		{
+			ID:        "session-ascii",
+			CWD:       "/tmp/project",
+			StartedAt: time.Date(2025, 10, 2, 12, 0, 0, 0, time.UTC),
+			Summary:   "Alpha",
+		},
+	}
+	items := []model.SessionSummaryProvider{&summaries[0], &summaries[1]}
+
+	for _, full := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := WriteSummaries(&buf, items, SummaryOptions{Format: "table", IncludeHeader: true, FullSummary: full}); err != nil {
+			t.Fatalf("WriteSummaries table returned error: %v", err)
+		}
+		lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+		want := runewidth.StringWidth(lines[0])
+		for _, line := range lines {
+			if got := runewidth.StringWidth(line); got != want {
+				t.Fatalf("full=%v: line width %d, want %d:\n%s", full, got, want, buf.String())
+			}
+			cells := strings.Split(line, "│")
+			if len(cells) < 3 {
+				continue
+			}
+			if got := runewidth.StringWidth(strings.TrimSpace(cells[len(cells)-2])); got > 80 {
+				t.Fatalf("full=%v: summary cell is %d columns wide, want at most 80:\n%s", full, got, buf.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RedactedPlaceholder replaces masked secrets.
+const RedactedPlaceholder = "***"
+
+// CWDPlaceholder replaces the session working directory when requested.
+const CWDPlaceholder = "<cwd>"
+
+// RedactRule rewrites every match of Pattern with Replacement, which may
+// reference submatches using regexp.Expand syntax ($1, ${name}).
+type RedactRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// secretRules mask common credential formats while keeping surrounding text.
+var secretRules = []RedactRule{
+	// Labelled values: api_key=..., "token": "...", password: ...
+	{
+		Pattern:     regexp.MustCompile(`(?i)((?:api[_-]?key|secret|token|password|passwd)["']?\s*[:=]\s*["']?)[^\s"'\\,;]{8,}`),
+		Replacement: "${1}" + RedactedPlaceholder,
+	},
+	{Pattern: regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]{16,}`), Replacement: "${1}" + RedactedPlaceholder},
+	{Pattern: regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`), Replacement: RedactedPlaceholder},
+	{Pattern: regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`), Replacement: RedactedPlaceholder},
+	{Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`), Replacement: RedactedPlaceholder},
+	{Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), Replacement: RedactedPlaceholder},
+}
+
+// RedactOptions configures NewRedactor.
+type RedactOptions struct {
+	HomeDir string // replaced with "~"; empty skips home redaction
+	CWD     string // replaced with CWDPlaceholder; empty keeps the cwd
+}
+
+// Redactor masks personal paths and secrets in rendered text. A nil Redactor
+// returns text unchanged.
+type Redactor struct {
+	rules []RedactRule
+}
+
+// NewRedactor builds a Redactor that masks secrets and, when configured,
+// replaces the working directory and home directory. The cwd rule runs first
+// so a cwd under the home directory is not rewritten to ~/... before it can
+// match.
+func NewRedactor(opts RedactOptions) *Redactor {
+	var rules []RedactRule
+	if cwd := strings.TrimRight(opts.CWD, "/"); cwd != "" {
+		rules = append(rules, pathRule(cwd, CWDPlaceholder))
+	}
+	if home := strings.TrimRight(opts.HomeDir, "/"); home != "" {
+		rules = append(rules, pathRule(home, "~"))
+	}
+	rules = append(rules, secretRules...)
+	return &Redactor{rules: rules}
+}
+
+// pathRule matches path only as a whole path component so /home/me does not
+// rewrite /home/media.
+func pathRule(path, replacement string) RedactRule {
+	return RedactRule{
+		Pattern:     regexp.MustCompile(regexp.QuoteMeta(path) + `([/\\]|$|[^A-Za-z0-9_.-])`),
+		Replacement: replacement + "${1}",
+	}
+}
+
+// Redact applies every rule to text in order.
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+	for _, rule := range r.rules {
+		text = rule.Pattern.ReplaceAllString(text, rule.Replacement)
+	}
+	return text
+}
@@ -0,0 +1,102 @@
+package format
+
+import (
+	"agentlog/internal/model"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData is the data a --template is executed with, once per session.
+type TemplateData struct {
+	ID              string
+	Path            string
+	CWD             string
+	StartedAt       time.Time
+	Summary         string
+	MessageCount    int
+	DurationSeconds int
+	Model           string
+	Effort          string
+}
+
+// NewTemplateData builds template data from a listed session.
+func NewTemplateData(item model.SessionSummaryProvider) TemplateData {
+	data := TemplateData{
+		ID:              item.GetID(),
+		Path:            item.GetPath(),
+		CWD:             item.GetCWD(),
+		StartedAt:       item.GetStartedAt(),
+		Summary:         item.GetSummary(),
+		MessageCount:    item.GetMessageCount(),
+		DurationSeconds: item.GetDurationSeconds(),
+	}
+	if provider, ok := item.(model.ModelProvider); ok {
+		data.Model = provider.GetModel()
+	}
+	if provider, ok := item.(model.EffortProvider); ok {
+		data.Effort = provider.GetEffort()
+	}
+	return data
+}
+
+// ParseTemplate parses a Go text/template with the helper functions
+// duration, relativeTime, and time available. time formats a timestamp with
+// timeFormat.
+func ParseTemplate(text string, timeFormat TimeFormatter) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"duration": DurationClock.Format,
+		"relativeTime": func(t time.Time) string {
+			return relativeTime(t, time.Now())
+		},
+		"time": timeFormat.Format,
+	}
+	tmpl, err := template.New("template").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderTemplate executes tmpl with data and ends the output with a newline
+// unless the template already does.
+func RenderTemplate(w io.Writer, tmpl *template.Template, data TemplateData) error {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+	out := b.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := io.WriteString(w, out)
+	return err
+}
+
+// relativeTime describes t relative to now, e.g. "5m ago" or "in 2h".
+func relativeTime(t, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := now.Sub(t)
+	suffix := " ago"
+	prefix := ""
+	if d < 0 {
+		d = -d
+		prefix, suffix = "in ", ""
+	}
+	var amount string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		amount = fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		amount = fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		amount = fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+	return prefix + amount + suffix
+}
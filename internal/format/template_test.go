@@ -0,0 +1,82 @@
+package format
+
+import (
+	"agentlog/internal/codex"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("{{.ID}} {{.MessageCount}} {{duration .DurationSeconds}} {{time .StartedAt}} {{.Summary}}", TimeFormatter{Layout: time.DateOnly})
+	if err != nil {
+		t.Fatalf("ParseTemplate returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	for _, item := range sampleSummaries() {
+		if err := RenderTemplate(&buf, tmpl, NewTemplateData(item)); err != nil {
+			t.Fatalf("RenderTemplate returned error: %v", err)
+		}
+	}
+	want := "session-a 10 00:01:30 2025-10-01 Alpha\nsession-b 20 00:00:45 2025-10-02 Beta\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n%q\nwant:\n%q", buf.String(), want)
+	}
+}
+
+func TestRenderTemplateModelEffort(t *testing.T) {
+	tmpl, err := ParseTemplate("{{.ID}} {{.Model}} {{.Effort}}", TimeFormatter{})
+	if err != nil {
+		t.Fatalf("ParseTemplate returned error: %v", err)
+	}
+
+	item := &codex.CodexSessionSummary{ID: "session-a", Model: "gpt-5-codex", Effort: "high"}
+	var buf bytes.Buffer
+	if err := RenderTemplate(&buf, tmpl, NewTemplateData(item)); err != nil {
+		t.Fatalf("RenderTemplate returned error: %v", err)
+	}
+	if want := "session-a gpt-5-codex high\n"; buf.String() != want {
+		t.Fatalf("unexpected output: %q, want %q", buf.String(), want)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	if _, err := ParseTemplate("{{.ID", TimeFormatter{}); err == nil || !strings.Contains(err.Error(), "parse template") {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+
+	tmpl, err := ParseTemplate("{{.Missing}}", TimeFormatter{})
+	if err != nil {
+		t.Fatalf("ParseTemplate returned error: %v", err)
+	}
+	var buf bytes.Buffer
+	err = RenderTemplate(&buf, tmpl, NewTemplateData(sampleSummaries()[0]))
+	if err == nil || !strings.Contains(err.Error(), "execute template") {
+		t.Fatalf("expected an execution error, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected no partial output, got %q", buf.String())
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Time{}, "-"},
+		{now.Add(-30 * time.Second), "just now"},
+		{now.Add(-5 * time.Minute), "5m ago"},
+		{now.Add(-3 * time.Hour), "3h ago"},
+		{now.Add(-50 * time.Hour), "2d ago"},
+		{now.Add(2 * time.Hour), "in 2h"},
+	}
+	for _, tt := range tests {
+		if got := relativeTime(tt.t, now); got != tt.want {
+			t.Errorf("relativeTime(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package format
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timePresets maps the named --time-format presets to Go layouts.
+var timePresets = map[string]string{
+	"rfc3339":  time.RFC3339,
+	"kitchen":  time.Kitchen,
+	"datetime": time.DateTime,
+}
+
+// TimeFormatter renders timestamps with a configurable layout and time zone.
+// The zero value formats timestamps as RFC3339 in their original zone.
+type TimeFormatter struct {
+	Layout   string         // Go layout string; empty means RFC3339
+	Unix     bool           // render as Unix seconds instead of using Layout
+	Location *time.Location // zone to convert to; nil keeps the original zone
+}
+
+// NewTimeFormatter builds a TimeFormatter from a preset name or Go layout
+// string. local and utc select the zone timestamps are converted to.
+func NewTimeFormatter(spec string, local, utc bool) (TimeFormatter, error) {
+	if local && utc {
+		return TimeFormatter{}, errors.New("--local and --utc cannot be used together")
+	}
+
+	var f TimeFormatter
+	switch {
+	case local:
+		f.Location = time.Local
+	case utc:
+		f.Location = time.UTC
+	}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return f, nil
+	}
+	if strings.EqualFold(spec, "unix") {
+		f.Unix = true
+		return f, nil
+	}
+	if layout, ok := timePresets[strings.ToLower(spec)]; ok {
+		f.Layout = layout
+		return f, nil
+	}
+	f.Layout = spec
+	return f, nil
+}
+
+// LoadTimeZone returns the zone named by an IANA name such as
+// America/New_York, or the local zone for "local" in any case.
+func LoadTimeZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	if name == "" {
+		return nil, errors.New("time zone name is empty")
+	}
+	return time.LoadLocation(name)
+}
+
+// Format renders t according to the formatter settings.
+func (f TimeFormatter) Format(t time.Time) string {
+	if f.Unix {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	if f.Location != nil {
+		t = t.In(f.Location)
+	}
+	layout := f.Layout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return t.Format(layout)
+}
+
+// WithDefaultLayout returns a copy of f that uses layout when no explicit
+// layout or preset was chosen.
+func (f TimeFormatter) WithDefaultLayout(layout string) TimeFormatter {
+	if f.Layout == "" && !f.Unix {
+		f.Layout = layout
+	}
+	return f
+}
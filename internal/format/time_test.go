@@ -0,0 +1,104 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeFormatterPresets(t *testing.T) {
+	ts := time.Date(2025, 10, 1, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{spec: "", want: "2025-10-01T15:04:05Z"},
+		{spec: "rfc3339", want: "2025-10-01T15:04:05Z"},
+		{spec: "kitchen", want: "3:04PM"},
+		{spec: "datetime", want: "2025-10-01 15:04:05"},
+		{spec: "unix", want: "1759331045"},
+		{spec: "2006/01/02", want: "2025/10/01"},
+	}
+
+	for _, tt := range tests {
+		f, err := NewTimeFormatter(tt.spec, false, false)
+		if err != nil {
+			t.Fatalf("NewTimeFormatter(%q) returned error: %v", tt.spec, err)
+		}
+		if got := f.Format(ts); got != tt.want {
+			t.Fatalf("NewTimeFormatter(%q).Format = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestTimeFormatterLocal(t *testing.T) {
+	prev := time.Local
+	time.Local = time.FixedZone("TST", 9*60*60)
+	t.Cleanup(func() { time.Local = prev })
+
+	f, err := NewTimeFormatter("datetime", true, false)
+	if err != nil {
+		t.Fatalf("NewTimeFormatter returned error: %v", err)
+	}
+
+	ts := time.Date(2025, 10, 1, 20, 0, 0, 0, time.UTC)
+	if got := f.Format(ts); got != "2025-10-02 05:00:00" {
+		t.Fatalf("expected timestamp converted to local zone, got %q", got)
+	}
+}
+
+func TestTimeFormatterUTC(t *testing.T) {
+	f, err := NewTimeFormatter("", false, true)
+	if err != nil {
+		t.Fatalf("NewTimeFormatter returned error: %v", err)
+	}
+
+	ts := time.Date(2025, 10, 2, 5, 0, 0, 0, time.FixedZone("TST", 9*60*60))
+	if got := f.Format(ts); got != "2025-10-01T20:00:00Z" {
+		t.Fatalf("expected timestamp converted to UTC, got %q", got)
+	}
+}
+
+func TestTimeFormatterConflictingZones(t *testing.T) {
+	if _, err := NewTimeFormatter("", true, true); err == nil {
+		t.Fatal("expected error when both --local and --utc are set")
+	}
+}
+
+func TestLoadTimeZone(t *testing.T) {
+	loc, err := LoadTimeZone("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadTimeZone returned error: %v", err)
+	}
+	f := TimeFormatter{Location: loc}
+	ts := time.Date(2025, 10, 1, 20, 0, 0, 0, time.UTC)
+	if got := f.Format(ts); got != "2025-10-02T05:00:00+09:00" {
+		t.Fatalf("expected timestamp converted to Asia/Tokyo, got %q", got)
+	}
+
+	if loc, err := LoadTimeZone("Local"); err != nil || loc != time.Local {
+		t.Fatalf("expected the local zone, got %v, %v", loc, err)
+	}
+	for _, name := range []string{"", "Mars/Olympus_Mons"} {
+		if _, err := LoadTimeZone(name); err == nil {
+			t.Fatalf("expected LoadTimeZone(%q) to fail", name)
+		}
+	}
+}
+
+func TestWriteSummariesPlainTimeFormat(t *testing.T) {
+	f, err := NewTimeFormatter("datetime", false, false)
+	if err != nil {
+		t.Fatalf("NewTimeFormatter returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSummaries(&buf, sampleSummaries(), SummaryOptions{Format: "plain", Time: f}); err != nil {
+		t.Fatalf("WriteSummaries returned error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "2025-10-01 12:00:00\tsession-a") {
+		t.Fatalf("expected datetime formatted timestamp, got %q", buf.String())
+	}
+}
@@ -7,11 +7,59 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
 )
 
+// WrapMode selects how long lines are broken when a wrap width is set.
+type WrapMode string
+
+const (
+	// WrapWord breaks on whitespace; a single word wider than the limit is
+	// left intact.
+	WrapWord WrapMode = "word"
+	// WrapChar breaks on whitespace and hard-breaks words wider than the limit.
+	WrapChar WrapMode = "char"
+	// WrapNone never wraps.
+	WrapNone WrapMode = "none"
+)
+
+// ParseWrapMode validates a --wrap-mode value. An empty value means WrapWord.
+func ParseWrapMode(value string) (WrapMode, error) {
+	switch mode := WrapMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return WrapWord, nil
+	case WrapWord, WrapChar, WrapNone:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid wrap mode: %s (expected word, char, or none)", value)
+	}
+}
+
+// RenderOptions controls how event bodies are rendered.
+type RenderOptions struct {
+	Width          int      // wrap width in columns; 0 disables wrapping
+	WrapMode       WrapMode // empty means WrapWord
+	MaxArgBytes    int      // truncate function arguments beyond this size; 0 means no limit
+	MaxOutputBytes int      // truncate function outputs beyond this size; 0 means no limit
+	KeepControl    bool     // keep control characters instead of stripping them
+}
+
 // RenderEventLines returns the formatted body lines for a session event.
-func RenderEventLines(event model.EventProvider, wrapWidth int) []string {
-	body := renderBlocks(event.GetContent(), wrapWidth)
+func RenderEventLines(event model.EventProvider, opts RenderOptions) []string {
+	body := renderBlocks(event.GetContent(), opts)
+	if body == "" {
+		return nil
+	}
+	return strings.Split(body, "\n")
+}
+
+// RenderTextLines formats a plain text body, such as a session's
+// instructions, the way RenderEventLines formats a text block.
+func RenderTextLines(text string, opts RenderOptions) []string {
+	body := renderBlocks([]model.ContentBlock{{Type: "text", Text: text}}, opts)
 	if body == "" {
 		return nil
 	}
@@ -20,7 +68,7 @@ func RenderEventLines(event model.EventProvider, wrapWidth int) []string {
 
 // RenderEvent converts a session event into a printable string (legacy helper).
 func RenderEvent(event model.EventProvider, wrapWidth int) string {
-	lines := RenderEventLines(event, wrapWidth)
+	lines := RenderEventLines(event, RenderOptions{Width: wrapWidth})
 	label := event.GetRole()
 	if label == "" {
 		label = "event"
@@ -34,47 +82,74 @@ func RenderEvent(event model.EventProvider, wrapWidth int) string {
 }
 
 // renderBlocks joins content blocks into a printable string with optional wrapping.
-func renderBlocks(blocks []model.ContentBlock, wrapWidth int) string {
+func renderBlocks(blocks []model.ContentBlock, opts RenderOptions) string {
 	if len(blocks) == 0 {
 		return ""
 	}
 	parts := make([]string, 0, len(blocks))
 	for _, block := range blocks {
+		block.Text = sanitizeText(block.Text, opts.KeepControl)
 		switch block.Type {
 		case "input_text", "output_text", "text", "summary_text":
-			parts = append(parts, wrapBody(strings.TrimSpace(block.Text), wrapWidth))
+			parts = append(parts, wrapBody(strings.TrimSpace(block.Text), opts.Width, opts.WrapMode))
+		case "reasoning":
+			parts = append(parts, "💭 Reasoning: "+wrapBody(strings.TrimSpace(block.Text), opts.Width, opts.WrapMode))
 		case "json":
 			parts = append(parts, formatJSON(block.Text))
+		case "command":
+			parts = append(parts, "$ "+block.Text)
 		case "function_name":
 			parts = append(parts, fmt.Sprintf("Function: %s", block.Text))
+		case "custom_tool_name":
+			parts = append(parts, fmt.Sprintf("Custom Tool: %s", block.Text))
+		case "image":
+			parts = append(parts, fmt.Sprintf("[image: %s]", block.Text))
+		case "attachment":
+			parts = append(parts, fmt.Sprintf("[attachment: %s]", block.Text))
 		case "function_arguments":
 			// Try to format arguments as JSON if possible
 			formatted := formatJSON(block.Text)
 			if formatted == block.Text {
 				// Not valid JSON, show as-is
-				parts = append(parts, fmt.Sprintf("Arguments: %s", block.Text))
+				parts = append(parts, fmt.Sprintf("Arguments: %s", TruncateBytes(block.Text, opts.MaxArgBytes)))
 			} else {
-				parts = append(parts, fmt.Sprintf("Arguments:\n%s", formatted))
+				parts = append(parts, fmt.Sprintf("Arguments:\n%s", TruncateBytes(formatted, opts.MaxArgBytes)))
 			}
 		case "function_output":
 			// Try to format output as JSON if possible
 			formatted := formatJSON(block.Text)
 			if formatted == block.Text {
 				// Not valid JSON, show as-is
-				parts = append(parts, fmt.Sprintf("Output: %s", block.Text))
+				parts = append(parts, fmt.Sprintf("Output: %s", TruncateBytes(block.Text, opts.MaxOutputBytes)))
 			} else {
-				parts = append(parts, fmt.Sprintf("Output:\n%s", formatted))
+				parts = append(parts, fmt.Sprintf("Output:\n%s", TruncateBytes(formatted, opts.MaxOutputBytes)))
 			}
 		default:
 			prefix := fmt.Sprintf("[%s] ", block.Type)
-			parts = append(parts, prefix+wrapBody(strings.TrimSpace(block.Text), wrapWidth))
+			parts = append(parts, prefix+wrapBody(strings.TrimSpace(block.Text), opts.Width, opts.WrapMode))
 		}
 	}
 	return strings.Join(parts, "\n")
 }
 
-func wrapBody(text string, width int) string {
-	if width <= 0 || len(text) <= width {
+// sanitizeText replaces invalid UTF-8 with U+FFFD and, unless keepControl
+// is set, drops control characters other than tab and newline, so binary
+// tool output cannot inject escape sequences into the terminal.
+func sanitizeText(text string, keepControl bool) string {
+	text = strings.ToValidUTF8(text, string(utf8.RuneError))
+	if keepControl {
+		return text
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, text)
+}
+
+func wrapBody(text string, width int, mode WrapMode) string {
+	if width <= 0 || mode == WrapNone || len(text) <= width {
 		return text
 	}
 
@@ -82,6 +157,9 @@ func wrapBody(text string, width int) string {
 	if len(words) == 0 {
 		return ""
 	}
+	if mode == WrapChar {
+		words = splitLongWords(words, width)
+	}
 
 	var lines []string
 	current := words[0]
@@ -98,6 +176,45 @@ func wrapBody(text string, width int) string {
 	return strings.Join(lines, "\n")
 }
 
+// splitLongWords hard-breaks every word wider than width into width-sized
+// chunks.
+func splitLongWords(words []string, width int) []string {
+	out := make([]string, 0, len(words))
+	for _, word := range words {
+		for runewidth.StringWidth(word) > width {
+			head := runewidth.Truncate(word, width, "")
+			if head == "" {
+				// A single rune is wider than width; emit it on its own.
+				_, size := utf8.DecodeRuneInString(word)
+				head = word[:size]
+			}
+			out = append(out, head)
+			word = word[len(head):]
+		}
+		if word != "" {
+			out = append(out, word)
+		}
+	}
+	return out
+}
+
+// InstructionsPreviewBytes is how much of a session's instructions is shown
+// unless the full text is asked for.
+const InstructionsPreviewBytes = 1000
+
+// TruncateBytes cuts text to at most limit bytes, backing off to a rune
+// boundary, and notes how many bytes were dropped. A limit of 0 keeps text.
+func TruncateBytes(text string, limit int) string {
+	if limit <= 0 || len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s… (+%d bytes truncated)", text[:cut], len(text)-cut)
+}
+
 func contentValue(blocks []model.ContentBlock, expected string) string {
 	for _, block := range blocks {
 		if block.Type == expected {
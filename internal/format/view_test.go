@@ -17,7 +17,7 @@ func TestRenderEventLines_Text(t *testing.T) {
 		},
 	}
 
-	lines := RenderEventLines(event, 10)
+	lines := RenderEventLines(event, RenderOptions{Width: 10})
 	if len(lines) < 2 {
 		t.Fatalf("expected wrapped lines, got %v", lines)
 	}
@@ -35,7 +35,7 @@ func TestRenderEventLines_JSON(t *testing.T) {
 		},
 	}
 
-	lines := RenderEventLines(event, 80)
+	lines := RenderEventLines(event, RenderOptions{Width: 80})
 	if len(lines) < 2 {
 		t.Fatalf("expected pretty-printed JSON lines, got %v", lines)
 	}
@@ -46,3 +46,158 @@ func TestRenderEventLines_JSON(t *testing.T) {
 		t.Fatalf("json indentation missing: %v", lines[1])
 	}
 }
+
+func TestRenderEventLines_Reasoning(t *testing.T) {
+	event := &codex.CodexEvent{
+		Kind:        codex.EntryTypeResponseItem,
+		PayloadType: string(codex.ResponseItemTypeReasoning),
+		Content: []model.ContentBlock{
+			{Type: "reasoning", Text: codex.EncryptedReasoningPlaceholder},
+		},
+	}
+
+	lines := RenderEventLines(event, RenderOptions{})
+	if len(lines) != 1 {
+		t.Fatalf("expected a single line, got %v", lines)
+	}
+	if lines[0] != "💭 Reasoning: (encrypted reasoning)" {
+		t.Fatalf("unexpected reasoning line: %q", lines[0])
+	}
+}
+
+func TestRenderEventLines_WrapModes(t *testing.T) {
+	token := strings.Repeat("a1b2c3d4", 8) // 64 columns, no spaces
+	event := &codex.CodexEvent{
+		Kind: codex.EntryTypeResponseItem,
+		Role: codex.PayloadRoleAssistant,
+		Content: []model.ContentBlock{
+			{Type: "text", Text: "see https://example.com/" + token + " for details"},
+		},
+	}
+	const width = 20
+
+	word := RenderEventLines(event, RenderOptions{Width: width, WrapMode: WrapWord})
+	if len(word) != 3 || word[1] != "https://example.com/"+token {
+		t.Fatalf("word mode should keep the long token intact, got %q", word)
+	}
+
+	char := RenderEventLines(event, RenderOptions{Width: width, WrapMode: WrapChar})
+	for _, line := range char {
+		if len(line) > width {
+			t.Fatalf("char mode line exceeds %d columns: %q", width, line)
+		}
+	}
+	if joined := strings.Join(char, ""); !strings.Contains(joined, token) {
+		t.Fatalf("char mode lost part of the token: %q", char)
+	}
+
+	none := RenderEventLines(event, RenderOptions{Width: width, WrapMode: WrapNone})
+	if len(none) != 1 {
+		t.Fatalf("none mode should not wrap, got %q", none)
+	}
+}
+
+func TestParseWrapMode(t *testing.T) {
+	for input, want := range map[string]WrapMode{"": WrapWord, "word": WrapWord, "CHAR": WrapChar, "none": WrapNone} {
+		got, err := ParseWrapMode(input)
+		if err != nil || got != want {
+			t.Fatalf("ParseWrapMode(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseWrapMode("line"); err == nil {
+		t.Fatalf("expected error for unknown wrap mode")
+	}
+}
+
+func TestRenderEventLines_TruncatesFunctionBlocks(t *testing.T) {
+	content := strings.Repeat("x", 5000)
+	event := &codex.CodexEvent{
+		Kind:        codex.EntryTypeResponseItem,
+		PayloadType: "function_call",
+		Content: []model.ContentBlock{
+			{Type: "function_name", Text: "write_file"},
+			{Type: "function_arguments", Text: `{"path":"a.txt","content":"` + content + `"}`},
+			{Type: "function_output", Text: content},
+		},
+	}
+
+	body := strings.Join(RenderEventLines(event, RenderOptions{MaxArgBytes: 100, MaxOutputBytes: 50}), "\n")
+	if strings.Contains(body, content) {
+		t.Fatalf("expected large blocks to be truncated")
+	}
+	if !strings.Contains(body, "bytes truncated)") {
+		t.Fatalf("missing truncation marker: %q", body)
+	}
+	if !strings.Contains(body, "Output: "+strings.Repeat("x", 50)+"… (+4950 bytes truncated)") {
+		t.Fatalf("unexpected output truncation: %q", body)
+	}
+
+	full := strings.Join(RenderEventLines(event, RenderOptions{}), "\n")
+	if !strings.Contains(full, content) || strings.Contains(full, "truncated") {
+		t.Fatalf("expected no truncation without limits")
+	}
+}
+
+func TestTruncateBytesRuneBoundary(t *testing.T) {
+	got := TruncateBytes("ab日本", 4)
+	if got != "ab… (+6 bytes truncated)" {
+		t.Fatalf("TruncateBytes = %q", got)
+	}
+}
+
+func TestRenderEventLines_CustomToolCall(t *testing.T) {
+	event := &codex.CodexEvent{
+		Kind:        codex.EntryTypeResponseItem,
+		PayloadType: "custom_tool_call",
+		Content: []model.ContentBlock{
+			{Type: "custom_tool_name", Text: "apply_patch"},
+			{Type: "function_arguments", Text: "*** Begin Patch"},
+		},
+	}
+
+	lines := RenderEventLines(event, RenderOptions{})
+	want := []string{"Custom Tool: apply_patch", "Arguments: *** Begin Patch"}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected custom tool rendering: %q", lines)
+	}
+}
+
+func TestRenderEventLines_ImagePlaceholders(t *testing.T) {
+	event := &codex.CodexEvent{
+		Kind: codex.EntryTypeResponseItem,
+		Role: codex.PayloadRoleUser,
+		Content: []model.ContentBlock{
+			{Type: "input_text", Text: "Look at this"},
+			{Type: "image", Text: "png, 3KB"},
+			{Type: "attachment", Text: "pdf, 9B"},
+		},
+	}
+	got := strings.Join(RenderEventLines(event, RenderOptions{}), "\n")
+	want := "Look at this\n[image: png, 3KB]\n[attachment: pdf, 9B]"
+	if got != want {
+		t.Fatalf("unexpected render:\n%s", got)
+	}
+}
+
+func TestRenderEventLines_SanitizesControlBytes(t *testing.T) {
+	event := &codex.CodexEvent{
+		Kind:        codex.EntryTypeResponseItem,
+		PayloadType: "function_call_output",
+		Content: []model.ContentBlock{
+			{Type: "function_output", Text: "ok\x1b[2J\x1b]0;owned\x07\r\tdone\x00\xff\xfe\nnext\u009b31m"},
+		},
+	}
+
+	body := strings.Join(RenderEventLines(event, RenderOptions{}), "\n")
+	if want := "Output: ok[2J]0;owned\tdone�\nnext31m"; body != want {
+		t.Fatalf("unexpected sanitized output:\n%q\nwant:\n%q", body, want)
+	}
+
+	kept := strings.Join(RenderEventLines(event, RenderOptions{KeepControl: true}), "\n")
+	if !strings.Contains(kept, "\x1b[2J") || !strings.Contains(kept, "\x00") {
+		t.Fatalf("expected KeepControl to keep control characters: %q", kept)
+	}
+	if strings.Contains(kept, "\xff") {
+		t.Fatalf("expected invalid UTF-8 to be replaced even with KeepControl: %q", kept)
+	}
+}
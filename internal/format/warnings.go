@@ -0,0 +1,51 @@
+package format
+
+import (
+	"agentlog/internal/store"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// warningRecord is the JSON form of a listing warning.
+type warningRecord struct {
+	Kind    store.WarningKind `json:"kind,omitempty"`
+	Path    string            `json:"path,omitempty"`
+	Message string            `json:"message"`
+}
+
+// WriteWarnings writes listing warnings to w. The text format prints one
+// "warning: ..." line each; json prints one JSON object per line.
+func WriteWarnings(w io.Writer, warnings []error, format string) error {
+	format = strings.ToLower(format)
+	switch format {
+	case "", "text":
+		for _, warn := range warnings {
+			if _, err := fmt.Fprintf(w, "warning: %v\n", warn); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		for _, warn := range warnings {
+			if err := enc.Encode(newWarningRecord(warn)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported warnings format: %s", format)
+	}
+}
+
+func newWarningRecord(err error) warningRecord {
+	var warn *store.Warning
+	if errors.As(err, &warn) {
+		return warningRecord{Kind: warn.Kind, Path: warn.Path, Message: warn.Err.Error()}
+	}
+	return warningRecord{Message: err.Error()}
+}
@@ -0,0 +1,45 @@
+package format
+
+import (
+	"agentlog/internal/store"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWriteWarnings(t *testing.T) {
+	warnings := []error{
+		&store.Warning{Kind: store.WarningMeta, Path: "/tmp/a.jsonl", Err: errors.New("session_meta record not found")},
+		errors.New("untyped"),
+	}
+
+	var text bytes.Buffer
+	if err := WriteWarnings(&text, warnings, "text"); err != nil {
+		t.Fatalf("WriteWarnings text returned error: %v", err)
+	}
+	want := "warning: parse meta /tmp/a.jsonl: session_meta record not found\nwarning: untyped\n"
+	if text.String() != want {
+		t.Fatalf("text warnings mismatch:\nexpected: %q\nactual:   %q", want, text.String())
+	}
+
+	var out bytes.Buffer
+	if err := WriteWarnings(&out, warnings, "json"); err != nil {
+		t.Fatalf("WriteWarnings json returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one JSON object per warning, got %q", out.String())
+	}
+	var record map[string]string
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("invalid JSON %q: %v", lines[0], err)
+	}
+	if record["kind"] != "parse_meta" || record["path"] != "/tmp/a.jsonl" || record["message"] != "session_meta record not found" {
+		t.Fatalf("unexpected warning record: %v", record)
+	}
+	if lines[1] != `{"message":"untyped"}` {
+		t.Fatalf("unexpected untyped record: %s", lines[1])
+	}
+}
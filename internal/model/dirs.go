@@ -0,0 +1,58 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultSessionsDir returns the directory searched for sessions of agent
+// when none is given. It tries, in order:
+//
+//  1. AGENTLOG_SESSIONS_DIR
+//  2. the agent's own override: $CODEX_HOME/sessions or $CLAUDE_CONFIG_DIR/projects
+//  3. the XDG location, if it exists: $XDG_DATA_HOME/codex/sessions or
+//     $XDG_CONFIG_HOME/claude/projects
+//  4. ~/.codex/sessions or ~/.claude/projects
+func DefaultSessionsDir(agent AgentType) string {
+	if dir := os.Getenv("AGENTLOG_SESSIONS_DIR"); dir != "" {
+		return dir
+	}
+
+	home, _ := os.UserHomeDir()
+	switch agent {
+	case AgentCodex:
+		if dir := os.Getenv("CODEX_HOME"); dir != "" {
+			return filepath.Join(dir, "sessions")
+		}
+		if dir := xdgDir("XDG_DATA_HOME", home, ".local/share", "codex", "sessions"); dir != "" {
+			return dir
+		}
+		return filepath.Join(home, ".codex", "sessions")
+	default:
+		if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+			return filepath.Join(dir, "projects")
+		}
+		if dir := xdgDir("XDG_CONFIG_HOME", home, ".config", "claude", "projects"); dir != "" {
+			return dir
+		}
+		return filepath.Join(home, ".claude", "projects")
+	}
+}
+
+// xdgDir joins parts onto the XDG base directory named by env, falling back
+// to fallback under home, and returns the result only if it is an existing
+// directory.
+func xdgDir(env, home, fallback string, parts ...string) string {
+	base := os.Getenv(env)
+	if base == "" {
+		if home == "" {
+			return ""
+		}
+		base = filepath.Join(home, fallback)
+	}
+	dir := filepath.Join(append([]string{base}, parts...)...)
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return dir
+	}
+	return ""
+}
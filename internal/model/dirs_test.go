@@ -0,0 +1,75 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultSessionsDir(t *testing.T) {
+	home := t.TempDir()
+	xdgData := t.TempDir()
+	xdgConfig := t.TempDir()
+
+	reset := func(t *testing.T) {
+		t.Helper()
+		t.Setenv("HOME", home)
+		for _, env := range []string{"AGENTLOG_SESSIONS_DIR", "CODEX_HOME", "CLAUDE_CONFIG_DIR", "XDG_DATA_HOME", "XDG_CONFIG_HOME"} {
+			t.Setenv(env, "")
+		}
+	}
+
+	t.Run("home defaults", func(t *testing.T) {
+		reset(t)
+		if got, want := DefaultSessionsDir(AgentCodex), filepath.Join(home, ".codex", "sessions"); got != want {
+			t.Fatalf("codex: got %s, want %s", got, want)
+		}
+		if got, want := DefaultSessionsDir(AgentClaude), filepath.Join(home, ".claude", "projects"); got != want {
+			t.Fatalf("claude: got %s, want %s", got, want)
+		}
+	})
+
+	t.Run("agent overrides", func(t *testing.T) {
+		reset(t)
+		t.Setenv("CODEX_HOME", "/opt/codex")
+		t.Setenv("CLAUDE_CONFIG_DIR", "/opt/claude")
+		if got := DefaultSessionsDir(AgentCodex); got != filepath.Join("/opt/codex", "sessions") {
+			t.Fatalf("codex: got %s", got)
+		}
+		if got := DefaultSessionsDir(AgentClaude); got != filepath.Join("/opt/claude", "projects") {
+			t.Fatalf("claude: got %s", got)
+		}
+	})
+
+	t.Run("xdg dirs only when present", func(t *testing.T) {
+		reset(t)
+		t.Setenv("XDG_DATA_HOME", xdgData)
+		t.Setenv("XDG_CONFIG_HOME", xdgConfig)
+		if got := DefaultSessionsDir(AgentCodex); got != filepath.Join(home, ".codex", "sessions") {
+			t.Fatalf("codex without xdg dir: got %s", got)
+		}
+
+		codexDir := filepath.Join(xdgData, "codex", "sessions")
+		claudeDir := filepath.Join(xdgConfig, "claude", "projects")
+		for _, dir := range []string{codexDir, claudeDir} {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := DefaultSessionsDir(AgentCodex); got != codexDir {
+			t.Fatalf("codex: got %s, want %s", got, codexDir)
+		}
+		if got := DefaultSessionsDir(AgentClaude); got != claudeDir {
+			t.Fatalf("claude: got %s, want %s", got, claudeDir)
+		}
+	})
+
+	t.Run("agentlog override wins", func(t *testing.T) {
+		reset(t)
+		t.Setenv("CODEX_HOME", "/opt/codex")
+		t.Setenv("AGENTLOG_SESSIONS_DIR", "/srv/sessions")
+		if got := DefaultSessionsDir(AgentCodex); got != "/srv/sessions" {
+			t.Fatalf("got %s, want /srv/sessions", got)
+		}
+	})
+}
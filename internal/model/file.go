@@ -0,0 +1,56 @@
+package model
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// gzipMagic is the two-byte header that starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// IsSessionFile reports whether name looks like a session log: a .jsonl file
+// or a gzip-compressed .jsonl.gz archive.
+func IsSessionFile(name string) bool {
+	return strings.HasSuffix(name, ".jsonl") || strings.HasSuffix(name, ".jsonl.gz")
+}
+
+// OpenSessionFile opens a session log for reading. Gzip-compressed files are
+// detected by their magic bytes, whatever their extension, and decompressed
+// transparently. An uncompressed log is returned as its *os.File, so callers
+// that need random access can tell the two apart by the reader's type.
+func OpenSessionFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, len(gzipMagic))
+	if n, _ := file.ReadAt(header, 0); n < len(header) || string(header) != string(gzipMagic) {
+		// Short files cannot be gzip streams; read them as they are.
+		return file, nil
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		file.Close() //nolint:errcheck
+		return nil, fmt.Errorf("read gzip header: %w", err)
+	}
+	return &sessionFile{Reader: gz, file: file, gz: gz}, nil
+}
+
+// sessionFile closes the decompressor along with the file.
+type sessionFile struct {
+	io.Reader
+	file *os.File
+	gz   *gzip.Reader
+}
+
+func (f *sessionFile) Close() error {
+	if err := f.gz.Close(); err != nil {
+		f.file.Close() //nolint:errcheck
+		return err
+	}
+	return f.file.Close()
+}
@@ -33,4 +33,72 @@ type EventProvider interface {
 	GetRole() string // Normalized role: "user", "assistant", "tool", "system"
 	GetContent() []ContentBlock
 	GetRaw() string // Raw JSON for debugging/export
+	// GetEntryType returns the normalized entry type: "session_meta",
+	// "response_item", "event_msg", or "turn_context".
+	GetEntryType() string
+	// GetPayloadType returns the normalized payload type within the entry
+	// type, e.g. "message", "function_call", or an event_msg type.
+	GetPayloadType() string
+}
+
+// EventMetadataProvider is implemented by events that carry agent-specific
+// details (request IDs, service tier, payload types) worth exporting.
+type EventMetadataProvider interface {
+	GetMetadata() map[string]string
+}
+
+// ModelProvider is implemented by events that record which model produced or
+// configured them. An empty string means the event carries no model.
+type ModelProvider interface {
+	GetModel() string
+}
+
+// EffortProvider is implemented by events that record the reasoning effort
+// configured for a turn. An empty string means the event carries no effort.
+type EffortProvider interface {
+	GetEffort() string
+}
+
+// TokenUsageProvider is implemented by events that report token usage. Both
+// counts are zero when the event carries no usage.
+type TokenUsageProvider interface {
+	GetTokenUsage() (input, output int)
+}
+
+// ToolCallProvider is implemented by events that identify the tool calls
+// they make, or the calls their tool output answers.
+type ToolCallProvider interface {
+	GetToolCallIDs() []string
+}
+
+// TurnAbortProvider is implemented by events that can record the user
+// aborting a turn.
+type TurnAbortProvider interface {
+	IsTurnAborted() bool
+}
+
+// InstructionsProvider is implemented by session metadata that records the
+// instructions, or system prompt, the session started with.
+type InstructionsProvider interface {
+	GetInstructions() string
+}
+
+// ContinuationProvider is implemented by session metadata that can tell
+// whether a session continues an earlier conversation that was compacted.
+// GetContinuedFrom returns the summaries of the compacted conversation,
+// oldest first, or nil for a session that starts afresh.
+type ContinuationProvider interface {
+	GetContinuedFrom() []string
+}
+
+// InterruptedProvider is implemented by session summaries that know whether
+// the session ended in the middle of a turn.
+type InterruptedProvider interface {
+	IsInterrupted() bool
+}
+
+// ParentSessionProvider is implemented by session metadata that can link a
+// resumed session to the session it continues.
+type ParentSessionProvider interface {
+	GetParentSessionID() string
 }
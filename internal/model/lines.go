@@ -0,0 +1,74 @@
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// maxLineBytesEnv names the environment variable that caps the length of a
+// session line. Unset or 0 means no limit.
+const maxLineBytesEnv = "AGENTLOG_MAX_LINE_BYTES"
+
+// LineScanner reads a session log line by line. Unlike bufio.Scanner it has
+// no maximum line length, so records embedding large file contents still
+// parse; set AGENTLOG_MAX_LINE_BYTES to reject lines above a size instead.
+// Trailing "\r\n" and "\n" are stripped, as with bufio.ScanLines.
+type LineScanner struct {
+	r       *bufio.Reader
+	line    []byte
+	lineNo  int
+	maxLine int
+	err     error
+}
+
+// NewLineScanner returns a LineScanner reading from r.
+func NewLineScanner(r io.Reader) *LineScanner {
+	return &LineScanner{r: bufio.NewReaderSize(r, 64*1024), maxLine: maxLineBytes()}
+}
+
+// Scan advances to the next line, returning false at the end of the input
+// or on an error.
+func (s *LineScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	s.line = s.line[:0]
+	s.lineNo++
+	for {
+		chunk, isPrefix, err := s.r.ReadLine()
+		if err != nil {
+			if err != io.EOF {
+				s.err = err
+			}
+			return false
+		}
+		s.line = append(s.line, chunk...)
+		if s.maxLine > 0 && len(s.line) > s.maxLine {
+			s.err = fmt.Errorf("line %d exceeds %s (%d bytes)", s.lineNo, maxLineBytesEnv, s.maxLine)
+			return false
+		}
+		if !isPrefix {
+			return true
+		}
+	}
+}
+
+// Bytes returns the current line. The slice is only valid until the next
+// call to Scan.
+func (s *LineScanner) Bytes() []byte { return s.line }
+
+// Err returns the first error other than io.EOF encountered by Scan.
+func (s *LineScanner) Err() error { return s.err }
+
+// maxLineBytes reads the line length cap from the environment, ignoring
+// values that are not positive integers.
+func maxLineBytes() int {
+	n, err := strconv.Atoi(os.Getenv(maxLineBytesEnv))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineScanner(t *testing.T) {
+	long := strings.Repeat("x", 200*1024)
+	scanner := NewLineScanner(strings.NewReader("first\r\n" + long + "\n\nlast"))
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, string(scanner.Bytes()))
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if len(lines) != 4 || lines[0] != "first" || lines[1] != long || lines[2] != "" || lines[3] != "last" {
+		t.Fatalf("unexpected lines: %d lines, first %q, last %q", len(lines), lines[0], lines[len(lines)-1])
+	}
+}
+
+func TestLineScannerMaxLineBytes(t *testing.T) {
+	t.Setenv("AGENTLOG_MAX_LINE_BYTES", "10")
+	scanner := NewLineScanner(strings.NewReader("short\n" + strings.Repeat("x", 11) + "\n"))
+
+	if !scanner.Scan() || string(scanner.Bytes()) != "short" {
+		t.Fatalf("expected the short line first")
+	}
+	if scanner.Scan() {
+		t.Fatalf("expected the long line to be rejected, got %d bytes", len(scanner.Bytes()))
+	}
+	if err := scanner.Err(); err == nil || !strings.Contains(err.Error(), "line 2 exceeds AGENTLOG_MAX_LINE_BYTES") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
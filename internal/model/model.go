@@ -1,8 +1,48 @@
 package model
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
 // ContentBlock models a portion of a response payload.
 // This is agent-agnostic and used by all implementations.
 type ContentBlock struct {
 	Type string
 	Text string
+	// ToolName and ToolInput describe a tool call: the tool and its input
+	// as JSON, kept structured for JSON output. Text still holds the
+	// readable rendering.
+	ToolName  string
+	ToolInput json.RawMessage
+}
+
+// DescribeAttachment returns the placeholder text for an image or file
+// block, such as "png, 45KB". mediaType is a MIME type; data is the base64
+// payload and may be empty when the content is referenced by URL.
+func DescribeAttachment(mediaType, data string) string {
+	kind := mediaType
+	if idx := strings.LastIndex(kind, "/"); idx >= 0 {
+		kind = kind[idx+1:]
+	}
+	if kind == "" {
+		kind = "unknown"
+	}
+	if data == "" {
+		return kind
+	}
+	return kind + ", " + formatByteSize(base64.RawStdEncoding.DecodedLen(len(strings.TrimRight(data, "="))))
+}
+
+func formatByteSize(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%dB", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%dKB", (n+512)/1024)
+	default:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1024*1024))
+	}
 }
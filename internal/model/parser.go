@@ -12,7 +12,26 @@ type Parser interface {
 	// This is used for displaying a brief description of the session.
 	FirstUserSummary(path string) (string, error)
 
+	// CountMessages returns the number of conversational messages in the log file.
+	// Only user and assistant messages are counted; summaries, metadata and
+	// other bookkeeping entries are excluded.
+	CountMessages(path string) (int, error)
+
 	// IterateEvents reads all events from the log file and calls the provided
 	// function for each event. The function should return an error to stop iteration.
 	IterateEvents(path string, fn func(EventProvider) error) error
 }
+
+// SummaryEntryReader is implemented by parsers whose logs can record a
+// summary of the session, such as Claude's summary entries.
+type SummaryEntryReader interface {
+	// SummaryEntry returns the text of the first summary entry, or "" when
+	// the log has none.
+	SummaryEntry(path string) (string, error)
+}
+
+// EventLineParser is implemented by parsers that can decode a single JSONL
+// record without reading the rest of the file.
+type EventLineParser interface {
+	ParseEventLine(line []byte) (EventProvider, error)
+}
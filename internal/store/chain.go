@@ -0,0 +1,141 @@
+package store
+
+import (
+	"agentlog/internal/model"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// chainNode is a session file seen while resolving a resume chain.
+type chainNode struct {
+	id        string
+	parent    string
+	path      string
+	startedAt time.Time
+}
+
+// ChainOptions controls which session files ResolveChain searches.
+type ChainOptions struct {
+	// Roots are the directories to search.
+	Roots []string
+	// FollowSymlinks and ExcludeDirs walk the roots as they do for
+	// ListSessions.
+	FollowSymlinks bool
+	ExcludeDirs    []string
+}
+
+// ResolveChain returns the paths of every session linked to id through
+// parent session IDs, oldest first. The chain is followed back to the session
+// that started it and forward through the sessions that resumed it; when a
+// session was resumed more than once, the earliest resume is followed.
+func ResolveChain(parser model.Parser, opts ChainOptions, id string) ([]string, error) {
+	roots := ListOptions{Roots: opts.Roots}.roots()
+	if len(roots) == 0 {
+		return nil, errors.New("root directory is required")
+	}
+	if id == "" {
+		return nil, errors.New("session id is required")
+	}
+
+	for _, root := range roots {
+		if err := checkRoot(root); err != nil {
+			return nil, err
+		}
+	}
+	for _, pattern := range opts.ExcludeDirs {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	nodes := make(map[string]chainNode)
+	children := make(map[string][]chainNode)
+	for _, root := range roots {
+		if err := collectChainNodes(parser, root, opts, nodes, children); err != nil {
+			return nil, err
+		}
+	}
+
+	start, ok := nodes[id]
+	if !ok {
+		return nil, fmt.Errorf("session id %s %w under %s", id, ErrSessionNotFound, strings.Join(roots, ", "))
+	}
+
+	seen := map[string]bool{start.id: true}
+	var ancestors []string
+	for node := start; node.parent != ""; {
+		parent, ok := nodes[node.parent]
+		if !ok || seen[parent.id] {
+			break
+		}
+		seen[parent.id] = true
+		ancestors = append(ancestors, parent.path)
+		node = parent
+	}
+
+	paths := make([]string, 0, len(ancestors)+1)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		paths = append(paths, ancestors[i])
+	}
+	paths = append(paths, start.path)
+
+	for node := start; ; {
+		next, ok := firstUnseen(children[node.id], seen)
+		if !ok {
+			break
+		}
+		seen[next.id] = true
+		paths = append(paths, next.path)
+		node = next
+	}
+	return paths, nil
+}
+
+// collectChainNodes records every session under root by ID, and every
+// session with a parent under that parent's ID.
+func collectChainNodes(parser model.Parser, root string, opts ChainOptions, nodes map[string]chainNode, children map[string][]chainNode) error {
+	return walkSessions(root, opts.FollowSymlinks, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return nil
+		}
+		if d.IsDir() && excludedDir(root, path, opts.ExcludeDirs) {
+			return fs.SkipDir
+		}
+		if d.IsDir() || !model.IsSessionFile(d.Name()) {
+			return nil
+		}
+		meta, err := parser.ReadSessionMeta(path)
+		if err != nil || meta.GetID() == "" {
+			return nil
+		}
+		node := chainNode{id: meta.GetID(), path: path, startedAt: meta.GetStartedAt()}
+		if provider, ok := meta.(model.ParentSessionProvider); ok {
+			node.parent = provider.GetParentSessionID()
+		}
+		if _, exists := nodes[node.id]; !exists {
+			nodes[node.id] = node
+		}
+		if node.parent != "" {
+			children[node.parent] = append(children[node.parent], node)
+		}
+		return nil
+	})
+}
+
+// firstUnseen returns the earliest started node not yet in seen.
+func firstUnseen(nodes []chainNode, seen map[string]bool) (chainNode, bool) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].startedAt.Before(nodes[j].startedAt)
+	})
+	for _, node := range nodes {
+		if !seen[node.id] {
+			return node, true
+		}
+	}
+	return chainNode{}, false
+}
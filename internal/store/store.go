@@ -3,9 +3,11 @@ package store
 
 import (
 	"agentlog/internal/model"
+	"agentlog/internal/util"
 	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -14,6 +16,18 @@ import (
 
 var errStop = errors.New("stop iteration")
 
+// ErrSessionNotFound is returned by FindSessionPath when no session file
+// under the roots has the requested id.
+var ErrSessionNotFound = errors.New("not found")
+
+// ErrAmbiguousSession is returned by FindSessionPath when the requested id
+// is a prefix of more than one session id.
+var ErrAmbiguousSession = errors.New("is ambiguous")
+
+// ErrSessionsDirNotFound is returned by ListSessions when a root does not
+// exist.
+var ErrSessionsDirNotFound = errors.New("sessions directory not found")
+
 // sessionSummary implements model.SessionSummaryProvider.
 type sessionSummary struct {
 	id              string
@@ -23,6 +37,9 @@ type sessionSummary struct {
 	summary         string
 	messageCount    int
 	durationSeconds int
+	models          []string
+	effort          string
+	interrupted     bool
 }
 
 func (s *sessionSummary) GetID() string              { return s.id }
@@ -33,8 +50,31 @@ func (s *sessionSummary) GetSummary() string         { return s.summary }
 func (s *sessionSummary) GetMessageCount() int       { return s.messageCount }
 func (s *sessionSummary) GetDurationSeconds() int    { return s.durationSeconds }
 
+// IsInterrupted reports whether the session ended in the middle of a turn.
+func (s *sessionSummary) IsInterrupted() bool { return s.interrupted }
+
+// GetModels returns the distinct models seen in the session, ordered so the
+// most recently used model is last.
+func (s *sessionSummary) GetModels() []string { return s.models }
+
+// GetEffort returns the most recently configured reasoning effort, or ""
+// when unknown.
+func (s *sessionSummary) GetEffort() string { return s.effort }
+
+// GetModel returns the most recently used model, or "" when unknown.
+func (s *sessionSummary) GetModel() string {
+	if len(s.models) == 0 {
+		return ""
+	}
+	return s.models[len(s.models)-1]
+}
+
 // ListOptions controls how sessions are enumerated.
 type ListOptions struct {
+	// Roots are the directories to scan. Sessions found under more than one
+	// root are listed once, from the first root that contains them.
+	Roots []string
+	// Root is shorthand for a single entry in Roots.
 	Root       string
 	CWD        string
 	ExactCWD   bool
@@ -42,36 +82,371 @@ type ListOptions struct {
 	Before     *time.Time
 	Limit      int
 	MaxSummary int
+	// SummarySource selects where each summary comes from: "" or "message"
+	// for the first user message, or "summary" for the session's summary
+	// entry, falling back to the first user message when the parser has no
+	// summary entries (see model.SummaryEntryReader) or the session none.
+	SummarySource string
+	// ModelFilter keeps only sessions where any model used contains this
+	// substring (case-insensitive).
+	ModelFilter string
+	// MinMessages and MaxMessages bound the message count; 0 means no bound.
+	MinMessages int
+	MaxMessages int
+	// MinDuration and MaxDuration bound the session duration; nil means no bound.
+	MinDuration *time.Duration
+	MaxDuration *time.Duration
+	// UniqueCWD keeps only the most recent session for each working directory.
+	UniqueCWD bool
+	// PageSize splits the sorted and limited sessions into pages, and Page
+	// selects one of them (1-based). PageSize 0 disables paging.
+	Page     int
+	PageSize int
+	// AfterID and BeforeID keep only the sessions sorted after or before the
+	// session with that id, for cursor-style paging that stays stable as new
+	// sessions appear. They are applied before Limit, and the anchor session
+	// must itself be listed.
+	AfterID  string
+	BeforeID string
+	// Duplicates keeps only sessions whose id is found in more than one file,
+	// every copy listed and the copies of each id kept together. Copies under
+	// different roots are not merged in this mode.
+	Duplicates bool
+	// FollowSymlinks descends into symlinked directories, such as a sessions
+	// directory linked in by a dotfile manager. Each directory is scanned once.
+	FollowSymlinks bool
+	// ExcludeDirs skips directories below a root whose name, or whose path
+	// relative to the root, matches any of these filepath.Match patterns.
+	ExcludeDirs []string
+	// Tag keeps only sessions that carry this tag in Tags, ignoring case.
+	Tag  string
+	Tags Tags
+	// Progress, when set, is called after each session file is scanned with
+	// the number scanned so far and the total counted by a walk done before
+	// scanning starts.
+	Progress func(done, total int)
 }
 
 // ListResult contains session summaries and non-fatal warnings.
 type ListResult struct {
 	Summaries []model.SessionSummaryProvider
-	Warnings  []error
+	Warnings  []error // each a *Warning
+	Total     int     // sessions matched after Limit, before paging
+}
+
+// WarningKind identifies the step that failed for a skipped file.
+type WarningKind string
+
+const (
+	WarningWalk    WarningKind = "walk"
+	WarningMeta    WarningKind = "parse_meta"
+	WarningSummary WarningKind = "extract_summary"
+	WarningCount   WarningKind = "count_messages"
+	WarningEvents  WarningKind = "scan_events"
+)
+
+// warningActions describes each kind in Error messages.
+var warningActions = map[WarningKind]string{
+	WarningWalk:    "walk",
+	WarningMeta:    "parse meta",
+	WarningSummary: "extract summary",
+	WarningCount:   "count messages",
+	WarningEvents:  "scan events",
+}
+
+// Warning reports a path that was skipped while listing sessions.
+type Warning struct {
+	Kind WarningKind
+	Path string
+	Err  error
+}
+
+func (w *Warning) Error() string {
+	return fmt.Sprintf("%s %s: %v", warningActions[w.Kind], w.Path, w.Err)
+}
+
+func (w *Warning) Unwrap() error { return w.Err }
+
+// roots returns Roots followed by Root, skipping empty entries.
+func (o ListOptions) roots() []string {
+	roots := make([]string, 0, len(o.Roots)+1)
+	for _, root := range o.Roots {
+		if root != "" {
+			roots = append(roots, root)
+		}
+	}
+	if o.Root != "" {
+		roots = append(roots, o.Root)
+	}
+	return roots
 }
 
-// ListSessions enumerates sessions under Root according to options using the provided parser.
+// ListSessions enumerates sessions under every root according to options
+// using the provided parser.
 func ListSessions(parser model.Parser, opts ListOptions) (ListResult, error) {
-	root := opts.Root
-	if root == "" {
+	roots := opts.roots()
+	if len(roots) == 0 {
 		return ListResult{}, errors.New("root directory is required")
 	}
+	for _, root := range roots {
+		if err := checkRoot(root); err != nil {
+			return ListResult{}, err
+		}
+	}
+	for _, pattern := range opts.ExcludeDirs {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return ListResult{}, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	done, total := 0, 0
+	if opts.Progress != nil {
+		total = countSessionFiles(roots, opts)
+	}
+	scanned := func() {
+		done++
+		if opts.Progress != nil {
+			opts.Progress(done, total)
+		}
+	}
 
 	var result ListResult
+	seen := make(map[string]struct{})
+	for _, root := range roots {
+		summaries, err := listRoot(parser, root, opts, &result.Warnings, scanned)
+		if err != nil {
+			return result, err
+		}
+		for _, summary := range summaries {
+			if _, dup := seen[summary.GetID()]; dup && !opts.Duplicates {
+				continue
+			}
+			result.Summaries = append(result.Summaries, summary)
+		}
+		for _, summary := range summaries {
+			seen[summary.GetID()] = struct{}{}
+		}
+	}
+
+	sort.SliceStable(result.Summaries, func(i, j int) bool {
+		return newerSession(result.Summaries[i], result.Summaries[j])
+	})
+
+	if opts.Duplicates {
+		result.Summaries = duplicateSessions(result.Summaries)
+	}
+	if opts.UniqueCWD {
+		result.Summaries = latestPerCWD(result.Summaries)
+	}
+	if opts.AfterID != "" || opts.BeforeID != "" {
+		summaries, err := betweenAnchors(result.Summaries, opts.AfterID, opts.BeforeID)
+		if err != nil {
+			return result, err
+		}
+		result.Summaries = summaries
+	}
+	if opts.Limit > 0 && len(result.Summaries) > opts.Limit {
+		result.Summaries = result.Summaries[:opts.Limit]
+	}
+	result.Total = len(result.Summaries)
+	if opts.PageSize > 0 {
+		result.Summaries = page(result.Summaries, opts.Page, opts.PageSize)
+	}
+
+	return result, nil
+}
+
+// sessionSummaryText returns the summary of the session at path, taken
+// from source as described by ListOptions.SummarySource.
+func sessionSummaryText(parser model.Parser, path, source string) (string, error) {
+	if reader, ok := parser.(model.SummaryEntryReader); ok && source == "summary" {
+		text, err := reader.SummaryEntry(path)
+		if err != nil || text != "" {
+			return text, err
+		}
+	}
+	return parser.FirstUserSummary(path)
+}
+
+// newerSession orders sessions newest first. Sessions that started at the
+// same instant are ordered by ID and then by path, so listings do not depend
+// on the order the files were walked in.
+func newerSession(a, b model.SessionSummaryProvider) bool {
+	if !a.GetStartedAt().Equal(b.GetStartedAt()) {
+		return a.GetStartedAt().After(b.GetStartedAt())
+	}
+	if a.GetID() != b.GetID() {
+		return a.GetID() < b.GetID()
+	}
+	return a.GetPath() < b.GetPath()
+}
+
+// checkRoot reports a root that is missing or cannot be read, so a mistyped
+// --sessions-dir fails instead of listing nothing. An existing empty
+// directory is fine.
+func checkRoot(root string) error {
+	_, err := os.ReadDir(root)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("%w: %s; is the agent installed?", ErrSessionsDirNotFound, root)
+	default:
+		return fmt.Errorf("read sessions directory: %w", err)
+	}
+}
+
+// excludedDir reports whether the directory at path, below root, matches one
+// of patterns by name or by its slash-separated path relative to root. The
+// root itself is never excluded.
+func excludedDir(root, path string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	name := filepath.Base(path)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// latestPerCWD keeps the first, and so the most recent, of the sorted
+// sessions for each working directory.
+func latestPerCWD(items []model.SessionSummaryProvider) []model.SessionSummaryProvider {
+	seen := make(map[string]struct{}, len(items))
+	kept := items[:0]
+	for _, item := range items {
+		if _, dup := seen[item.GetCWD()]; dup {
+			continue
+		}
+		seen[item.GetCWD()] = struct{}{}
+		kept = append(kept, item)
+	}
+	return kept
+}
+
+// duplicateSessions keeps the sorted sessions whose id appears more than
+// once, moving each later copy up behind the first so the copies of an id
+// are listed together.
+func duplicateSessions(items []model.SessionSummaryProvider) []model.SessionSummaryProvider {
+	copies := make(map[string][]model.SessionSummaryProvider, len(items))
+	var order []string
+	for _, item := range items {
+		id := item.GetID()
+		if _, ok := copies[id]; !ok {
+			order = append(order, id)
+		}
+		copies[id] = append(copies[id], item)
+	}
+	var kept []model.SessionSummaryProvider
+	for _, id := range order {
+		if len(copies[id]) > 1 {
+			kept = append(kept, copies[id]...)
+		}
+	}
+	return kept
+}
+
+// page returns the 1-based page of size items; pages past the end are empty.
+func page(items []model.SessionSummaryProvider, number, size int) []model.SessionSummaryProvider {
+	if number < 1 {
+		number = 1
+	}
+	start := (number - 1) * size
+	if start >= len(items) {
+		return nil
+	}
+	end := start + size
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
 
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+// betweenAnchors returns the sorted items that come after the session with
+// id afterID and before the one with id beforeID. An empty id leaves that
+// end open.
+func betweenAnchors(items []model.SessionSummaryProvider, afterID, beforeID string) ([]model.SessionSummaryProvider, error) {
+	start, end := 0, len(items)
+	if afterID != "" {
+		idx, err := anchorIndex(items, afterID)
+		if err != nil {
+			return nil, err
+		}
+		start = idx + 1
+	}
+	if beforeID != "" {
+		idx, err := anchorIndex(items, beforeID)
+		if err != nil {
+			return nil, err
+		}
+		end = idx
+	}
+	if start >= end {
+		return nil, nil
+	}
+	return items[start:end], nil
+}
+
+func anchorIndex(items []model.SessionSummaryProvider, id string) (int, error) {
+	for i, item := range items {
+		if item.GetID() == id {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("anchor session id %s %w among the listed sessions", id, ErrSessionNotFound)
+}
+
+// countSessionFiles returns the number of session files under roots.
+func countSessionFiles(roots []string, opts ListOptions) int {
+	n := 0
+	for _, root := range roots {
+		_ = walkSessions(root, opts.FollowSymlinks, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr == nil && d.IsDir() && excludedDir(root, path, opts.ExcludeDirs) {
+				return fs.SkipDir
+			}
+			if walkErr == nil && !d.IsDir() && model.IsSessionFile(d.Name()) {
+				n++
+			}
+			return nil
+		})
+	}
+	return n
+}
+
+// listRoot collects the sessions under root that pass the filters in opts,
+// calling scanned once per session file. Unreadable files are reported
+// through warnings and skipped.
+func listRoot(parser model.Parser, root string, opts ListOptions, warnings *[]error, scanned func()) ([]model.SessionSummaryProvider, error) {
+	var summaries []model.SessionSummaryProvider
+
+	err := walkSessions(root, opts.FollowSymlinks, func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
-			result.Warnings = append(result.Warnings, fmt.Errorf("walk %s: %w", path, walkErr))
+			*warnings = append(*warnings, &Warning{Kind: WarningWalk, Path: path, Err: walkErr})
 			return nil
 		}
 
-		if d.IsDir() || !strings.HasSuffix(d.Name(), ".jsonl") {
+		if d.IsDir() && excludedDir(root, path, opts.ExcludeDirs) {
+			return fs.SkipDir
+		}
+		if d.IsDir() || !model.IsSessionFile(d.Name()) {
 			return nil
 		}
+		defer scanned()
 
 		meta, err := parser.ReadSessionMeta(path)
 		if err != nil {
-			result.Warnings = append(result.Warnings, fmt.Errorf("parse meta %s: %w", path, err))
+			*warnings = append(*warnings, &Warning{Kind: WarningMeta, Path: path, Err: err})
 			return nil
 		}
 
@@ -90,29 +465,58 @@ func ListSessions(parser model.Parser, opts ListOptions) (ListResult, error) {
 		if opts.Before != nil && meta.GetStartedAt().After(*opts.Before) {
 			return nil
 		}
+		if opts.Tag != "" && !opts.Tags.Has(meta.GetID(), opts.Tag) {
+			return nil
+		}
 
-		summaryText, err := parser.FirstUserSummary(path)
+		summaryText, err := sessionSummaryText(parser, path, opts.SummarySource)
 		if err != nil {
-			result.Warnings = append(result.Warnings, fmt.Errorf("extract summary %s: %w", path, err))
+			*warnings = append(*warnings, &Warning{Kind: WarningSummary, Path: path, Err: err})
 			return nil
 		}
 
 		if opts.MaxSummary > 0 && len(summaryText) > opts.MaxSummary {
-			summaryText = truncate(summaryText, opts.MaxSummary)
+			summaryText = util.ClipRunes(summaryText, opts.MaxSummary)
 		}
 
-		// Count messages and find last timestamp
-		var count int
-		var lastTimestamp time.Time
+		count, err := parser.CountMessages(path)
+		if err != nil {
+			*warnings = append(*warnings, &Warning{Kind: WarningCount, Path: path, Err: err})
+			return nil
+		}
+		if opts.MinMessages > 0 && count < opts.MinMessages {
+			return nil
+		}
+		if opts.MaxMessages > 0 && count > opts.MaxMessages {
+			return nil
+		}
+
+		// Find last timestamp, the models and effort used, and whether the last turn finished
+		var (
+			lastTimestamp time.Time
+			models        []string
+			effort        string
+			turns         TurnTracker
+		)
 		err = parser.IterateEvents(path, func(event model.EventProvider) error {
-			count++
 			if !event.GetTimestamp().IsZero() && event.GetTimestamp().After(lastTimestamp) {
 				lastTimestamp = event.GetTimestamp()
 			}
+			if provider, ok := event.(model.ModelProvider); ok {
+				models = appendModel(models, provider.GetModel())
+			}
+			if provider, ok := event.(model.EffortProvider); ok && provider.GetEffort() != "" {
+				effort = provider.GetEffort()
+			}
+			turns.Observe(event)
 			return nil
 		})
 		if err != nil {
-			result.Warnings = append(result.Warnings, fmt.Errorf("count messages %s: %w", path, err))
+			*warnings = append(*warnings, &Warning{Kind: WarningEvents, Path: path, Err: err})
+			return nil
+		}
+
+		if opts.ModelFilter != "" && !matchesModel(models, opts.ModelFilter) {
 			return nil
 		}
 
@@ -120,9 +524,16 @@ func ListSessions(parser model.Parser, opts ListOptions) (ListResult, error) {
 			lastTimestamp = meta.GetStartedAt()
 		}
 
-		duration := durationSeconds(meta.GetStartedAt(), lastTimestamp)
+		duration := util.DurationSeconds(meta.GetStartedAt(), lastTimestamp)
+		elapsed := time.Duration(duration) * time.Second
+		if opts.MinDuration != nil && elapsed < *opts.MinDuration {
+			return nil
+		}
+		if opts.MaxDuration != nil && elapsed > *opts.MaxDuration {
+			return nil
+		}
 
-		result.Summaries = append(result.Summaries, &sessionSummary{
+		summaries = append(summaries, &sessionSummary{
 			id:              meta.GetID(),
 			path:            path,
 			cwd:             meta.GetCWD(),
@@ -130,51 +541,114 @@ func ListSessions(parser model.Parser, opts ListOptions) (ListResult, error) {
 			summary:         summaryText,
 			messageCount:    count,
 			durationSeconds: duration,
+			models:          models,
+			effort:          effort,
+			interrupted:     turns.Interrupted(),
 		})
 
 		return nil
 	})
-	if err != nil {
-		return result, err
-	}
-
-	sort.Slice(result.Summaries, func(i, j int) bool {
-		return result.Summaries[i].GetStartedAt().After(result.Summaries[j].GetStartedAt())
-	})
+	return summaries, err
+}
 
-	if opts.Limit > 0 && len(result.Summaries) > opts.Limit {
-		result.Summaries = result.Summaries[:opts.Limit]
+// appendModel records name as the most recent model, moving it to the end
+// when it was already seen.
+func appendModel(models []string, name string) []string {
+	if name == "" {
+		return models
 	}
-
-	return result, nil
+	for i, existing := range models {
+		if existing == name {
+			if i == len(models)-1 {
+				return models
+			}
+			models = append(models[:i], models[i+1:]...)
+			break
+		}
+	}
+	return append(models, name)
 }
 
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	runes := []rune(s)
-	if len(runes) <= maxLen {
-		return s
+func matchesModel(models []string, filter string) bool {
+	filter = strings.ToLower(filter)
+	for _, name := range models {
+		if strings.Contains(strings.ToLower(name), filter) {
+			return true
+		}
 	}
-	return string(runes[:maxLen]) + "…"
+	return false
 }
 
-// FindSessionPath searches for a session file whose session id matches id.
-func FindSessionPath(parser model.Parser, root, id string) (string, error) {
-	if root == "" {
+// FindSessionPath searches roots in order for a session file whose session
+// id matches id. An id that matches no session exactly may be a prefix of
+// one; a prefix shared by several sessions is rejected with
+// ErrAmbiguousSession. followSymlinks descends into symlinked directories,
+// as ListOptions.FollowSymlinks does.
+//
+// Both agents usually put the session id in the file name, so files named
+// after id are tried first; only when none of them holds the session is
+// every file's metadata read.
+func FindSessionPath(parser model.Parser, roots []string, id string, followSymlinks bool) (string, error) {
+	roots = ListOptions{Roots: roots}.roots()
+	if len(roots) == 0 {
 		return "", errors.New("root directory is required")
 	}
 	if id == "" {
 		return "", errors.New("session id is required")
 	}
 
+	for _, root := range roots {
+		path, err := findSessionPathByName(parser, root, id, followSymlinks)
+		if err != nil {
+			return "", err
+		}
+		if path != "" {
+			return path, nil
+		}
+	}
+
+	var prefixed []sessionMatch
+	for _, root := range roots {
+		path, matches, err := findSessionPathIn(parser, root, id, followSymlinks)
+		if err != nil {
+			return "", err
+		}
+		if path != "" {
+			return path, nil
+		}
+		prefixed = append(prefixed, matches...)
+	}
+
+	ids := make([]string, 0, len(prefixed))
+	seen := make(map[string]struct{})
+	for _, match := range prefixed {
+		if _, ok := seen[match.id]; ok {
+			continue
+		}
+		seen[match.id] = struct{}{}
+		ids = append(ids, match.id)
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("session id %s %w under %s", id, ErrSessionNotFound, strings.Join(roots, ", "))
+	case 1:
+		return prefixed[0].path, nil
+	default:
+		sort.Strings(ids)
+		return "", fmt.Errorf("session id prefix %s %w: %s", id, ErrAmbiguousSession, strings.Join(ids, ", "))
+	}
+}
+
+// findSessionPathByName returns the path of the session under root whose
+// id is exactly id, reading only files whose name contains id, or "" when
+// none of them is that session.
+func findSessionPathByName(parser model.Parser, root, id string, followSymlinks bool) (string, error) {
 	var matched string
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+	err := walkSessions(root, followSymlinks, func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return nil
 		}
-		if d.IsDir() || !strings.HasSuffix(d.Name(), ".jsonl") {
+		if d.IsDir() || !model.IsSessionFile(d.Name()) || !strings.Contains(d.Name(), id) {
 			return nil
 		}
 		meta, err := parser.ReadSessionMeta(path)
@@ -191,21 +665,52 @@ func FindSessionPath(parser model.Parser, root, id string) (string, error) {
 	if matched != "" {
 		return matched, nil
 	}
-	if err != nil {
-		if errors.Is(err, errStop) {
-			return matched, nil
-		}
+	if err != nil && !errors.Is(err, errStop) {
 		return "", err
 	}
-	return "", fmt.Errorf("session id %s not found under %s", id, root)
+	return "", nil
+}
+
+// sessionMatch is a session whose id starts with the id being looked up.
+type sessionMatch struct {
+	id   string
+	path string
 }
 
-func durationSeconds(start, end time.Time) int {
-	if start.IsZero() || end.IsZero() {
-		return 0
+// findSessionPathIn returns the path of the session under root whose id is
+// exactly id. When there is none, it returns "" and the sessions whose ids
+// start with id instead.
+func findSessionPathIn(parser model.Parser, root, id string, followSymlinks bool) (string, []sessionMatch, error) {
+	var (
+		matched  string
+		prefixed []sessionMatch
+	)
+	err := walkSessions(root, followSymlinks, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return nil
+		}
+		if d.IsDir() || !model.IsSessionFile(d.Name()) {
+			return nil
+		}
+		meta, err := parser.ReadSessionMeta(path)
+		if err != nil {
+			return nil
+		}
+		switch sessionID := meta.GetID(); {
+		case sessionID == id:
+			matched = path
+			return errStop
+		case strings.HasPrefix(sessionID, id):
+			prefixed = append(prefixed, sessionMatch{id: sessionID, path: path})
+		}
+		return nil
+	})
+
+	if matched != "" {
+		return matched, nil, nil
 	}
-	if end.Before(start) {
-		return 0
+	if err != nil && !errors.Is(err, errStop) {
+		return "", nil, err
 	}
-	return int(end.Sub(start).Seconds())
+	return "", prefixed, nil
 }
@@ -1,8 +1,14 @@
 package store
 
 import (
+	"agentlog/internal/claude"
 	"agentlog/internal/codex"
+	"agentlog/internal/model"
+	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -20,22 +26,82 @@ func TestListSessions(t *testing.T) {
 		t.Fatalf("expected 2 summaries, got %d", len(res.Summaries))
 	}
 
-	// Both sessions have same timestamp, order may vary
-	ids := map[string]bool{}
-	for _, s := range res.Summaries {
-		ids[s.GetID()] = true
+	// Newest first: the full session started an hour after the simple one.
+	if res.Summaries[0].GetID() != "test-full-session" || res.Summaries[1].GetID() != "test-simple-session" {
+		t.Fatalf("unexpected order: %s, %s", res.Summaries[0].GetID(), res.Summaries[1].GetID())
 	}
 
-	if !ids["test-full-session"] {
-		t.Fatalf("expected test-full-session in results")
+	if len(res.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %d", len(res.Warnings))
 	}
+}
+
+func TestListSessionsTieBreak(t *testing.T) {
+	// Every copy starts at the same instant. Names are chosen so that walk
+	// order differs from the expected order.
+	root := t.TempDir()
+	writeSessionCopies(t, root, map[string]string{
+		"a.jsonl": "session-c",
+		"b.jsonl": "session-a",
+		"c.jsonl": "session-b",
+		"d.jsonl": "session-a",
+	})
 
-	if !ids["test-simple-session"] {
-		t.Fatalf("expected test-simple-session in results")
+	res, err := ListSessions(&codex.CodexParser{}, ListOptions{Root: root})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+	var got []string
+	for _, summary := range res.Summaries {
+		got = append(got, summary.GetID()+" "+filepath.Base(summary.GetPath()))
 	}
+	want := []string{"session-a b.jsonl", "session-a d.jsonl", "session-b c.jsonl", "session-c a.jsonl"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("unexpected order for equal start times:\n got %v\nwant %v", got, want)
+	}
+}
 
-	if len(res.Warnings) != 0 {
-		t.Fatalf("expected no warnings, got %d", len(res.Warnings))
+func TestListSessionsAnchorIDs(t *testing.T) {
+	// Equal start times sort by id, so the listing is session-a through
+	// session-e.
+	root := t.TempDir()
+	files := make(map[string]string)
+	for _, id := range []string{"session-d", "session-b", "session-e", "session-a", "session-c"} {
+		files[id+".jsonl"] = id
+	}
+	writeSessionCopies(t, root, files)
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want string
+	}{
+		{"after", ListOptions{AfterID: "session-b"}, "session-c session-d session-e"},
+		{"after with limit", ListOptions{AfterID: "session-b", Limit: 2}, "session-c session-d"},
+		{"before", ListOptions{BeforeID: "session-c"}, "session-a session-b"},
+		{"between", ListOptions{AfterID: "session-a", BeforeID: "session-e"}, "session-b session-c session-d"},
+		{"after last", ListOptions{AfterID: "session-e"}, ""},
+		{"crossed", ListOptions{AfterID: "session-d", BeforeID: "session-b"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Root = root
+			res, err := ListSessions(&codex.CodexParser{}, tt.opts)
+			if err != nil {
+				t.Fatalf("ListSessions returned error: %v", err)
+			}
+			var got []string
+			for _, summary := range res.Summaries {
+				got = append(got, summary.GetID())
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Fatalf("got %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ListSessions(&codex.CodexParser{}, ListOptions{Root: root, AfterID: "missing"}); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected not found error for unknown anchor, got %v", err)
 	}
 }
 
@@ -61,7 +127,7 @@ func TestListSessionsFilters(t *testing.T) {
 func TestFindSessionPath(t *testing.T) {
 	root := filepath.Join("..", "..", "testdata", "sessions")
 	parser := &codex.CodexParser{}
-	path, err := FindSessionPath(parser, root, "test-simple-session")
+	path, err := FindSessionPath(parser, []string{root}, "test-simple-session", false)
 	if err != nil {
 		t.Fatalf("FindSessionPath returned error: %v", err)
 	}
@@ -70,6 +136,283 @@ func TestFindSessionPath(t *testing.T) {
 	if path != expected {
 		t.Fatalf("unexpected path: %s", path)
 	}
+
+	path, err = FindSessionPath(parser, []string{root}, "test-simple", false)
+	if err != nil {
+		t.Fatalf("FindSessionPath with prefix returned error: %v", err)
+	}
+	if path != expected {
+		t.Fatalf("unexpected path for prefix: %s", path)
+	}
+	if _, err := FindSessionPath(parser, []string{root}, "test-", false); !errors.Is(err, ErrAmbiguousSession) {
+		t.Fatalf("expected ambiguous prefix error, got %v", err)
+	}
+}
+
+// countingParser counts the session files whose metadata is read.
+type countingParser struct {
+	model.Parser
+	reads int
+}
+
+func (p *countingParser) ReadSessionMeta(path string) (model.SessionMetaProvider, error) {
+	p.reads++
+	return p.Parser.ReadSessionMeta(path)
+}
+
+// writeSessionCopies writes a copy of the simple Codex session into root for
+// each file name in files, with the session id replaced by the mapped id.
+func writeSessionCopies(tb testing.TB, root string, files map[string]string) {
+	tb.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for name, id := range files {
+		copied := strings.Replace(string(data), "test-simple-session", id, 1)
+		if err := os.WriteFile(filepath.Join(root, name), []byte(copied), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// numberedSessionCopies names n sessions session-0000 onward, in files that
+// are not named after the id.
+func numberedSessionCopies(n int) map[string]string {
+	files := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		files[fmt.Sprintf("rollout-%04d.jsonl", i)] = fmt.Sprintf("session-%04d", i)
+	}
+	return files
+}
+
+func TestFindSessionPathByFileName(t *testing.T) {
+	root := t.TempDir()
+	files := numberedSessionCopies(20)
+	files["rollout-2025-11-05-test-simple-session.jsonl"] = "test-simple-session"
+	writeSessionCopies(t, root, files)
+	named := filepath.Join(root, "rollout-2025-11-05-test-simple-session.jsonl")
+
+	parser := &countingParser{Parser: &codex.CodexParser{}}
+	path, err := FindSessionPath(parser, []string{root}, "test-simple-session", false)
+	if err != nil {
+		t.Fatalf("FindSessionPath returned error: %v", err)
+	}
+	if path != named {
+		t.Fatalf("unexpected path: %s", path)
+	}
+	if parser.reads != 1 {
+		t.Fatalf("expected only the file named after the session to be read, read %d", parser.reads)
+	}
+
+	// A session whose file is not named after it is still found by its
+	// metadata.
+	parser.reads = 0
+	path, err = FindSessionPath(parser, []string{root}, "session-0007", false)
+	if err != nil {
+		t.Fatalf("FindSessionPath returned error: %v", err)
+	}
+	if want := filepath.Join(root, "rollout-0007.jsonl"); path != want {
+		t.Fatalf("unexpected path: %s", path)
+	}
+	if parser.reads < 2 {
+		t.Fatalf("expected a metadata scan, read %d", parser.reads)
+	}
+}
+
+func BenchmarkFindSessionPath(b *testing.B) {
+	root := b.TempDir()
+	files := numberedSessionCopies(500)
+	files["rollout-test-simple-session.jsonl"] = "test-simple-session"
+	writeSessionCopies(b, root, files)
+	parser := &codex.CodexParser{}
+
+	for _, bench := range []struct{ name, id string }{
+		{"file name", "test-simple-session"},
+		{"metadata scan", "session-0499"},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := FindSessionPath(parser, []string{root}, bench.id, false); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestListSessionsMissingRoot(t *testing.T) {
+	parser := &codex.CodexParser{}
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	_, err := ListSessions(parser, ListOptions{Root: missing})
+	if !errors.Is(err, ErrSessionsDirNotFound) {
+		t.Fatalf("expected ErrSessionsDirNotFound, got %v", err)
+	}
+	if !strings.Contains(err.Error(), missing) || !strings.Contains(err.Error(), "is the agent installed?") {
+		t.Fatalf("error should name the directory and suggest a cause: %v", err)
+	}
+
+	sessions := filepath.Join("..", "..", "testdata", "sessions")
+	if _, err := ListSessions(parser, ListOptions{Roots: []string{sessions, missing}}); !errors.Is(err, ErrSessionsDirNotFound) {
+		t.Fatalf("expected a missing root among several to fail, got %v", err)
+	}
+}
+
+func TestListSessionsEmptyRoot(t *testing.T) {
+	res, err := ListSessions(&codex.CodexParser{}, ListOptions{Root: t.TempDir()})
+	if err != nil {
+		t.Fatalf("an empty sessions directory should not be an error: %v", err)
+	}
+	if len(res.Summaries) != 0 || len(res.Warnings) != 0 {
+		t.Fatalf("expected no sessions and no warnings, got %d sessions and %v", len(res.Summaries), res.Warnings)
+	}
+}
+
+func TestListSessionsFollowSymlinks(t *testing.T) {
+	sessions, err := filepath.Abs(filepath.Join("..", "..", "testdata", "sessions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// root/2025 links to the fixtures, and root/2025/loop would lead back
+	// to root forever if cycles were not detected.
+	root := t.TempDir()
+	linked := filepath.Join(root, "2025")
+	if err := os.Symlink(sessions, linked); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	loopDir := t.TempDir()
+	if err := os.Symlink(root, filepath.Join(loopDir, "loop")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(loopDir, filepath.Join(root, "more")); err != nil {
+		t.Fatal(err)
+	}
+	parser := &codex.CodexParser{}
+
+	res, err := ListSessions(parser, ListOptions{Root: root})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+	if len(res.Summaries) != 0 {
+		t.Fatalf("expected symlinks to be ignored by default, got %d sessions", len(res.Summaries))
+	}
+	if _, err := FindSessionPath(parser, []string{root}, "test-simple-session", false); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound without FollowSymlinks, got %v", err)
+	}
+
+	res, err = ListSessions(parser, ListOptions{Root: root, FollowSymlinks: true})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+	if len(res.Summaries) != 2 {
+		t.Fatalf("expected each fixture once through the symlink, got %d sessions", len(res.Summaries))
+	}
+	for _, summary := range res.Summaries {
+		if !strings.HasPrefix(summary.GetPath(), linked+string(filepath.Separator)) {
+			t.Fatalf("expected paths under the symlink, got %s", summary.GetPath())
+		}
+	}
+	path, err := FindSessionPath(parser, []string{root}, "test-simple-session", true)
+	if err != nil {
+		t.Fatalf("FindSessionPath returned error: %v", err)
+	}
+	if path != filepath.Join(linked, "sample-simple.jsonl") {
+		t.Fatalf("unexpected path: %s", path)
+	}
+
+	// A symlinked root is followed too.
+	res, err = ListSessions(parser, ListOptions{Root: linked, FollowSymlinks: true})
+	if err != nil || len(res.Summaries) != 2 {
+		t.Fatalf("expected 2 sessions under a symlinked root, got %d (err %v)", len(res.Summaries), err)
+	}
+}
+
+func TestListSessionsExcludeDirs(t *testing.T) {
+	root := t.TempDir()
+	place := func(dir, fixture string) {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join("..", "..", "testdata", "sessions", fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, fixture), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	place("-Users-test-project", "sample-simple.jsonl")
+	place(filepath.Join("archive-2024", "-Users-test-old"), "sample-full.jsonl")
+	parser := &codex.CodexParser{}
+
+	for _, patterns := range [][]string{{"archive-*"}, {"archive-2024/-Users-*"}} {
+		res, err := ListSessions(parser, ListOptions{Root: root, ExcludeDirs: patterns})
+		if err != nil {
+			t.Fatalf("ListSessions returned error: %v", err)
+		}
+		if len(res.Summaries) != 1 || res.Summaries[0].GetID() != "test-simple-session" {
+			t.Fatalf("expected only the session outside the excluded directory for %v, got %d sessions", patterns, len(res.Summaries))
+		}
+	}
+
+	res, err := ListSessions(parser, ListOptions{Root: root, ExcludeDirs: []string{"-Users-test"}})
+	if err != nil || len(res.Summaries) != 2 {
+		t.Fatalf("expected a partial name not to exclude anything, got %d sessions (err %v)", len(res.Summaries), err)
+	}
+
+	if _, err := ListSessions(parser, ListOptions{Root: root, ExcludeDirs: []string{"["}}); err == nil {
+		t.Fatal("expected an invalid pattern to be rejected")
+	}
+}
+
+func TestListSessionsMultipleRoots(t *testing.T) {
+	sessions := filepath.Join("..", "..", "testdata", "sessions")
+	edgeCases := filepath.Join("..", "..", "testdata", "codex-edge-cases")
+	parser := &codex.CodexParser{}
+
+	// sessions is given twice; its sessions must be listed only once.
+	res, err := ListSessions(parser, ListOptions{Roots: []string{sessions, edgeCases}, Root: sessions})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+
+	ids := map[string]string{}
+	for _, s := range res.Summaries {
+		if prev, dup := ids[s.GetID()]; dup {
+			t.Fatalf("session %s listed twice: %s and %s", s.GetID(), prev, s.GetPath())
+		}
+		ids[s.GetID()] = s.GetPath()
+	}
+	for _, id := range []string{"test-simple-session", "test-full-session", "test-turn-context-session"} {
+		if _, ok := ids[id]; !ok {
+			t.Fatalf("expected %s in merged results, got %v", id, ids)
+		}
+	}
+	for i := 1; i < len(res.Summaries); i++ {
+		if res.Summaries[i].GetStartedAt().After(res.Summaries[i-1].GetStartedAt()) {
+			t.Fatalf("summaries not sorted newest first at index %d", i)
+		}
+	}
+}
+
+func TestFindSessionPathMultipleRoots(t *testing.T) {
+	sessions := filepath.Join("..", "..", "testdata", "sessions")
+	edgeCases := filepath.Join("..", "..", "testdata", "codex-edge-cases")
+	parser := &codex.CodexParser{}
+
+	path, err := FindSessionPath(parser, []string{sessions, edgeCases}, "test-turn-context-session", false)
+	if err != nil {
+		t.Fatalf("FindSessionPath returned error: %v", err)
+	}
+	if want := filepath.Join(edgeCases, "turn-context.jsonl"); path != want {
+		t.Fatalf("unexpected path: %s", path)
+	}
+
+	if _, err := FindSessionPath(parser, []string{sessions, edgeCases}, "missing", false); err == nil {
+		t.Fatal("expected error for unknown session id")
+	}
 }
 
 func TestListSessionsExactCWD(t *testing.T) {
@@ -92,3 +435,501 @@ func TestListSessionsExactCWD(t *testing.T) {
 		t.Fatalf("expected duration to be populated")
 	}
 }
+
+func TestListSessionsClaudeMessageCount(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "claude-sessions")
+	parser := &claude.ClaudeParser{}
+
+	res, err := ListSessions(parser, ListOptions{Root: root})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+
+	var found bool
+	for _, s := range res.Summaries {
+		if s.GetID() != "test-claude-tools" {
+			continue
+		}
+		found = true
+		if s.GetMessageCount() != 4 {
+			t.Fatalf("expected 4 messages (summary entry excluded), got %d", s.GetMessageCount())
+		}
+	}
+	if !found {
+		t.Fatalf("expected test-claude-tools in results")
+	}
+}
+
+func TestListSessionsSummarySource(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "claude-sessions")
+	summaries := func(source string) map[string]string {
+		t.Helper()
+		res, err := ListSessions(&claude.ClaudeParser{}, ListOptions{Root: root, SummarySource: source})
+		if err != nil {
+			t.Fatalf("ListSessions returned error: %v", err)
+		}
+		byID := make(map[string]string)
+		for _, s := range res.Summaries {
+			byID[s.GetID()] = s.GetSummary()
+		}
+		return byID
+	}
+
+	if got := summaries("")["test-claude-tools"]; got != "Read the README file" {
+		t.Fatalf("default summary = %q, want the first user message", got)
+	}
+	bySummary := summaries("summary")
+	if got := bySummary["test-claude-tools"]; got != "Reading and discussing README file" {
+		t.Fatalf("summary source = %q, want the summary entry", got)
+	}
+	if got, want := bySummary["test-claude-session"], summaries("")["test-claude-session"]; got == "" || got != want {
+		t.Fatalf("session without a summary entry = %q, want its first user message %q", got, want)
+	}
+}
+
+func TestListSessionsModelFilter(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "claude-sessions")
+	parser := &claude.ClaudeParser{}
+
+	ids := func(opts ListOptions) []string {
+		t.Helper()
+		opts.Root = root
+		res, err := ListSessions(parser, opts)
+		if err != nil {
+			t.Fatalf("ListSessions returned error: %v", err)
+		}
+		var out []string
+		for _, s := range res.Summaries {
+			out = append(out, s.GetID())
+		}
+		return out
+	}
+
+	// Matches an earlier model in a session that later switched.
+	if got := ids(ListOptions{ModelFilter: "HAIKU"}); len(got) != 1 || got[0] != "test-claude-model-switch" {
+		t.Fatalf("expected only the model-switch session for haiku, got %v", got)
+	}
+	if got := ids(ListOptions{ModelFilter: "opus"}); len(got) != 1 || got[0] != "test-claude-model-switch" {
+		t.Fatalf("expected only the model-switch session for opus, got %v", got)
+	}
+	sonnet := ids(ListOptions{ModelFilter: "sonnet"})
+	for _, id := range sonnet {
+		if id == "test-claude-model-switch" {
+			t.Fatalf("model-switch session should not match sonnet: %v", sonnet)
+		}
+	}
+	if len(sonnet) == 0 {
+		t.Fatalf("expected sonnet sessions")
+	}
+	if got := ids(ListOptions{ModelFilter: "gpt"}); len(got) != 0 {
+		t.Fatalf("expected no matches for gpt, got %v", got)
+	}
+}
+
+func TestListSessionsRecordsLatestModel(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "claude-sessions")
+	res, err := ListSessions(&claude.ClaudeParser{}, ListOptions{Root: root, ModelFilter: "opus"})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+	if len(res.Summaries) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(res.Summaries))
+	}
+	summary := res.Summaries[0].(*sessionSummary)
+	if summary.GetModel() != "claude-opus-4-20250514" {
+		t.Fatalf("unexpected latest model: %s", summary.GetModel())
+	}
+	if len(summary.GetModels()) != 2 {
+		t.Fatalf("expected 2 models, got %v", summary.GetModels())
+	}
+}
+
+func TestListSessionsMessageCountBounds(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "codex-edge-cases")
+	parser := &codex.CodexParser{}
+
+	ids := func(opts ListOptions) map[string]bool {
+		t.Helper()
+		opts.Root = root
+		res, err := ListSessions(parser, opts)
+		if err != nil {
+			t.Fatalf("ListSessions returned error: %v", err)
+		}
+		out := map[string]bool{}
+		for _, s := range res.Summaries {
+			out[s.GetID()] = true
+		}
+		return out
+	}
+
+	if all := ids(ListOptions{}); !all["test-empty-session"] {
+		t.Fatalf("expected empty session without filters, got %v", all)
+	}
+	nonEmpty := ids(ListOptions{MinMessages: 1})
+	if nonEmpty["test-empty-session"] {
+		t.Fatalf("empty session should be excluded by MinMessages 1")
+	}
+	if !nonEmpty["test-turn-context-session"] {
+		t.Fatalf("expected sessions with messages to remain, got %v", nonEmpty)
+	}
+	small := ids(ListOptions{MaxMessages: 2})
+	if small["test-turn-context-session"] {
+		t.Fatalf("4-message session should be excluded by MaxMessages 2, got %v", small)
+	}
+	if !small["test-empty-session"] || !small["test-reasoning-session"] {
+		t.Fatalf("expected sessions with at most 2 messages, got %v", small)
+	}
+}
+
+func TestListSessionsDurationBounds(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "sessions")
+	parser := &codex.CodexParser{}
+
+	// sample-full lasts 15s, sample-simple 4s.
+	ids := func(min, max *time.Duration) []string {
+		t.Helper()
+		res, err := ListSessions(parser, ListOptions{Root: root, MinDuration: min, MaxDuration: max})
+		if err != nil {
+			t.Fatalf("ListSessions returned error: %v", err)
+		}
+		var out []string
+		for _, s := range res.Summaries {
+			out = append(out, s.GetID())
+		}
+		return out
+	}
+	dur := func(d time.Duration) *time.Duration { return &d }
+
+	if got := ids(dur(10*time.Second), nil); len(got) != 1 || got[0] != "test-full-session" {
+		t.Fatalf("expected only test-full-session for min 10s, got %v", got)
+	}
+	if got := ids(nil, dur(10*time.Second)); len(got) != 1 || got[0] != "test-simple-session" {
+		t.Fatalf("expected only test-simple-session for max 10s, got %v", got)
+	}
+	if got := ids(dur(4*time.Second), dur(15*time.Second)); len(got) != 2 {
+		t.Fatalf("bounds should be inclusive, got %v", got)
+	}
+}
+
+func TestListSessionsMaxSummaryZeroKeepsFullText(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "codex-edge-cases")
+	parser := &codex.CodexParser{}
+
+	summaryFor := func(maxSummary int) string {
+		t.Helper()
+		res, err := ListSessions(parser, ListOptions{Root: root, MaxSummary: maxSummary})
+		if err != nil {
+			t.Fatalf("ListSessions returned error: %v", err)
+		}
+		for _, s := range res.Summaries {
+			if s.GetID() == "test-secrets-session" {
+				return s.GetSummary()
+			}
+		}
+		t.Fatalf("test-secrets-session not found")
+		return ""
+	}
+
+	clipped := summaryFor(40)
+	if !strings.HasSuffix(clipped, "…") {
+		t.Fatalf("expected clipped summary, got %q", clipped)
+	}
+	full := summaryFor(0)
+	if strings.HasSuffix(full, "…") || !strings.HasSuffix(full, "/Users/test/.config/acme.toml") {
+		t.Fatalf("expected full summary, got %q", full)
+	}
+}
+
+func TestListSessionsStructuredWarnings(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "broken-sessions")
+	parser := &codex.CodexParser{}
+
+	res, err := ListSessions(parser, ListOptions{Root: root})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+
+	var found bool
+	for _, err := range res.Warnings {
+		var warn *Warning
+		if !errors.As(err, &warn) {
+			t.Fatalf("expected *Warning, got %T: %v", err, err)
+		}
+		if warn.Kind == WarningMeta && filepath.Base(warn.Path) == "no-meta.jsonl" {
+			found = true
+			if !strings.HasPrefix(warn.Error(), "parse meta ") {
+				t.Fatalf("unexpected warning text: %q", warn.Error())
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected parse_meta warning for no-meta.jsonl, got %v", res.Warnings)
+	}
+}
+
+func TestListSessionsPaging(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "claude-sessions")
+	parser := &claude.ClaudeParser{}
+
+	list := func(opts ListOptions) ([]string, int) {
+		t.Helper()
+		opts.Root = root
+		res, err := ListSessions(parser, opts)
+		if err != nil {
+			t.Fatalf("ListSessions returned error: %v", err)
+		}
+		var out []string
+		for _, s := range res.Summaries {
+			out = append(out, s.GetID())
+		}
+		return out, res.Total
+	}
+
+	all, total := list(ListOptions{})
+	if len(all) < 5 || total != len(all) {
+		t.Fatalf("expected at least 5 sessions with matching total, got %d (total %d)", len(all), total)
+	}
+
+	page2, total := list(ListOptions{Page: 2, PageSize: 2})
+	if strings.Join(page2, ",") != strings.Join(all[2:4], ",") {
+		t.Fatalf("page 2 of size 2: got %v, want %v", page2, all[2:4])
+	}
+	if total != len(all) {
+		t.Fatalf("expected total %d across pages, got %d", len(all), total)
+	}
+
+	lastPage := (len(all) + 1) / 2
+	last, _ := list(ListOptions{Page: lastPage, PageSize: 2})
+	if strings.Join(last, ",") != strings.Join(all[(lastPage-1)*2:], ",") {
+		t.Fatalf("last page: got %v, want %v", last, all[(lastPage-1)*2:])
+	}
+	if past, _ := list(ListOptions{Page: lastPage + 1, PageSize: 2}); len(past) != 0 {
+		t.Fatalf("expected no sessions past the last page, got %v", past)
+	}
+
+	// Paging applies after --limit.
+	limited, total := list(ListOptions{Limit: 3, Page: 2, PageSize: 2})
+	if len(limited) != 1 || limited[0] != all[2] || total != 3 {
+		t.Fatalf("expected only the 3rd session on page 2 of a limit of 3, got %v (total %d)", limited, total)
+	}
+}
+
+func TestListSessionsInterrupted(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "codex-edge-cases")
+	res, err := ListSessions(&codex.CodexParser{}, ListOptions{Root: root})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+	if len(res.Summaries) == 0 {
+		t.Fatalf("expected sessions")
+	}
+	for _, s := range res.Summaries {
+		want := s.GetID() == "test-turn-aborted-session"
+		if got := s.(*sessionSummary).IsInterrupted(); got != want {
+			t.Errorf("%s: interrupted = %v, want %v", s.GetID(), got, want)
+		}
+	}
+}
+
+func TestTurnTrackerEndsMidTurn(t *testing.T) {
+	parser := &codex.CodexParser{}
+	track := func(lines ...string) bool {
+		t.Helper()
+		var turns TurnTracker
+		for _, line := range lines {
+			event, err := parser.ParseEventLine([]byte(line))
+			if err != nil {
+				t.Fatalf("ParseEventLine(%s): %v", line, err)
+			}
+			turns.Observe(event)
+		}
+		return turns.Interrupted()
+	}
+
+	prompt := `{"timestamp":"2025-11-10T16:00:01Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"hi"}]}}`
+	reasoning := `{"timestamp":"2025-11-10T16:00:02Z","type":"response_item","payload":{"type":"reasoning","summary":[{"type":"summary_text","text":"thinking"}]}}`
+	call := `{"timestamp":"2025-11-10T16:00:03Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{}","call_id":"c1"}}`
+	output := `{"timestamp":"2025-11-10T16:00:04Z","type":"response_item","payload":{"type":"function_call_output","call_id":"c1","output":"ok"}}`
+	reply := `{"timestamp":"2025-11-10T16:00:05Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"done"}]}}`
+
+	if track() {
+		t.Fatalf("a session without events is not interrupted")
+	}
+	if !track(prompt) || !track(prompt, reasoning) {
+		t.Fatalf("an unanswered prompt should be interrupted")
+	}
+	if !track(prompt, reply, call) || !track(prompt, call, output) {
+		t.Fatalf("a pending tool call or result should be interrupted")
+	}
+	if track(prompt, call, output, reply) {
+		t.Fatalf("a session ending with a reply should not be interrupted")
+	}
+}
+
+func TestFirstResponseTracker(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "first-response.jsonl")
+	parser := &codex.CodexParser{}
+
+	var first FirstResponseTracker
+	if _, ok := first.Latency(); ok {
+		t.Fatalf("a session without events has no first response")
+	}
+	err := parser.IterateEvents(path, func(event model.EventProvider) error {
+		first.Observe(event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateEvents returned error: %v", err)
+	}
+	// The prompt at 14:00:03 is answered at 14:00:45; reasoning and tool
+	// calls in between do not count as the response.
+	if got, ok := first.Latency(); !ok || got != 42*time.Second {
+		t.Fatalf("Latency() = %v, %v; want 42s, true", got, ok)
+	}
+}
+
+func TestListSessionsUniqueCWD(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "claude-sessions")
+	parser := &claude.ClaudeParser{}
+
+	res, err := ListSessions(parser, ListOptions{Root: root, UniqueCWD: true})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+	seen := map[string]string{}
+	for _, s := range res.Summaries {
+		if prev, dup := seen[s.GetCWD()]; dup {
+			t.Fatalf("cwd %s listed twice: %s and %s", s.GetCWD(), prev, s.GetID())
+		}
+		seen[s.GetCWD()] = s.GetID()
+	}
+	// Both sessions share /Users/test/project; only the later one is kept.
+	if got := seen["/Users/test/project"]; got != "test-claude-meta" {
+		t.Fatalf("expected the latest session for /Users/test/project, got %q", got)
+	}
+
+	// --limit counts directories, not the sessions dropped as duplicates.
+	limited, err := ListSessions(parser, ListOptions{Root: root, UniqueCWD: true, Limit: len(res.Summaries)})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+	if len(limited.Summaries) != len(res.Summaries) {
+		t.Fatalf("expected limit to apply after de-duplication, got %d sessions, want %d", len(limited.Summaries), len(res.Summaries))
+	}
+}
+
+func TestListSessionsDuplicates(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "duplicate-sessions")
+	res, err := ListSessions(&codex.CodexParser{}, ListOptions{Root: root, Duplicates: true})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+
+	// The unique session started between the two copies; it is dropped and
+	// the copies are listed together, newest first.
+	want := []string{
+		filepath.Join(root, "2025", "11", "08", "rollout-copied.jsonl"),
+		filepath.Join(root, "2025", "11", "07", "rollout-copied.jsonl"),
+	}
+	var got []string
+	for _, summary := range res.Summaries {
+		if summary.GetID() != "copied-session" {
+			t.Fatalf("unexpected session %s in duplicates", summary.GetID())
+		}
+		got = append(got, summary.GetPath())
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got paths %v, want %v", got, want)
+	}
+
+	// Across roots, copies are reported rather than merged.
+	copies, err := ListSessions(&codex.CodexParser{}, ListOptions{
+		Roots:      []string{filepath.Join(root, "2025", "11", "07"), filepath.Join(root, "2025", "11", "08")},
+		Duplicates: true,
+	})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+	if len(copies.Summaries) != 2 {
+		t.Fatalf("expected both copies across roots, got %d sessions", len(copies.Summaries))
+	}
+}
+
+func TestResolveChain(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "codex-chain")
+	parser := &codex.CodexParser{}
+	expected := []string{
+		filepath.Join(root, "chain-start.jsonl"),
+		filepath.Join(root, "chain-resumed.jsonl"),
+	}
+
+	for _, id := range []string{"chain-start", "chain-resumed"} {
+		paths, err := ResolveChain(parser, ChainOptions{Roots: []string{root}}, id)
+		if err != nil {
+			t.Fatalf("ResolveChain(%s) returned error: %v", id, err)
+		}
+		if strings.Join(paths, ",") != strings.Join(expected, ",") {
+			t.Fatalf("ResolveChain(%s) = %v, want %v", id, paths, expected)
+		}
+	}
+
+	if _, err := ResolveChain(parser, ChainOptions{Roots: []string{root}}, "missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+
+	missingRoot := filepath.Join("..", "..", "testdata", "no-such-dir")
+	if _, err := ResolveChain(parser, ChainOptions{Roots: []string{missingRoot}}, "chain-start"); !errors.Is(err, ErrSessionsDirNotFound) {
+		t.Fatalf("expected ErrSessionsDirNotFound, got %v", err)
+	}
+
+	// A resume under an excluded directory is not followed.
+	excluded := t.TempDir()
+	for dir, name := range map[string]string{"": "chain-start.jsonl", "archive": "chain-resumed.jsonl"} {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(excluded, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(excluded, dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths, err := ResolveChain(parser, ChainOptions{Roots: []string{excluded}, ExcludeDirs: []string{"archive"}}, "chain-start")
+	if err != nil {
+		t.Fatalf("ResolveChain with ExcludeDirs returned error: %v", err)
+	}
+	if want := filepath.Join(excluded, "chain-start.jsonl"); len(paths) != 1 || paths[0] != want {
+		t.Fatalf("ResolveChain with ExcludeDirs = %v, want [%s]", paths, want)
+	}
+}
+
+func TestListSessionsProgress(t *testing.T) {
+	sessions := filepath.Join("..", "..", "testdata", "sessions")
+	edgeCases := filepath.Join("..", "..", "testdata", "codex-edge-cases")
+
+	var calls [][2]int
+	_, err := ListSessions(&codex.CodexParser{}, ListOptions{
+		Roots: []string{sessions, edgeCases},
+		Progress: func(done, total int) {
+			calls = append(calls, [2]int{done, total})
+		},
+	})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+
+	if len(calls) < 2 {
+		t.Fatalf("expected a progress call per session file, got %v", calls)
+	}
+	total := calls[0][1]
+	for i, call := range calls {
+		if call[0] != i+1 || call[1] != total {
+			t.Fatalf("call %d: got %d/%d, want %d/%d", i, call[0], call[1], i+1, total)
+		}
+	}
+	if last := calls[len(calls)-1]; last[0] != total {
+		t.Fatalf("expected the last call to report %d/%d, got %v", total, total, last)
+	}
+}
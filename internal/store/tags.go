@@ -0,0 +1,130 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Tags maps session ids to the tags given to them with 'agentlog tag'. A
+// tag is any non-empty text, such as "important bugfix". Tags are keyed by
+// id alone, so one file serves every agent.
+type Tags map[string][]string
+
+// DefaultTagsPath returns the file tags are kept in: AGENTLOG_TAGS_FILE if
+// set, otherwise agentlog/tags.json under $XDG_CONFIG_HOME or ~/.config.
+func DefaultTagsPath() string {
+	if path := os.Getenv("AGENTLOG_TAGS_FILE"); path != "" {
+		return path
+	}
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, _ := os.UserHomeDir()
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "agentlog", "tags.json")
+}
+
+// LoadTags reads the tags file at path. A missing file holds no tags.
+func LoadTags(path string) (Tags, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Tags{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read tags: %w", err)
+	}
+	tags := Tags{}
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, fmt.Errorf("parse tags %s: %w", path, err)
+	}
+	return tags, nil
+}
+
+// SaveTags writes tags to path, creating its directory. The file is
+// replaced in one step so a failed write leaves the old tags in place.
+func SaveTags(path string, tags Tags) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create tags directory: %w", err)
+	}
+	data, err := json.MarshalIndent(tags, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tags-*.json")
+	if err != nil {
+		return fmt.Errorf("write tags: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close() //nolint:errcheck
+		return fmt.Errorf("write tags: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write tags: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write tags: %w", err)
+	}
+	return nil
+}
+
+// Merge adds the tags in other to t. Each session's tags stay sorted and
+// free of repeats; tags that differ only in case are the same tag.
+func (t Tags) Merge(other Tags) {
+	for id, tags := range other {
+		merged := t[id]
+		for _, tag := range tags {
+			tag = strings.TrimSpace(tag)
+			if tag != "" && !hasTag(merged, tag) {
+				merged = append(merged, tag)
+			}
+		}
+		if len(merged) == 0 {
+			continue
+		}
+		sort.Strings(merged)
+		t[id] = merged
+	}
+}
+
+// Remove drops the given tags from session id, or all of its tags when none
+// are given.
+func (t Tags) Remove(id string, tags ...string) {
+	if len(tags) == 0 {
+		delete(t, id)
+		return
+	}
+	var kept []string
+	for _, tag := range t[id] {
+		if !hasTag(tags, tag) {
+			kept = append(kept, tag)
+		}
+	}
+	if len(kept) == 0 {
+		delete(t, id)
+		return
+	}
+	t[id] = kept
+}
+
+// Has reports whether session id carries tag, ignoring case.
+func (t Tags) Has(id, tag string) bool {
+	return hasTag(t[id], tag)
+}
+
+func hasTag(tags []string, tag string) bool {
+	tag = strings.TrimSpace(tag)
+	for _, existing := range tags {
+		if strings.EqualFold(existing, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"agentlog/internal/codex"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTagsLoadSaveMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agentlog", "tags.json")
+
+	tags, err := LoadTags(path)
+	if err != nil || len(tags) != 0 {
+		t.Fatalf("missing file should hold no tags, got %v, %v", tags, err)
+	}
+
+	tags.Merge(Tags{"s1": {"important bugfix", "auth"}})
+	tags.Merge(Tags{"s1": {"Auth", " "}, "s2": {"later"}})
+	if err := SaveTags(path, tags); err != nil {
+		t.Fatalf("SaveTags returned error: %v", err)
+	}
+
+	loaded, err := LoadTags(path)
+	if err != nil {
+		t.Fatalf("LoadTags returned error: %v", err)
+	}
+	want := Tags{"s1": {"auth", "important bugfix"}, "s2": {"later"}}
+	if !reflect.DeepEqual(loaded, want) {
+		t.Fatalf("got %v, want %v", loaded, want)
+	}
+	if !loaded.Has("s1", "AUTH") || loaded.Has("s2", "auth") {
+		t.Fatalf("Has should match tags of the session, ignoring case")
+	}
+
+	loaded.Remove("s1", "auth")
+	loaded.Remove("s2")
+	if want := (Tags{"s1": {"important bugfix"}}); !reflect.DeepEqual(loaded, want) {
+		t.Fatalf("after Remove got %v, want %v", loaded, want)
+	}
+}
+
+func TestListSessionsTagFilter(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "duplicate-sessions")
+	res, err := ListSessions(&codex.CodexParser{}, ListOptions{
+		Root: root,
+		Tag:  "Keep",
+		Tags: Tags{"unique-session": {"keep"}, "copied-session": {"other"}},
+	})
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+	if len(res.Summaries) != 1 || res.Summaries[0].GetID() != "unique-session" {
+		t.Fatalf("expected only unique-session, got %v", res.Summaries)
+	}
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"agentlog/internal/model"
+	"time"
+)
+
+// toolBlockTypes are the content blocks of tool calls and their results,
+// after which the assistant still owes a reply.
+var toolBlockTypes = map[string]struct{}{
+	"function_name":    {},
+	"function_output":  {},
+	"custom_tool_name": {},
+	"tool_use":         {},
+	"tool_result":      {},
+}
+
+// TurnTracker follows a session's events in order to tell whether the
+// session ended in the middle of a turn: after an aborted turn, a prompt the
+// assistant never answered, or a tool call it never followed up on.
+type TurnTracker struct {
+	interrupted bool
+}
+
+// Observe records the next event of the session.
+func (t *TurnTracker) Observe(event model.EventProvider) {
+	if provider, ok := event.(model.TurnAbortProvider); ok && provider.IsTurnAborted() {
+		t.interrupted = true
+		return
+	}
+	blocks := event.GetContent()
+	if hasBlockType(blocks, toolBlockTypes) {
+		t.interrupted = true
+		return
+	}
+	switch event.GetRole() {
+	case "user":
+		t.interrupted = true
+	case "assistant":
+		if hasReplyText(blocks) {
+			t.interrupted = false
+		}
+	}
+}
+
+// Interrupted reports whether the events observed so far end mid-turn.
+func (t *TurnTracker) Interrupted() bool { return t.interrupted }
+
+// FirstResponseTracker follows a session's events in order to measure how
+// long the assistant took to answer: the span from the first user message to
+// the first assistant message after it.
+type FirstResponseTracker struct {
+	user      time.Time
+	assistant time.Time
+}
+
+// Observe records the next event of the session.
+func (t *FirstResponseTracker) Observe(event model.EventProvider) {
+	ts := event.GetTimestamp()
+	if ts.IsZero() || !t.assistant.IsZero() {
+		return
+	}
+	switch event.GetRole() {
+	case "user":
+		if t.user.IsZero() {
+			t.user = ts
+		}
+	case "assistant":
+		if !t.user.IsZero() && !ts.Before(t.user) {
+			t.assistant = ts
+		}
+	}
+}
+
+// Latency returns the time to the first response, and false when the
+// session has no user message or no assistant reply to it.
+func (t *FirstResponseTracker) Latency() (time.Duration, bool) {
+	if t.assistant.IsZero() {
+		return 0, false
+	}
+	return t.assistant.Sub(t.user), true
+}
+
+func hasBlockType(blocks []model.ContentBlock, types map[string]struct{}) bool {
+	for _, block := range blocks {
+		if _, ok := types[block.Type]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasReplyText reports whether blocks carry answer text rather than only
+// reasoning.
+func hasReplyText(blocks []model.ContentBlock) bool {
+	for _, block := range blocks {
+		switch block.Type {
+		case "text", "output_text":
+			if block.Text != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
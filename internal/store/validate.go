@@ -0,0 +1,261 @@
+package store
+
+import (
+	"agentlog/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Severity classifies how serious a validation issue is.
+type Severity string
+
+const (
+	// SeverityError marks issues that make a session unusable or ambiguous.
+	SeverityError Severity = "error"
+	// SeverityWarning marks suspicious but readable sessions.
+	SeverityWarning Severity = "warning"
+)
+
+// IssueKind identifies the check that produced an issue.
+type IssueKind string
+
+const (
+	IssueUnreadable     IssueKind = "unreadable"
+	IssueMissingMeta    IssueKind = "missing_meta"
+	IssueMalformedLine  IssueKind = "malformed_line"
+	IssueNoEvents       IssueKind = "no_events"
+	IssueDuplicateID    IssueKind = "duplicate_id"
+	IssueTimestampOrder IssueKind = "timestamp_order"
+)
+
+// Issue describes a single problem found while validating a session file.
+type Issue struct {
+	Path     string    `json:"path"`
+	Line     int       `json:"line,omitempty"`
+	Kind     IssueKind `json:"kind"`
+	Severity Severity  `json:"severity"`
+	Message  string    `json:"message"`
+}
+
+// ValidateResult contains the issues found under a sessions directory.
+type ValidateResult struct {
+	FilesScanned int
+	Issues       []Issue
+}
+
+// ErrorCount returns the number of error-level issues.
+func (r ValidateResult) ErrorCount() int {
+	return r.count(SeverityError)
+}
+
+// WarningCount returns the number of warning-level issues.
+func (r ValidateResult) WarningCount() int {
+	return r.count(SeverityWarning)
+}
+
+func (r ValidateResult) count(severity Severity) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// ValidateOptions controls which session files ValidateSessions checks.
+type ValidateOptions struct {
+	// Roots are the directories to scan.
+	Roots []string
+	// FollowSymlinks and ExcludeDirs walk the roots as they do for
+	// ListSessions.
+	FollowSymlinks bool
+	ExcludeDirs    []string
+}
+
+// ValidateSessions scans every session file under the roots and reports
+// files without parseable metadata, malformed lines, files without events,
+// duplicated session IDs, and out-of-order timestamps. A missing root is
+// reported with ErrSessionsDirNotFound, as by ListSessions.
+func ValidateSessions(parser model.Parser, opts ValidateOptions) (ValidateResult, error) {
+	roots := ListOptions{Roots: opts.Roots}.roots()
+	if len(roots) == 0 {
+		return ValidateResult{}, errors.New("root directory is required")
+	}
+	for _, root := range roots {
+		if err := checkRoot(root); err != nil {
+			return ValidateResult{}, err
+		}
+	}
+	for _, pattern := range opts.ExcludeDirs {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return ValidateResult{}, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	var result ValidateResult
+	idPaths := make(map[string][]string)
+
+	for _, root := range roots {
+		if err := validateRoot(parser, root, opts, &result, idPaths); err != nil {
+			return result, err
+		}
+	}
+
+	ids := make([]string, 0, len(idPaths))
+	for id, paths := range idPaths {
+		if len(paths) > 1 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		paths := idPaths[id]
+		for _, path := range paths[1:] {
+			result.Issues = append(result.Issues, Issue{
+				Path:     path,
+				Kind:     IssueDuplicateID,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("session id %s is also used by %s", id, paths[0]),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// validateRoot validates the session files under root, recording issues in
+// result and session paths by ID in idPaths.
+func validateRoot(parser model.Parser, root string, opts ValidateOptions, result *ValidateResult, idPaths map[string][]string) error {
+	return walkSessions(root, opts.FollowSymlinks, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			result.Issues = append(result.Issues, Issue{
+				Path:     path,
+				Kind:     IssueUnreadable,
+				Severity: SeverityError,
+				Message:  walkErr.Error(),
+			})
+			return nil
+		}
+		if d.IsDir() && excludedDir(root, path, opts.ExcludeDirs) {
+			return fs.SkipDir
+		}
+		if d.IsDir() || !model.IsSessionFile(d.Name()) {
+			return nil
+		}
+
+		result.FilesScanned++
+		issues, id := validateFile(parser, path)
+		result.Issues = append(result.Issues, issues...)
+		if id != "" {
+			idPaths[id] = append(idPaths[id], path)
+		}
+		return nil
+	})
+}
+
+// validateFile runs the per-file checks and returns any issues along with the
+// session ID when the metadata could be read.
+func validateFile(parser model.Parser, path string) ([]Issue, string) {
+	issues, lines, err := checkLines(path)
+	if err != nil {
+		return append(issues, Issue{
+			Path:     path,
+			Kind:     IssueUnreadable,
+			Severity: SeverityError,
+			Message:  err.Error(),
+		}), ""
+	}
+	if lines == 0 {
+		return append(issues, Issue{
+			Path:     path,
+			Kind:     IssueNoEvents,
+			Severity: SeverityWarning,
+			Message:  "file contains no events",
+		}), ""
+	}
+
+	var id string
+	meta, err := parser.ReadSessionMeta(path)
+	if err != nil {
+		issues = append(issues, Issue{
+			Path:     path,
+			Kind:     IssueMissingMeta,
+			Severity: SeverityError,
+			Message:  err.Error(),
+		})
+	} else {
+		id = meta.GetID()
+	}
+
+	var (
+		index int
+		prev  time.Time
+	)
+	// Iteration errors are already covered by the malformed line check.
+	_ = parser.IterateEvents(path, func(event model.EventProvider) error {
+		index++
+		ts := event.GetTimestamp()
+		if ts.IsZero() {
+			return nil
+		}
+		if !prev.IsZero() && ts.Before(prev) {
+			issues = append(issues, Issue{
+				Path:     path,
+				Kind:     IssueTimestampOrder,
+				Severity: SeverityWarning,
+				Message: fmt.Sprintf("event %d at %s precedes previous event at %s",
+					index, ts.Format(time.RFC3339), prev.Format(time.RFC3339)),
+			})
+		}
+		prev = ts
+		return nil
+	})
+
+	return issues, id
+}
+
+// checkLines reports every non-empty line that is not valid JSON and returns
+// the number of non-empty lines.
+func checkLines(path string) ([]Issue, int, error) {
+	file, err := model.OpenSessionFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("open session file: %w", err)
+	}
+	defer file.Close() //nolint:errcheck
+
+	scanner := model.NewLineScanner(file)
+
+	var (
+		issues   []Issue
+		lineNo   int
+		nonBlank int
+	)
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		nonBlank++
+		if !json.Valid(line) {
+			issues = append(issues, Issue{
+				Path:     path,
+				Line:     lineNo,
+				Kind:     IssueMalformedLine,
+				Severity: SeverityError,
+				Message:  "line is not valid JSON",
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return issues, nonBlank, fmt.Errorf("scan session: %w", err)
+	}
+	return issues, nonBlank, nil
+}
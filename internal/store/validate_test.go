@@ -0,0 +1,98 @@
+package store
+
+import (
+	"agentlog/internal/codex"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateSessionsBrokenFixtures(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "broken-sessions")
+	parser := &codex.CodexParser{}
+
+	res, err := ValidateSessions(parser, ValidateOptions{Roots: []string{root}})
+	if err != nil {
+		t.Fatalf("ValidateSessions returned error: %v", err)
+	}
+
+	if res.FilesScanned != 6 {
+		t.Fatalf("expected 6 files scanned, got %d", res.FilesScanned)
+	}
+
+	byKind := map[IssueKind][]Issue{}
+	for _, issue := range res.Issues {
+		byKind[issue.Kind] = append(byKind[issue.Kind], issue)
+	}
+
+	expectIssue := func(kind IssueKind, file string) Issue {
+		t.Helper()
+		for _, issue := range byKind[kind] {
+			if filepath.Base(issue.Path) == file {
+				return issue
+			}
+		}
+		t.Fatalf("expected %s issue for %s, got %+v", kind, file, res.Issues)
+		return Issue{}
+	}
+
+	expectIssue(IssueMissingMeta, "no-meta.jsonl")
+	if issue := expectIssue(IssueMalformedLine, "malformed.jsonl"); issue.Line != 2 {
+		t.Fatalf("expected malformed line 2, got %d", issue.Line)
+	}
+	expectIssue(IssueNoEvents, "empty.jsonl")
+	expectIssue(IssueDuplicateID, "duplicate-b.jsonl")
+	if issue := expectIssue(IssueTimestampOrder, "out-of-order.jsonl"); issue.Severity != SeverityWarning {
+		t.Fatalf("expected timestamp order issue to be a warning, got %s", issue.Severity)
+	}
+
+	if res.ErrorCount() == 0 {
+		t.Fatalf("expected errors to be reported")
+	}
+}
+
+func TestValidateSessionsHealthy(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "sessions")
+	parser := &codex.CodexParser{}
+
+	res, err := ValidateSessions(parser, ValidateOptions{Roots: []string{root}})
+	if err != nil {
+		t.Fatalf("ValidateSessions returned error: %v", err)
+	}
+	if len(res.Issues) != 0 {
+		t.Fatalf("expected no issues, got %+v", res.Issues)
+	}
+}
+
+func TestValidateSessionsWalk(t *testing.T) {
+	parser := &codex.CodexParser{}
+
+	_, err := ValidateSessions(parser, ValidateOptions{Roots: []string{filepath.Join("..", "..", "testdata", "no-such-dir")}})
+	if !errors.Is(err, ErrSessionsDirNotFound) {
+		t.Fatalf("expected ErrSessionsDirNotFound for a missing root, got %v", err)
+	}
+
+	// Skipping one copy's directory leaves no duplicated id to report.
+	root := filepath.Join("..", "..", "testdata", "duplicate-sessions")
+	duplicates := func(opts ValidateOptions) int {
+		t.Helper()
+		opts.Roots = []string{root}
+		res, err := ValidateSessions(parser, opts)
+		if err != nil {
+			t.Fatalf("ValidateSessions returned error: %v", err)
+		}
+		n := 0
+		for _, issue := range res.Issues {
+			if issue.Kind == IssueDuplicateID {
+				n++
+			}
+		}
+		return n
+	}
+	if n := duplicates(ValidateOptions{}); n != 1 {
+		t.Fatalf("expected 1 duplicate id issue, got %d", n)
+	}
+	if n := duplicates(ValidateOptions{ExcludeDirs: []string{"2025/11/07"}}); n != 0 {
+		t.Fatalf("expected --exclude-dir to skip the copy, got %d duplicate issues", n)
+	}
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// walkSessions walks root like filepath.WalkDir. With followSymlinks it also
+// descends into symlinked directories, root included, and reports symlinked
+// files as the files they point to. Paths stay under root as given. Each
+// directory is walked once however many links reach it, which also stops
+// symlink cycles.
+func walkSessions(root string, followSymlinks bool, fn fs.WalkDirFunc) error {
+	if !followSymlinks {
+		return filepath.WalkDir(root, fn)
+	}
+	visited := make(map[string]struct{})
+	return walkResolved(root, visited, fn)
+}
+
+// walkResolved walks the directory dir resolves to, presenting paths under
+// dir and recording every directory walked in visited by its real path.
+func walkResolved(dir string, visited map[string]struct{}, fn fs.WalkDirFunc) error {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fn(dir, nil, err)
+	}
+	if _, seen := visited[real]; seen {
+		return nil
+	}
+	return filepath.WalkDir(real, func(path string, d fs.DirEntry, walkErr error) error {
+		rel, err := filepath.Rel(real, path)
+		if err != nil {
+			return err
+		}
+		shown := filepath.Join(dir, rel)
+		if walkErr != nil {
+			return fn(shown, d, walkErr)
+		}
+		if d.IsDir() {
+			if _, seen := visited[path]; seen {
+				return filepath.SkipDir
+			}
+			visited[path] = struct{}{}
+			return fn(shown, d, nil)
+		}
+		if d.Type()&fs.ModeSymlink == 0 {
+			return fn(shown, d, nil)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fn(shown, d, err)
+		}
+		if info.IsDir() {
+			return walkResolved(shown, visited, fn)
+		}
+		return fn(shown, fs.FileInfoToDirEntry(info), nil)
+	})
+}
@@ -0,0 +1,284 @@
+// Package tui provides minimal interactive terminal helpers.
+package tui
+
+import (
+	"agentlog/internal/format"
+	"agentlog/internal/model"
+	"agentlog/internal/util"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// ErrCancelled is returned when the user leaves the picker without choosing.
+var ErrCancelled = errors.New("selection cancelled")
+
+// candidateTimeLayout is used when no explicit --time-format is given.
+const candidateTimeLayout = "2006-01-02 15:04"
+
+// Candidate is a session offered by the picker.
+type Candidate struct {
+	ID        string
+	Path      string
+	CWD       string
+	StartedAt time.Time
+	Summary   string
+}
+
+// CandidatesFromSummaries converts listed sessions into picker candidates,
+// preserving their order.
+func CandidatesFromSummaries(items []model.SessionSummaryProvider) []Candidate {
+	candidates := make([]Candidate, 0, len(items))
+	for _, item := range items {
+		candidates = append(candidates, Candidate{
+			ID:        item.GetID(),
+			Path:      item.GetPath(),
+			CWD:       item.GetCWD(),
+			StartedAt: item.GetStartedAt(),
+			Summary:   item.GetSummary(),
+		})
+	}
+	return candidates
+}
+
+// FormatCandidate renders a candidate as a single line of at most width
+// display columns: start time, session ID, then the collapsed summary.
+func FormatCandidate(c Candidate, width int, timeFormat format.TimeFormatter) string {
+	ts := "-"
+	if !c.StartedAt.IsZero() {
+		ts = timeFormat.WithDefaultLayout(candidateTimeLayout).Format(c.StartedAt)
+	}
+	summary := strings.Join(strings.Fields(c.Summary), " ")
+	line := fmt.Sprintf("%s  %s  %s", ts, c.ID, summary)
+	line = strings.TrimRight(line, " ")
+	if width > 0 && runewidth.StringWidth(line) > width {
+		line = runewidth.Truncate(line, width, util.Ellipsis)
+	}
+	return line
+}
+
+// FilterCandidates returns the candidates whose ID, CWD, or summary contains
+// every character of query in order (case-insensitive fuzzy match).
+func FilterCandidates(candidates []Candidate, query string) []Candidate {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return candidates
+	}
+	matched := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		haystack := strings.ToLower(c.ID + " " + c.CWD + " " + c.Summary)
+		if fuzzyContains(haystack, query) {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+func fuzzyContains(haystack, needle string) bool {
+	for _, r := range needle {
+		idx := strings.IndexRune(haystack, r)
+		if idx < 0 {
+			return false
+		}
+		haystack = haystack[idx+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+// PickOptions configures an interactive selection.
+type PickOptions struct {
+	In   *os.File
+	Out  *os.File
+	Time format.TimeFormatter
+}
+
+// IsInteractive reports whether both in and out are terminals.
+func IsInteractive(in, out *os.File) bool {
+	if in == nil || out == nil {
+		return false
+	}
+	return term.IsTerminal(int(in.Fd())) && term.IsTerminal(int(out.Fd()))
+}
+
+// Pick shows candidates and lets the user narrow them by typing and choose
+// one with the arrow keys and Enter. Esc or Ctrl-C cancels.
+func Pick(candidates []Candidate, opts PickOptions) (Candidate, error) {
+	if len(candidates) == 0 {
+		return Candidate{}, errors.New("no sessions to choose from")
+	}
+	if !IsInteractive(opts.In, opts.Out) {
+		return Candidate{}, errors.New("interactive picker requires a terminal")
+	}
+
+	state, err := term.MakeRaw(int(opts.In.Fd()))
+	if err != nil {
+		return Candidate{}, fmt.Errorf("enable raw mode: %w", err)
+	}
+	defer term.Restore(int(opts.In.Fd()), state) //nolint:errcheck
+
+	p := &picker{
+		all:     candidates,
+		visible: candidates,
+		out:     opts.Out,
+		time:    opts.Time,
+	}
+	p.width, p.rows = pickerSize(opts.Out)
+
+	buf := make([]byte, 64)
+	for {
+		p.render()
+		n, err := opts.In.Read(buf)
+		if err != nil {
+			p.clear()
+			return Candidate{}, fmt.Errorf("read input: %w", err)
+		}
+		done, cancelled := p.handle(buf[:n])
+		if cancelled {
+			p.clear()
+			return Candidate{}, ErrCancelled
+		}
+		if done && len(p.visible) > 0 {
+			p.clear()
+			return p.visible[p.cursor], nil
+		}
+	}
+}
+
+type picker struct {
+	all      []Candidate
+	visible  []Candidate
+	query    []rune
+	cursor   int
+	offset   int
+	out      io.Writer
+	time     format.TimeFormatter
+	width    int
+	rows     int
+	rendered int
+}
+
+func pickerSize(out *os.File) (width, rows int) {
+	width, height := 80, 24
+	if w, h, err := term.GetSize(int(out.Fd())); err == nil {
+		if w > 0 {
+			width = w
+		}
+		if h > 0 {
+			height = h
+		}
+	}
+	rows = height - 2
+	if rows > 15 {
+		rows = 15
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return width, rows
+}
+
+// handle applies a chunk of input and reports whether the user accepted the
+// current selection or cancelled.
+func (p *picker) handle(input []byte) (done, cancelled bool) {
+	if len(input) >= 3 && input[0] == 0x1b && input[1] == '[' {
+		switch input[2] {
+		case 'A':
+			p.move(-1)
+		case 'B':
+			p.move(1)
+		}
+		return false, false
+	}
+
+	for len(input) > 0 {
+		r, size := utf8.DecodeRune(input)
+		input = input[size:]
+		switch r {
+		case 0x03, 0x1b: // Ctrl-C, Esc
+			return false, true
+		case '\r', '\n':
+			return true, false
+		case 0x10: // Ctrl-P
+			p.move(-1)
+		case 0x0e: // Ctrl-N
+			p.move(1)
+		case 0x7f, 0x08: // Backspace
+			if len(p.query) > 0 {
+				p.query = p.query[:len(p.query)-1]
+				p.refilter()
+			}
+		default:
+			if unicode.IsPrint(r) {
+				p.query = append(p.query, r)
+				p.refilter()
+			}
+		}
+	}
+	return false, false
+}
+
+func (p *picker) move(delta int) {
+	if len(p.visible) == 0 {
+		return
+	}
+	p.cursor += delta
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+	if p.cursor >= len(p.visible) {
+		p.cursor = len(p.visible) - 1
+	}
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+p.rows {
+		p.offset = p.cursor - p.rows + 1
+	}
+}
+
+func (p *picker) refilter() {
+	p.visible = FilterCandidates(p.all, string(p.query))
+	p.cursor = 0
+	p.offset = 0
+}
+
+func (p *picker) clear() {
+	if p.rendered > 0 {
+		fmt.Fprintf(p.out, "\x1b[%dA", p.rendered) //nolint:errcheck
+	}
+	fmt.Fprint(p.out, "\r\x1b[J") //nolint:errcheck
+	p.rendered = 0
+}
+
+func (p *picker) render() {
+	p.clear()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "> %s  (%d/%d)\r\n", string(p.query), len(p.visible), len(p.all))
+	lines := 1
+
+	end := p.offset + p.rows
+	if end > len(p.visible) {
+		end = len(p.visible)
+	}
+	for i := p.offset; i < end; i++ {
+		line := FormatCandidate(p.visible[i], p.width-2, p.time)
+		if i == p.cursor {
+			b.WriteString("\x1b[7m> " + line + "\x1b[0m\r\n")
+		} else {
+			b.WriteString("  " + line + "\r\n")
+		}
+		lines++
+	}
+
+	fmt.Fprint(p.out, b.String()) //nolint:errcheck
+	p.rendered = lines
+}
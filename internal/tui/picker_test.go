@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"agentlog/internal/format"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleCandidates() []Candidate {
+	return []Candidate{
+		{
+			ID:        "session-a",
+			CWD:       "/tmp/project",
+			StartedAt: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC),
+			Summary:   "Fix the flaky login test",
+		},
+		{
+			ID:        "session-b",
+			CWD:       "/tmp/other",
+			StartedAt: time.Date(2025, 10, 2, 9, 30, 0, 0, time.UTC),
+			Summary:   "Write a fibonacci function",
+		},
+	}
+}
+
+func TestFormatCandidate(t *testing.T) {
+	c := sampleCandidates()[0]
+	c.Summary = "Fix the\nflaky   login test"
+
+	got := FormatCandidate(c, 0, format.TimeFormatter{})
+	want := "2025-10-01 12:00  session-a  Fix the flaky login test"
+	if got != want {
+		t.Fatalf("FormatCandidate = %q, want %q", got, want)
+	}
+
+	clipped := FormatCandidate(c, 30, format.TimeFormatter{})
+	if !strings.HasSuffix(clipped, "…") || len([]rune(clipped)) != 30 {
+		t.Fatalf("expected candidate clipped to 30 columns, got %q", clipped)
+	}
+}
+
+func TestFormatCandidateTimeFormat(t *testing.T) {
+	tf, err := format.NewTimeFormatter("rfc3339", false, false)
+	if err != nil {
+		t.Fatalf("NewTimeFormatter returned error: %v", err)
+	}
+	got := FormatCandidate(sampleCandidates()[1], 0, tf)
+	if !strings.HasPrefix(got, "2025-10-02T09:30:00Z  session-b") {
+		t.Fatalf("unexpected candidate line: %q", got)
+	}
+}
+
+func TestFilterCandidates(t *testing.T) {
+	candidates := sampleCandidates()
+
+	if got := FilterCandidates(candidates, ""); len(got) != 2 {
+		t.Fatalf("empty query should keep all candidates, got %d", len(got))
+	}
+
+	got := FilterCandidates(candidates, "fibfn")
+	if len(got) != 1 || got[0].ID != "session-b" {
+		t.Fatalf("expected fuzzy match on session-b, got %+v", got)
+	}
+
+	got = FilterCandidates(candidates, "PROJECT")
+	if len(got) != 1 || got[0].ID != "session-a" {
+		t.Fatalf("expected case-insensitive cwd match on session-a, got %+v", got)
+	}
+}
+
+func TestPickerHandleKeys(t *testing.T) {
+	p := &picker{all: sampleCandidates(), visible: sampleCandidates(), rows: 10}
+
+	p.handle([]byte("\x1b[B"))
+	if p.cursor != 1 {
+		t.Fatalf("down arrow should move cursor, got %d", p.cursor)
+	}
+
+	p.handle([]byte("login"))
+	if len(p.visible) != 1 || p.cursor != 0 {
+		t.Fatalf("typing should filter and reset cursor, got %d visible cursor %d", len(p.visible), p.cursor)
+	}
+
+	if done, _ := p.handle([]byte("\r")); !done {
+		t.Fatal("enter should accept the selection")
+	}
+	if _, cancelled := p.handle([]byte{0x1b}); !cancelled {
+		t.Fatal("escape should cancel")
+	}
+}
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+// TerminalWidth returns the width of the terminal out is attached to, or
+// the COLUMNS environment variable when out is nil or not a terminal. It
+// returns 0 when neither is known.
+func TerminalWidth(out *os.File) int {
+	if out != nil {
+		if w, _, err := term.GetSize(int(out.Fd())); err == nil && w > 0 {
+			return w
+		}
+	}
+	if colsStr := os.Getenv("COLUMNS"); colsStr != "" {
+		if v, err := strconv.Atoi(colsStr); err == nil && v > 0 {
+			return v
+		}
+	}
+	return 0
+}
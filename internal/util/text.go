@@ -0,0 +1,30 @@
+// Package util holds small helpers shared by the CLI and the internal
+// packages, so that fixes to them land in one place.
+package util
+
+import "strings"
+
+// Ellipsis marks text that was clipped.
+const Ellipsis = "…"
+
+// CollapseWhitespace trims text and replaces each run of whitespace,
+// including line breaks, with a single space.
+func CollapseWhitespace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// ClipRunes shortens text to at most maxLen runes, ending it with Ellipsis
+// when anything was cut. A maxLen of zero or less yields "".
+func ClipRunes(text string, maxLen int) string {
+	if maxLen <= 0 {
+		return ""
+	}
+	if len(text) <= maxLen {
+		return text
+	}
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	return string(runes[:maxLen-1]) + Ellipsis
+}
@@ -0,0 +1,12 @@
+package util
+
+import "time"
+
+// DurationSeconds returns the whole seconds from start to end, or 0 when
+// either is unset or end comes before start.
+func DurationSeconds(start, end time.Time) int {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return int(end.Sub(start).Seconds())
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClipRunes(t *testing.T) {
+	tests := []struct {
+		text   string
+		maxLen int
+		want   string
+	}{
+		{"abcdef", 3, "ab…"},
+		{"short", 10, "short"},
+		{"exact", 5, "exact"},
+		{"abcdef", 1, "…"},
+		{"abcdef", 0, ""},
+		{"日本語のテキスト", 4, "日本語…"},
+		{"日本語", 3, "日本語"},
+	}
+	for _, tt := range tests {
+		if got := ClipRunes(tt.text, tt.maxLen); got != tt.want {
+			t.Fatalf("ClipRunes(%q, %d) = %q, want %q", tt.text, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+func TestCollapseWhitespace(t *testing.T) {
+	tests := map[string]string{
+		"  line one\n\nline\t two  ": "line one line two",
+		"":                           "",
+		" \n\t ":                     "",
+		"single":                     "single",
+	}
+	for text, want := range tests {
+		if got := CollapseWhitespace(text); got != want {
+			t.Fatalf("CollapseWhitespace(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestDurationSeconds(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       int
+	}{
+		{"ordered", start, start.Add(90*time.Second + 500*time.Millisecond), 90},
+		{"same instant", start, start, 0},
+		{"end before start", start, start.Add(-time.Minute), 0},
+		{"unset start", time.Time{}, start, 0},
+		{"unset end", start, time.Time{}, 0},
+	}
+	for _, tt := range tests {
+		if got := DurationSeconds(tt.start, tt.end); got != tt.want {
+			t.Fatalf("%s: DurationSeconds = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"agentlog/internal/format"
 	"agentlog/internal/model"
 	"fmt"
+	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 	"unicode"
@@ -14,9 +16,136 @@ import (
 	"github.com/mattn/go-runewidth"
 )
 
-func renderChatTranscript(events []model.EventProvider, width int, useColor bool) []string {
-	if width <= 0 {
-		width = 80
+// chatTimeLayout is the compact timestamp layout used in chat bubble headers
+// unless --time-format overrides it.
+const chatTimeLayout = "Jan 02 15:04"
+
+// chatCharset holds the glyphs used to draw chat bubbles.
+type chatCharset struct {
+	TopLeft     string
+	TopRight    string
+	BottomLeft  string
+	BottomRight string
+	Horizontal  string
+	Vertical    string
+	HeaderSep   string // between the role label and the timestamp
+}
+
+var (
+	unicodeCharset = chatCharset{
+		TopLeft: "╭", TopRight: "╮", BottomLeft: "╰", BottomRight: "╯",
+		Horizontal: "─", Vertical: "|", HeaderSep: "·",
+	}
+	asciiCharset = chatCharset{
+		TopLeft: "+", TopRight: "+", BottomLeft: "+", BottomRight: "+",
+		Horizontal: "-", Vertical: "|", HeaderSep: "-",
+	}
+)
+
+// chatOptions controls chat transcript rendering.
+type chatOptions struct {
+	Width          int
+	WrapMode       format.WrapMode
+	MaxArgBytes    int
+	MaxOutputBytes int
+	UseColor       bool
+	Time           format.TimeFormatter
+	Charset        chatCharset // zero value means unicodeCharset
+	RoleLabels     RoleLabels
+	KeepControl    bool
+	Models         *modelTracker // marks model switches between bubbles; nil shows none
+	Alignments     RoleAlignments
+}
+
+// RoleLabels maps lowercase roles, such as "user" or "assistant", to the
+// text shown for them in event headers. Roles without an entry keep their
+// own name. Alignment and color always follow the role, not the label.
+type RoleLabels map[string]string
+
+// labelRoles are the roles RoleLabels may rename.
+var labelRoles = []string{"user", "assistant", "tool", "system"}
+
+// ParseRoleLabels validates role=label pairs, lowercasing the roles.
+func ParseRoleLabels(pairs map[string]string) (RoleLabels, error) {
+	labels := make(RoleLabels, len(pairs))
+	for role, label := range pairs {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if !slices.Contains(labelRoles, role) {
+			return nil, fmt.Errorf("unknown role %q (expected %s)", role, strings.Join(labelRoles, ", "))
+		}
+		if strings.TrimSpace(label) == "" {
+			return nil, fmt.Errorf("empty label for role %q", role)
+		}
+		labels[role] = label
+	}
+	return labels, nil
+}
+
+// RoleAlignments maps lowercase roles to the side their chat bubbles are
+// drawn on: "left", "right", or "center". Roles without an entry keep the
+// default, with the user on the right and everything else on the left.
+type RoleAlignments map[string]string
+
+// chatAlignments are the values RoleAlignments accepts.
+var chatAlignments = []string{"left", "right", "center"}
+
+// ParseRoleAlignments validates role=side pairs, lowercasing both.
+func ParseRoleAlignments(pairs map[string]string) (RoleAlignments, error) {
+	alignments := make(RoleAlignments, len(pairs))
+	for role, align := range pairs {
+		role = strings.ToLower(strings.TrimSpace(role))
+		align = strings.ToLower(strings.TrimSpace(align))
+		if !slices.Contains(labelRoles, role) {
+			return nil, fmt.Errorf("unknown role %q (expected %s)", role, strings.Join(labelRoles, ", "))
+		}
+		if !slices.Contains(chatAlignments, align) {
+			return nil, fmt.Errorf("invalid alignment %q for role %q (expected %s)", align, role, strings.Join(chatAlignments, ", "))
+		}
+		alignments[role] = align
+	}
+	return alignments, nil
+}
+
+// text returns the label of role for the text and content formats: the
+// custom label, or the lowercase role.
+func (l RoleLabels) text(role string) string {
+	role = strings.ToLower(role)
+	if label, ok := l[role]; ok {
+		return label
+	}
+	return role
+}
+
+// title returns the label of role for chat headers and the legend: the
+// custom label as given, or the role in title case.
+func (l RoleLabels) title(role string) string {
+	if label, ok := l[strings.ToLower(role)]; ok {
+		return label
+	}
+	return titleCase(role)
+}
+
+// terminalSupportsUnicode reports whether box-drawing glyphs are safe to
+// emit: TERM must not be "dumb" and an explicitly set locale must be UTF-8.
+func terminalSupportsUnicode() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(key); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return true
+}
+
+func renderChatTranscript(events []model.EventProvider, opts chatOptions) []string {
+	if opts.Width <= 0 {
+		opts.Width = 80
+	}
+	if opts.Charset == (chatCharset{}) {
+		opts.Charset = unicodeCharset
 	}
 	padding := 2
 
@@ -25,14 +154,48 @@ func renderChatTranscript(events []model.EventProvider, width int, useColor bool
 		if idx > 0 {
 			lines = append(lines, "")
 		}
-		lines = append(lines, renderChatBubble(event, width, padding, useColor)...)
+		if name, ok := opts.Models.changeBefore(event); ok {
+			lines = append(lines, modelDivider(name, opts.Charset.Horizontal), "")
+		}
+		lines = append(lines, renderChatBubble(event, padding, opts)...)
 	}
 	return lines
 }
 
-func renderChatBubble(event model.EventProvider, totalWidth int, padding int, useColor bool) []string {
-	displayRole := strings.ToLower(roleLabel(event))
-	bodyLines := format.RenderEventLines(event, 0)
+// legendRoles lists the roles explained by the chat legend, in order.
+var legendRoles = []string{"user", "assistant", "tool"}
+
+// chatLegend returns the lines shown above the chat bubbles: the session ID
+// and start time, then which color and side each role is drawn with.
+func chatLegend(meta model.SessionMetaProvider, opts chatOptions) []string {
+	started := "-"
+	if ts := meta.GetStartedAt(); !ts.IsZero() {
+		started = opts.Time.WithDefaultLayout(chatTimeLayout).Format(ts)
+	}
+	sep := opts.Charset.HeaderSep
+	if sep == "" {
+		sep = unicodeCharset.HeaderSep
+	}
+	header := fmt.Sprintf("Session %s %s %s", meta.GetID(), sep, started)
+
+	entries := make([]string, 0, len(legendRoles))
+	for _, role := range legendRoles {
+		label := opts.RoleLabels.title(role)
+		if opts.UseColor {
+			label = colorize(roleColor(role), label)
+		}
+		entries = append(entries, fmt.Sprintf("%s (%s)", label, alignmentForRole(role, opts.Alignments)))
+	}
+	return []string{header, "Legend: " + strings.Join(entries, " "+sep+" "), ""}
+}
+
+func renderChatBubble(event model.EventProvider, padding int, opts chatOptions) []string {
+	totalWidth, useColor, charset := opts.Width, opts.UseColor, opts.Charset
+	bodyLines := format.RenderEventLines(event, format.RenderOptions{
+		MaxArgBytes:    opts.MaxArgBytes,
+		MaxOutputBytes: opts.MaxOutputBytes,
+		KeepControl:    opts.KeepControl,
+	})
 
 	maxContentWidth := totalWidth - padding*2 - 10
 	if maxContentWidth < 20 {
@@ -46,8 +209,8 @@ func renderChatBubble(event model.EventProvider, totalWidth int, padding int, us
 		}
 	}
 
-	headerText, headerLabel, headerTime := chatHeader(displayRole, event.GetTimestamp())
-	content := wrapLines(append([]string{headerText}, bodyLines...), maxContentWidth)
+	headerText, headerLabel, headerTime := chatHeader(roleLabel(event, opts.RoleLabels), event.GetTimestamp(), opts.Time, charset.HeaderSep)
+	content := wrapLines(append([]string{headerText}, bodyLines...), maxContentWidth, opts.WrapMode)
 	maxLineWidth := contentMaxWidth(content)
 
 	bubbleWidth := maxLineWidth
@@ -57,29 +220,31 @@ func renderChatBubble(event model.EventProvider, totalWidth int, padding int, us
 
 	// Use raw role/kind for alignment and color, not the display label
 	rawRole := extractRawRole(event)
-	align := alignmentForRole(rawRole)
+	align := alignmentForRole(rawRole, opts.Alignments)
 	leftPad := computeLeftPad(totalWidth, bubbleWidth, padding, align)
 
 	if useColor && len(content) > 0 {
-		colored := fmt.Sprintf("%s · %s",
+		colored := fmt.Sprintf("%s %s %s",
 			colorize(roleColor(rawRole), headerLabel),
+			charset.HeaderSep,
 			colorize(ansiTimestamp, headerTime),
 		)
 		content[0] = strings.Replace(content[0], headerText, colored, 1)
 	}
 
-	top := fmt.Sprintf("%s╭%s╮", strings.Repeat(" ", leftPad), strings.Repeat("─", bubbleWidth+2))
-	bottom := fmt.Sprintf("%s╰%s╯", strings.Repeat(" ", leftPad), strings.Repeat("─", bubbleWidth+2))
+	border := strings.Repeat(charset.Horizontal, bubbleWidth+2)
+	top := strings.Repeat(" ", leftPad) + charset.TopLeft + border + charset.TopRight
+	bottom := strings.Repeat(" ", leftPad) + charset.BottomLeft + border + charset.BottomRight
 
 	result := []string{top}
 	for _, line := range content {
-		result = append(result, renderBubbleBodyLine(line, bubbleWidth, leftPad, useColor))
+		result = append(result, renderBubbleBodyLine(line, bubbleWidth, leftPad, useColor, charset.Vertical))
 	}
 	result = append(result, bottom)
 	return result
 }
 
-func renderBubbleBodyLine(line string, bubbleWidth int, leftPad int, useColor bool) string {
+func renderBubbleBodyLine(line string, bubbleWidth int, leftPad int, useColor bool, vertical string) string {
 	displayLen := visibleWidth(line)
 	if displayLen > bubbleWidth {
 		line = truncateToWidth(line, bubbleWidth)
@@ -87,7 +252,7 @@ func renderBubbleBodyLine(line string, bubbleWidth int, leftPad int, useColor bo
 	}
 	paddingRight := bubbleWidth - displayLen
 
-	border := "|"
+	border := vertical
 	if useColor {
 		border = colorize(ansiSeparator, border)
 	}
@@ -95,22 +260,23 @@ func renderBubbleBodyLine(line string, bubbleWidth int, leftPad int, useColor bo
 	return fmt.Sprintf("%s%s %s%s %s", strings.Repeat(" ", leftPad), border, line, strings.Repeat(" ", paddingRight), border)
 }
 
-func chatHeader(role string, ts time.Time) (header string, label string, timeText string) {
-	label = titleCase(role)
+// chatHeader builds a bubble header from the display label of its role.
+func chatHeader(roleText string, ts time.Time, timeFormat format.TimeFormatter, sep string) (header string, label string, timeText string) {
+	label = roleText
 	if label == "" {
 		label = "Event"
 	}
 	timeText = "-"
 	if !ts.IsZero() {
-		timeText = ts.Format("Jan 02 15:04")
+		timeText = timeFormat.WithDefaultLayout(chatTimeLayout).Format(ts)
 	}
 
-	return fmt.Sprintf("%s · %s", label, timeText), label, timeText
+	return fmt.Sprintf("%s %s %s", label, sep, timeText), label, timeText
 }
 
-func roleLabel(event model.EventProvider) string {
-	// Use the normalized role from the interface
-	return event.GetRole()
+// roleLabel returns the chat header label of event's normalized role.
+func roleLabel(event model.EventProvider, labels RoleLabels) string {
+	return labels.title(event.GetRole())
 }
 
 // extractRawRole returns the base role for alignment and color purposes.
@@ -118,8 +284,13 @@ func extractRawRole(event model.EventProvider) string {
 	return event.GetRole()
 }
 
-func alignmentForRole(role string) string {
+// alignmentForRole returns the side role's bubbles are drawn on: the custom
+// alignment, if any, or the default.
+func alignmentForRole(role string, custom RoleAlignments) string {
 	role = strings.ToLower(role)
+	if align, ok := custom[role]; ok {
+		return align
+	}
 	switch role {
 	case "assistant", "system", "tool":
 		return "left"
@@ -159,22 +330,55 @@ func computeLeftPad(totalWidth, bubbleWidth, padding int, align string) int {
 	}
 }
 
-func wrapLines(lines []string, width int) []string {
+func wrapLines(lines []string, width int, mode format.WrapMode) []string {
 	var out []string
 	for _, line := range lines {
-		out = append(out, wrapText(line, width)...)
+		out = append(out, wrapText(line, width, mode)...)
 	}
 	return out
 }
 
-func wrapText(text string, width int) []string {
-	if width <= 0 {
+// wrapText breaks text into lines no wider than width. Word mode breaks at
+// spaces and hard-breaks only words that cannot fit a bubble line; none leaves
+// the line for the bubble to truncate.
+func wrapText(text string, width int, mode format.WrapMode) []string {
+	if width <= 0 || mode == format.WrapNone {
 		return []string{text}
 	}
 	text = strings.TrimRight(text, " ")
 	if text == "" {
 		return []string{""}
 	}
+	if mode == format.WrapChar {
+		return wrapRunes(text, width)
+	}
+
+	words := strings.Split(text, " ")
+	out := make([]string, 0, 1)
+	current := words[0]
+	if runewidth.StringWidth(current) > width {
+		chunks := wrapRunes(current, width)
+		out = append(out, chunks[:len(chunks)-1]...)
+		current = chunks[len(chunks)-1]
+	}
+	for _, word := range words[1:] {
+		if runewidth.StringWidth(current)+1+runewidth.StringWidth(word) <= width {
+			current += " " + word
+			continue
+		}
+		out = append(out, current)
+		current = word
+		if runewidth.StringWidth(word) > width {
+			chunks := wrapRunes(word, width)
+			out = append(out, chunks[:len(chunks)-1]...)
+			current = chunks[len(chunks)-1]
+		}
+	}
+	return append(out, current)
+}
+
+// wrapRunes hard-breaks text at the width boundary.
+func wrapRunes(text string, width int) []string {
 	var out []string
 	var current strings.Builder
 	currentWidth := 0
@@ -0,0 +1,484 @@
+package view
+
+import (
+	"agentlog/internal/model"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FilterExpr is a compiled --filter-expr expression. It is a boolean
+// expression over the fields of an event, for example
+//
+//	role == user && contains(text, "error")
+//
+// Fields are role, type (the payload type), text (the text of the content
+// blocks), and timestamp. Comparisons use ==, !=, <, <=, >, and >=; they are
+// combined with &&, ||, !, and parentheses. Strings are double-quoted, or
+// written bare when they are a single word that is not a field name.
+// timestamp compares with RFC3339 timestamps and YYYY-MM-DD dates (in UTC).
+// The functions contains, startsWith, endsWith, and matches (a regular
+// expression) take a field and a string.
+type FilterExpr struct {
+	root exprNode
+}
+
+// ParseFilterExpr compiles src, reporting syntax and type errors with the
+// byte offset they were found at.
+func ParseFilterExpr(src string) (*FilterExpr, error) {
+	tokens, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s at offset %d", tok, tok.pos)
+	}
+	if root.kind() != kindBool {
+		return nil, fmt.Errorf("expression is a %s, not a condition", root.kind())
+	}
+	return &FilterExpr{root: root}, nil
+}
+
+// Match reports whether event satisfies the expression. A nil expression
+// matches every event.
+func (f *FilterExpr) Match(event model.EventProvider) bool {
+	if f == nil {
+		return true
+	}
+	return f.root.eval(event).(bool)
+}
+
+type exprKind int
+
+const (
+	kindBool exprKind = iota
+	kindString
+	kindTime
+)
+
+func (k exprKind) String() string {
+	switch k {
+	case kindString:
+		return "string"
+	case kindTime:
+		return "timestamp"
+	}
+	return "condition"
+}
+
+// exprNode is a node of a compiled expression. eval returns a bool, string,
+// or time.Time according to kind.
+type exprNode interface {
+	kind() exprKind
+	eval(event model.EventProvider) any
+}
+
+// exprFields maps field names to their kind.
+var exprFields = map[string]exprKind{
+	"role":      kindString,
+	"type":      kindString,
+	"text":      kindString,
+	"timestamp": kindTime,
+}
+
+type fieldNode struct{ name string }
+
+func (n fieldNode) kind() exprKind { return exprFields[n.name] }
+
+func (n fieldNode) eval(event model.EventProvider) any {
+	switch n.name {
+	case "role":
+		return event.GetRole()
+	case "type":
+		return event.GetPayloadType()
+	case "timestamp":
+		return event.GetTimestamp()
+	}
+	var parts []string
+	for _, block := range event.GetContent() {
+		if block.Text != "" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+type literalNode struct {
+	value any
+	k     exprKind
+}
+
+func (n literalNode) kind() exprKind               { return n.k }
+func (n literalNode) eval(model.EventProvider) any { return n.value }
+
+type notNode struct{ x exprNode }
+
+func (n notNode) kind() exprKind { return kindBool }
+
+func (n notNode) eval(event model.EventProvider) any {
+	return !n.x.eval(event).(bool)
+}
+
+type logicNode struct {
+	and         bool
+	left, right exprNode
+}
+
+func (n logicNode) kind() exprKind { return kindBool }
+
+func (n logicNode) eval(event model.EventProvider) any {
+	left := n.left.eval(event).(bool)
+	if n.and {
+		return left && n.right.eval(event).(bool)
+	}
+	return left || n.right.eval(event).(bool)
+}
+
+type compareNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n compareNode) kind() exprKind { return kindBool }
+
+func (n compareNode) eval(event model.EventProvider) any {
+	var cmp int
+	switch left := n.left.eval(event).(type) {
+	case time.Time:
+		cmp = left.Compare(n.right.eval(event).(time.Time))
+	case string:
+		cmp = strings.Compare(left, n.right.eval(event).(string))
+	}
+	switch n.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0
+}
+
+type callNode struct {
+	match func(value string) bool
+	arg   exprNode
+}
+
+func (n callNode) kind() exprKind { return kindBool }
+
+func (n callNode) eval(event model.EventProvider) any {
+	return n.match(n.arg.eval(event).(string))
+}
+
+// exprFuncs lists the functions in the order they are suggested in errors.
+var exprFuncs = []string{"contains", "startsWith", "endsWith", "matches"}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokOp
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string // identifier, operator, or unquoted string
+	pos  int
+}
+
+func (t exprToken) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// exprOps lists the operators, two-character ones first so they are matched
+// before their prefixes.
+var exprOps = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", ","}
+
+func lexExpr(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(src) && src[end] != '"' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			text, err := strconv.Unquote(src[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d: %w", i, err)
+			}
+			tokens = append(tokens, exprToken{kind: tokString, text: text, pos: i})
+			i = end + 1
+		case isIdentRune(c):
+			end := i
+			for end < len(src) && isIdentRune(rune(src[end])) {
+				end++
+			}
+			tokens = append(tokens, exprToken{kind: tokIdent, text: src[i:end], pos: i})
+			i = end
+		default:
+			op := ""
+			for _, candidate := range exprOps {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", src[i], i)
+			}
+			tokens = append(tokens, exprToken{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, exprToken{kind: tokEOF, pos: len(src)}), nil
+}
+
+// isIdentRune reports whether c can appear in a field name, function name,
+// or bare word. Bare words may contain dashes, dots, and colons, so dates
+// and timestamps such as 2025-01-15 need no quotes.
+func isIdentRune(c rune) bool {
+	return c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("_-.:", c))
+}
+
+type exprParser struct {
+	tokens []exprToken
+	next   int
+}
+
+func (p *exprParser) peek() exprToken { return p.tokens[p.next] }
+
+func (p *exprParser) advance() exprToken {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+// acceptOp consumes the next token if it is the operator op.
+func (p *exprParser) acceptOp(op string) bool {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == op {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expectOp(op string) error {
+	if p.acceptOp(op) {
+		return nil
+	}
+	tok := p.peek()
+	return fmt.Errorf("expected %q at offset %d, found %s", op, tok.pos, tok)
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseLogic("||", p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseLogic("&&", p.parseUnary)
+}
+
+// parseLogic parses operands joined by op, which binds left to right.
+func (p *exprParser) parseLogic(op string, operand func() (exprNode, error)) (exprNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		opPos := p.peek().pos
+		if !p.acceptOp(op) {
+			return left, nil
+		}
+		if left.kind() != kindBool {
+			return nil, fmt.Errorf("left side of %s at offset %d is a %s, not a condition", op, opPos, left.kind())
+		}
+		pos := p.peek().pos
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if right.kind() != kindBool {
+			return nil, fmt.Errorf("right side of %s at offset %d is a %s, not a condition", op, pos, right.kind())
+		}
+		left = logicNode{and: op == "&&", left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	pos := p.peek().pos
+	if !p.acceptOp("!") {
+		return p.parseComparison()
+	}
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if x.kind() != kindBool {
+		return nil, fmt.Errorf("! at offset %d applies to a %s, not a condition", pos, x.kind())
+	}
+	return notNode{x: x}, nil
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind != tokOp || !slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, tok.text) {
+		return left, nil
+	}
+	p.advance()
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	left, right, err = unifyOperands(left, right)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compare at offset %d: %w", tok.pos, err)
+	}
+	return compareNode{op: tok.text, left: left, right: right}, nil
+}
+
+// unifyOperands checks that a comparison's operands have the same kind,
+// converting a string literal compared with a timestamp into a time.
+func unifyOperands(left, right exprNode) (exprNode, exprNode, error) {
+	var err error
+	if left.kind() == kindTime {
+		right, err = timeOperand(right)
+	} else if right.kind() == kindTime {
+		left, err = timeOperand(left)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if left.kind() != right.kind() || left.kind() == kindBool {
+		return nil, nil, fmt.Errorf("%s with %s", left.kind(), right.kind())
+	}
+	return left, right, nil
+}
+
+// timeOperand converts a string literal to a timestamp literal.
+func timeOperand(n exprNode) (exprNode, error) {
+	lit, ok := n.(literalNode)
+	if !ok || lit.k != kindString {
+		return n, nil
+	}
+	text := lit.value.(string)
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if ts, err := time.Parse(layout, text); err == nil {
+			return literalNode{value: ts, k: kindTime}, nil
+		}
+	}
+	return nil, fmt.Errorf("%q is not an RFC3339 timestamp or YYYY-MM-DD date", text)
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokString:
+		return literalNode{value: tok.text, k: kindString}, nil
+	case tokIdent:
+		if p.acceptOp("(") {
+			return p.parseCall(tok)
+		}
+		if _, ok := exprFields[tok.text]; ok {
+			return fieldNode{name: tok.text}, nil
+		}
+		return literalNode{value: tok.text, k: kindString}, nil
+	case tokOp:
+		if tok.text == "(" {
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s at offset %d", tok, tok.pos)
+}
+
+// parseCall parses the arguments of the function named by name, whose
+// opening parenthesis has been consumed.
+func (p *exprParser) parseCall(name exprToken) (exprNode, error) {
+	if !slices.Contains(exprFuncs, name.text) {
+		return nil, fmt.Errorf("unknown function %q at offset %d (expected %s)", name.text, name.pos, strings.Join(exprFuncs, ", "))
+	}
+	arg, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if arg.kind() != kindString {
+		return nil, fmt.Errorf("%s at offset %d takes a string field, not a %s", name.text, name.pos, arg.kind())
+	}
+	if err := p.expectOp(","); err != nil {
+		return nil, err
+	}
+	patternTok := p.peek()
+	pattern, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	lit, ok := pattern.(literalNode)
+	if !ok || lit.k != kindString {
+		return nil, fmt.Errorf("%s at offset %d takes a string as its second argument", name.text, name.pos)
+	}
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+
+	want := lit.value.(string)
+	var match func(string) bool
+	switch name.text {
+	case "contains":
+		match = func(value string) bool { return strings.Contains(value, want) }
+	case "startsWith":
+		match = func(value string) bool { return strings.HasPrefix(value, want) }
+	case "endsWith":
+		match = func(value string) bool { return strings.HasSuffix(value, want) }
+	case "matches":
+		re, err := regexp.Compile(want)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression at offset %d: %w", patternTok.pos, err)
+		}
+		match = re.MatchString
+	}
+	return callNode{match: match, arg: arg}, nil
+}
@@ -0,0 +1,45 @@
+package view
+
+import (
+	"errors"
+	"io"
+)
+
+// errOutputLimit is returned by limitWriter once --limit-bytes is used up.
+// Run treats it as a clean stop.
+var errOutputLimit = errors.New("output limit reached")
+
+// limitWriter passes at most limit bytes through to w. The write that
+// crosses the limit is cut short, and it and every later write fail with
+// errOutputLimit.
+type limitWriter struct {
+	w       io.Writer
+	limit   int
+	written int
+	full    bool // output was dropped
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if l.full {
+		return 0, errOutputLimit
+	}
+	if room := l.limit - l.written; len(p) > room {
+		n, err := l.w.Write(p[:room])
+		l.written += n
+		l.full = true
+		if err != nil {
+			return n, err
+		}
+		return n, errOutputLimit
+	}
+	n, err := l.w.Write(p)
+	l.written += n
+	return n, err
+}
+
+// outputFull reports whether out is a limitWriter that has dropped output,
+// so rendering can stop even where write errors are ignored.
+func outputFull(out io.Writer) bool {
+	limiter, ok := out.(*limitWriter)
+	return ok && limiter.full
+}
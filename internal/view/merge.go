@@ -0,0 +1,118 @@
+package view
+
+import (
+	"agentlog/internal/model"
+	"strings"
+)
+
+// compositeEvent is implemented by the events that stand for several
+// events of the session, such as a tool call joined with its outputs.
+type compositeEvent interface {
+	parts() []model.EventProvider
+}
+
+// mergedEvent presents consecutive messages from the same role as one, so
+// --merge-consecutive renders a message streamed in chunks in one block.
+// The first message supplies the header and timestamp.
+type mergedEvent struct {
+	model.EventProvider
+	rest []model.EventProvider
+}
+
+// GetContent joins the messages' content. A text block that follows a
+// block of the same type is appended to it directly, since each chunk
+// carries its own spacing.
+func (e *mergedEvent) GetContent() []model.ContentBlock {
+	content := append([]model.ContentBlock(nil), e.EventProvider.GetContent()...)
+	for _, next := range e.rest {
+		for _, block := range next.GetContent() {
+			if last := len(content) - 1; last >= 0 && isTextBlock(block) && content[last].Type == block.Type {
+				content[last].Text += block.Text
+				continue
+			}
+			content = append(content, block)
+		}
+	}
+	return content
+}
+
+// GetRaw returns the messages' records, one per line.
+func (e *mergedEvent) GetRaw() string {
+	raws := make([]string, 0, len(e.rest)+1)
+	for _, part := range e.parts() {
+		raws = append(raws, part.GetRaw())
+	}
+	return strings.Join(raws, "\n")
+}
+
+// GetMetadata forwards the first message's metadata, if any.
+func (e *mergedEvent) GetMetadata() map[string]string {
+	if provider, ok := e.EventProvider.(model.EventMetadataProvider); ok {
+		return provider.GetMetadata()
+	}
+	return nil
+}
+
+// GetTokenUsage sums the token usage of the messages.
+func (e *mergedEvent) GetTokenUsage() (input, output int) {
+	for _, part := range e.parts() {
+		if provider, ok := part.(model.TokenUsageProvider); ok {
+			in, out := provider.GetTokenUsage()
+			input += in
+			output += out
+		}
+	}
+	return input, output
+}
+
+func (e *mergedEvent) parts() []model.EventProvider {
+	return append([]model.EventProvider{e.EventProvider}, e.rest...)
+}
+
+// mergeConsecutive joins runs of messages that share a role, entry type,
+// and payload type. Tool calls and their outputs are never merged.
+func mergeConsecutive(events []model.EventProvider) []model.EventProvider {
+	merged := make([]model.EventProvider, 0, len(events))
+	for _, event := range events {
+		if last := len(merged) - 1; last >= 0 && mergeable(merged[last], event) {
+			joined, ok := merged[last].(*mergedEvent)
+			if !ok {
+				joined = &mergedEvent{EventProvider: merged[last]}
+				merged[last] = joined
+			}
+			joined.rest = append(joined.rest, event)
+			continue
+		}
+		merged = append(merged, event)
+	}
+	return merged
+}
+
+func mergeable(prev, next model.EventProvider) bool {
+	if !mergeableMessage(prev) || !mergeableMessage(next) {
+		return false
+	}
+	return prev.GetRole() == next.GetRole() &&
+		prev.GetEntryType() == next.GetEntryType() &&
+		prev.GetPayloadType() == next.GetPayloadType()
+}
+
+func mergeableMessage(event model.EventProvider) bool {
+	if _, ok := event.(*toolPair); ok {
+		return false
+	}
+	switch event.GetRole() {
+	case "user", "assistant":
+	default:
+		return false
+	}
+	return !isToolCall(event) && !isToolOutput(event) && len(toolCallIDs(event)) == 0
+}
+
+func isTextBlock(block model.ContentBlock) bool {
+	switch block.Type {
+	case "input_text", "output_text", "text", "summary_text", "reasoning":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package view
+
+import "agentlog/internal/model"
+
+// modelTracker follows the model through a session for --show-model-changes.
+// Every event read is observed, since Codex records the model only in
+// turn_context entries that the default filters hide; a change is shown
+// before the next event that is rendered. A nil tracker records nothing.
+type modelTracker struct {
+	current string
+	pending string
+	changes map[model.EventProvider]string
+}
+
+func newModelTracker() *modelTracker {
+	return &modelTracker{changes: make(map[model.EventProvider]string)}
+}
+
+// observe notes the model of an event read from the session. The first
+// model seen is the starting point, not a change.
+func (t *modelTracker) observe(event model.EventProvider) {
+	if t == nil {
+		return
+	}
+	provider, ok := event.(model.ModelProvider)
+	if !ok {
+		return
+	}
+	name := provider.GetModel()
+	if name == "" || name == t.current {
+		return
+	}
+	if t.current != "" {
+		t.pending = name
+	}
+	t.current = name
+}
+
+// attach assigns a change not yet shown to event, which is about to be
+// rendered.
+func (t *modelTracker) attach(event model.EventProvider) {
+	if t == nil || t.pending == "" {
+		return
+	}
+	t.changes[event] = t.pending
+	t.pending = ""
+}
+
+// changeBefore returns the model the session switched to just before event.
+func (t *modelTracker) changeBefore(event model.EventProvider) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	if composite, ok := event.(compositeEvent); ok {
+		for _, part := range composite.parts() {
+			if name, ok := t.changes[part]; ok {
+				return name, true
+			}
+		}
+		return "", false
+	}
+	name, ok := t.changes[event]
+	return name, ok
+}
+
+// modelDivider renders the line shown where the model changed, drawn with
+// horizontal.
+func modelDivider(name, horizontal string) string {
+	rule := horizontal + horizontal
+	return rule + " model changed to " + name + " " + rule
+}
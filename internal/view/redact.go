@@ -0,0 +1,62 @@
+package view
+
+import (
+	"agentlog/internal/format"
+	"agentlog/internal/model"
+	"encoding/json"
+)
+
+// redactedEvent presents an event with its content and raw JSON passed
+// through a Redactor, so every output format shares the same masking.
+type redactedEvent struct {
+	model.EventProvider
+	content []model.ContentBlock
+	raw     string
+}
+
+func redactEvent(event model.EventProvider, redactor *format.Redactor) model.EventProvider {
+	blocks := event.GetContent()
+	content := make([]model.ContentBlock, len(blocks))
+	for i, block := range blocks {
+		content[i] = block
+		content[i].Text = redactor.Redact(block.Text)
+		if len(block.ToolInput) > 0 {
+			// The rules never match quotes or backslashes, so the input
+			// stays valid JSON, as the raw line does.
+			content[i].ToolInput = json.RawMessage(redactor.Redact(string(block.ToolInput)))
+		}
+	}
+	return &redactedEvent{
+		EventProvider: event,
+		content:       content,
+		raw:           redactor.Redact(event.GetRaw()),
+	}
+}
+
+func (e *redactedEvent) GetContent() []model.ContentBlock { return e.content }
+
+func (e *redactedEvent) GetRaw() string { return e.raw }
+
+// GetMetadata forwards the wrapped event's metadata, if any.
+func (e *redactedEvent) GetMetadata() map[string]string {
+	if provider, ok := e.EventProvider.(model.EventMetadataProvider); ok {
+		return provider.GetMetadata()
+	}
+	return nil
+}
+
+// GetTokenUsage forwards the wrapped event's token usage, if any.
+func (e *redactedEvent) GetTokenUsage() (input, output int) {
+	if provider, ok := e.EventProvider.(model.TokenUsageProvider); ok {
+		return provider.GetTokenUsage()
+	}
+	return 0, 0
+}
+
+// GetToolCallIDs forwards the wrapped event's tool call IDs, if any.
+func (e *redactedEvent) GetToolCallIDs() []string {
+	if provider, ok := e.EventProvider.(model.ToolCallProvider); ok {
+		return provider.GetToolCallIDs()
+	}
+	return nil
+}
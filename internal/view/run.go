@@ -3,47 +3,153 @@ package view
 import (
 	"agentlog/internal/format"
 	"agentlog/internal/model"
+	"agentlog/internal/util"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
-	"strconv"
+	"slices"
 	"strings"
-	"time"
+	"syscall"
 
 	"github.com/mattn/go-isatty"
+	"github.com/mattn/go-runewidth"
 	"golang.org/x/term"
 )
 
+// errStop ends iteration once --first events have been rendered.
+var errStop = errors.New("stop iteration")
+
+// ErrNoEvents is returned by Run after rendering when no event matched the
+// filters, so callers can tell an empty transcript from a successful one.
+var ErrNoEvents = errors.New("no events matched")
+
 // Options defines the configurable parameters for rendering a view.
 type Options struct {
 	Path            string
+	Chain           []string // session files rendered in order as one transcript; overrides Path
 	Format          string
 	Wrap            int
+	WrapMode        format.WrapMode
 	MaxEvents       int
+	First           int  // render only the first N matching events and stop reading; 0 means no limit
+	BetweenStart    int  // render only matching events from this 1-based position on; 0 means unset
+	BetweenEnd      int  // render only matching events up to this 1-based position; 0 means unset
+	Reverse         bool // render events newest first
+	Renumber        bool // with Reverse, number events in display order instead of chronologically
+	Tail            int  // read only the last N lines of the file; 0 reads everything
+	MaxArgBytes     int  // truncate rendered function arguments; 0 means no limit
+	MaxOutputBytes  int  // truncate rendered function outputs; 0 means no limit
 	EntryTypeArg    string
 	ResponseTypeArg string
 	EventMsgTypeArg string
 	PayloadRoleArg  string
-	AllFilter       bool
-	ForceColor      bool
-	ForceNoColor    bool
-	RawFile         bool
-	Out             io.Writer
-	OutFile         *os.File
+	// Exclude*Arg remove values from the corresponding filter above.
+	ExcludeResponseTypeArg string
+	ExcludeEventMsgTypeArg string
+	ExcludePayloadRoleArg  string
+	AllFilter              bool
+	ForceColor             bool
+	ForceNoColor           bool
+	ASCII                  bool // draw chat bubbles with ASCII glyphs only
+	RawFile                bool
+	Count                  bool   // print only the number of matching events
+	Stats                  bool   // append a summary footer to text and chat output
+	Redact                 bool   // mask the home directory and secrets in rendered output
+	RedactCWD              bool   // with Redact, also replace the session cwd with a placeholder
+	NoPager                bool   // never page chat output
+	ForcePager             bool   // page chat output even when stdout is not a terminal
+	NoLegend               bool   // omit the session header and color legend above chat output
+	ForceLegend            bool   // show the chat legend even when stdout is not a terminal
+	WithRole               bool   // prefix each body with "role: " in the content format
+	FlatTools              bool   // render each tool call together with its output
+	MergeConsecutive       bool   // render consecutive messages from the same role as one
+	KeepControl            bool   // keep control characters in bodies instead of stripping them
+	ShowModelChanges       bool   // mark where the session switched models in text and chat output
+	LimitBytes             int    // stop after writing this many bytes and add a notice; 0 means no limit
+	ShowInstructions       bool   // show the session's instructions above text and chat output
+	FullInstructions       bool   // with ShowInstructions, do not clip the instructions
+	Debug                  bool   // print each event's raw JSON under its body in text output
+	NoEmptyContent         bool   // skip events whose rendered body is empty instead of showing "(no content)"
+	JSONArray              bool   // with the json format, write one array instead of one object per line
+	Pager                  string // pager command; empty falls back to AGENTLOG_PAGER, PAGER, then less
+	Time                   format.TimeFormatter
+	RoleLabels             RoleLabels
+	Alignments             RoleAlignments // chat bubble side per role; roles without an entry keep the default
+	FilterExpr             *FilterExpr    // render only events it matches; nil matches every event
+	Out                    io.Writer
+	OutFile                *os.File
+
+	// Context stops reading the session when canceled, as on Ctrl-C. The
+	// events read so far are still rendered and Run returns the context's
+	// error. Nil never cancels.
+	Context context.Context
 }
 
-// Run renders a session log according to the provided options.
+// Run renders a session log according to the provided options. It returns
+// ErrNoEvents when the output is complete but no event matched.
 func Run(parser model.Parser, opts Options) error {
+	var limiter *limitWriter
+	if opts.LimitBytes > 0 {
+		if opts.Out == nil {
+			opts.Out = os.Stdout
+		}
+		limiter = &limitWriter{w: opts.Out, limit: opts.LimitBytes}
+		opts.Out = limiter
+	}
+
+	found := 0
+	err := render(parser, opts, &found)
+	if limiter != nil && limiter.full && (err == nil || errors.Is(err, errOutputLimit)) {
+		// The cut can fall mid-line, so the notice starts a line of its own.
+		_, err := fmt.Fprintf(limiter.w, "\n(output truncated at %d bytes)\n", opts.LimitBytes)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if opts.Context != nil && opts.Context.Err() != nil {
+		// Leave the terminal in its default colors for the shell prompt.
+		if resolveColorChoice(opts) {
+			out := opts.Out
+			if out == nil {
+				out = os.Stdout
+			}
+			fmt.Fprint(out, ansiReset) //nolint:errcheck
+		}
+		return opts.Context.Err()
+	}
+	if found == 0 && !opts.RawFile {
+		return ErrNoEvents
+	}
+	return nil
+}
+
+// render does the work of Run, counting matched events in found.
+func render(parser model.Parser, opts Options, found *int) error {
 	if opts.Out == nil {
 		opts.Out = os.Stdout
 	}
+	if len(opts.Chain) > 0 {
+		opts.Path = opts.Chain[0]
+	}
 
 	if opts.RawFile {
-		return copyFile(opts.Out, opts.Path)
+		for _, path := range chainPaths(opts) {
+			if err := copyFile(opts.Out, path); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
-	filters, err := buildViewFilters(opts.AllFilter, opts.EntryTypeArg, opts.ResponseTypeArg, opts.EventMsgTypeArg, opts.PayloadRoleArg)
+	filters, err := buildViewFilters(opts.AllFilter, opts.EntryTypeArg, opts.ResponseTypeArg, opts.EventMsgTypeArg, opts.PayloadRoleArg, viewExclusions{
+		ResponseTypeArg: opts.ExcludeResponseTypeArg,
+		EventMsgTypeArg: opts.ExcludeEventMsgTypeArg,
+		PayloadRoleArg:  opts.ExcludePayloadRoleArg,
+	})
 	if err != nil {
 		return err
 	}
@@ -52,65 +158,303 @@ func Run(parser model.Parser, opts Options) error {
 	if formatMode == "" {
 		formatMode = "text"
 	}
+	// jsonl promises the original bytes, so it never rewrites records.
+	if formatMode == "jsonl" && opts.Redact {
+		return errors.New("--redact cannot be used with --format jsonl; use --format raw instead")
+	}
 
-	if _, err := parser.ReadSessionMeta(opts.Path); err != nil {
+	meta, err := parser.ReadSessionMeta(opts.Path)
+	if err != nil {
 		return err
 	}
 
+	var redactor *format.Redactor
+	if opts.Redact {
+		redactOpts := format.RedactOptions{}
+		if home, err := os.UserHomeDir(); err == nil {
+			redactOpts.HomeDir = home
+		}
+		if opts.RedactCWD {
+			redactOpts.CWD = meta.GetCWD()
+		}
+		redactor = format.NewRedactor(redactOpts)
+	}
+
+	iterate := parser.IterateEvents
+	if opts.Tail > 0 {
+		iterate = func(path string, fn func(model.EventProvider) error) error {
+			return iterateTail(parser, path, opts.Tail, fn)
+		}
+	}
+	if len(opts.Chain) > 0 {
+		single := iterate
+		iterate = func(_ string, fn func(model.EventProvider) error) error {
+			for _, path := range opts.Chain {
+				if err := single(path, fn); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	// indexBase offsets event numbers so a --between-index slice keeps the
+	// positions the events have in the full filtered transcript.
+	indexBase := 0
+	if opts.BetweenStart > 1 {
+		indexBase = opts.BetweenStart - 1
+	}
+
+	var stats viewStats
+	var models *modelTracker
+	if opts.ShowModelChanges {
+		models = newModelTracker()
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	processEvents := func(fn func(model.EventProvider) error) error {
-		return parser.IterateEvents(opts.Path, func(event model.EventProvider) error {
+		matched, position := 0, 0
+		err := iterate(opts.Path, func(event model.EventProvider) error {
+			if ctx.Err() != nil {
+				return errStop
+			}
+			stats.observe(event)
+			models.observe(event)
 			if !eventMatchesFilters(event, filters) {
 				return nil
 			}
-			return fn(event)
+			if opts.NoEmptyContent && emptyContent(event, opts) {
+				return nil
+			}
+			if !opts.FilterExpr.Match(event) {
+				return nil
+			}
+			position++
+			if position < opts.BetweenStart {
+				return nil
+			}
+			*found++
+			if redactor != nil {
+				event = redactEvent(event, redactor)
+			}
+			models.attach(event)
+			if err := fn(event); err != nil {
+				return err
+			}
+			if outputFull(opts.Out) {
+				return errOutputLimit
+			}
+			matched++
+			if opts.First > 0 && matched >= opts.First {
+				return errStop
+			}
+			if opts.BetweenEnd > 0 && position >= opts.BetweenEnd {
+				return errStop
+			}
+			return nil
 		})
+		if errors.Is(err, errStop) {
+			return nil
+		}
+		return err
+	}
+
+	if opts.Count {
+		count := 0
+		if err := processEvents(func(model.EventProvider) error {
+			count++
+			return nil
+		}); err != nil {
+			return err
+		}
+		if opts.MaxEvents > 0 && count > opts.MaxEvents {
+			count = opts.MaxEvents
+		}
+		return format.WriteCount(opts.Out, count, formatMode)
+	}
+
+	// collectEvents gathers the matching events for formats that cannot
+	// stream them, keeping only the last MaxEvents when set.
+	collectEvents := func() ([]model.EventProvider, error) {
+		var events []model.EventProvider
+		if opts.MaxEvents > 0 {
+			ring := newEventRing(opts.MaxEvents)
+			if err := processEvents(func(event model.EventProvider) error {
+				ring.push(event)
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+			events = ring.slice()
+		} else {
+			events = make([]model.EventProvider, 0)
+			if err := processEvents(func(event model.EventProvider) error {
+				events = append(events, event)
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+		if opts.FlatTools {
+			events = pairToolCalls(events)
+		}
+		if opts.MergeConsecutive {
+			events = mergeConsecutive(events)
+		}
+		return events, nil
 	}
+	// An output can arrive long after its call, so --flat-tools reads the
+	// whole transcript before rendering; --merge-consecutive needs to see
+	// where a run of messages ends.
+	buffered := opts.MaxEvents > 0 || opts.Reverse || opts.FlatTools || opts.MergeConsecutive
 
 	switch formatMode {
 	case "text":
-		useColor := resolveColorChoice(opts)
-		if opts.MaxEvents == 0 {
+		printOpts := eventPrintOptions{
+			Wrap:           opts.Wrap,
+			WrapMode:       opts.WrapMode,
+			MaxArgBytes:    opts.MaxArgBytes,
+			MaxOutputBytes: opts.MaxOutputBytes,
+			UseColor:       resolveColorChoice(opts),
+			Time:           opts.Time,
+			Verbose:        opts.AllFilter,
+			RoleLabels:     opts.RoleLabels,
+			KeepControl:    opts.KeepControl,
+			Debug:          opts.Debug,
+		}
+		if lines := continuationLines(meta, opts, redactor); lines != nil {
+			if err := writeLines(opts.Out, append(lines, "")); err != nil {
+				return err
+			}
+		}
+		if opts.ShowInstructions {
+			if lines := instructionsLines(meta, opts, redactor); lines != nil {
+				if err := writeLines(opts.Out, append(lines, "")); err != nil {
+					return err
+				}
+			}
+		}
+		if !buffered {
 			count := 0
-			return processEvents(func(event model.EventProvider) error {
+			if err := processEvents(func(event model.EventProvider) error {
 				if count > 0 {
 					fmt.Fprintln(opts.Out) //nolint:errcheck
 				}
-				printEvent(opts.Out, event, count+1, opts.Wrap, useColor)
+				printModelChange(opts.Out, models, event)
+				printEvent(opts.Out, event, indexBase+count+1, printOpts)
+				stats.add(event)
 				count++
 				return nil
+			}); err != nil {
+				return err
+			}
+		} else {
+			events, err := collectEvents()
+			if err != nil {
+				return err
+			}
+			for idx, entry := range orderEvents(events, indexBase, opts.Reverse, opts.Renumber) {
+				if idx > 0 {
+					fmt.Fprintln(opts.Out) //nolint:errcheck
+				}
+				printModelChange(opts.Out, models, entry.event)
+				printEvent(opts.Out, entry.event, entry.index, printOpts)
+				stats.add(entry.event)
+			}
+		}
+		if opts.Stats {
+			fmt.Fprintf(opts.Out, "\n%s\n", stats.footer()) //nolint:errcheck
+		}
+		return nil
+
+	case "raw", "jsonl":
+		// Both re-emit each matching record's original line; raw also
+		// honours --redact.
+		if !buffered {
+			return processEvents(func(event model.EventProvider) error {
+				_, err := fmt.Fprintln(opts.Out, event.GetRaw()) //nolint:errcheck
+				return err
 			})
 		}
-		ring := newEventRing(opts.MaxEvents)
-		if err := processEvents(func(event model.EventProvider) error {
-			ring.push(event)
-			return nil
-		}); err != nil {
+		events, err := collectEvents()
+		if err != nil {
+			return err
+		}
+		for _, entry := range orderEvents(events, indexBase, opts.Reverse, opts.Renumber) {
+			fmt.Fprintln(opts.Out, entry.event.GetRaw()) //nolint:errcheck
+		}
+		return nil
+
+	case "content":
+		// Only the bodies, separated by blank lines, for piping into other
+		// tools; events without a body are skipped.
+		renderOpts := format.RenderOptions{
+			Width:          opts.Wrap,
+			WrapMode:       opts.WrapMode,
+			MaxArgBytes:    opts.MaxArgBytes,
+			MaxOutputBytes: opts.MaxOutputBytes,
+			KeepControl:    opts.KeepControl,
+		}
+		written := 0
+		writeBody := func(event model.EventProvider) error {
+			body := strings.Join(format.RenderEventLines(event, renderOpts), "\n")
+			if body == "" {
+				return nil
+			}
+			if opts.WithRole {
+				body = contentRole(event, opts.RoleLabels) + ": " + body
+			}
+			if written > 0 {
+				body = "\n" + body
+			}
+			written++
+			_, err := fmt.Fprintln(opts.Out, body)
+			return err
+		}
+		if !buffered {
+			return processEvents(writeBody)
+		}
+		events, err := collectEvents()
+		if err != nil {
 			return err
 		}
-		for idx, event := range ring.slice() {
-			if idx > 0 {
-				fmt.Fprintln(opts.Out) //nolint:errcheck
+		for _, entry := range orderEvents(events, indexBase, opts.Reverse, opts.Renumber) {
+			if err := writeBody(entry.event); err != nil {
+				return err
 			}
-			printEvent(opts.Out, event, idx+1, opts.Wrap, useColor)
 		}
 		return nil
 
-	case "raw":
-		if opts.MaxEvents == 0 {
-			return processEvents(func(event model.EventProvider) error {
-				_, err := fmt.Fprintln(opts.Out, event.GetRaw()) //nolint:errcheck
+	case "json":
+		if opts.JSONArray {
+			events, err := collectEvents()
+			if err != nil {
 				return err
+			}
+			ordered := orderEvents(events, indexBase, opts.Reverse, opts.Renumber)
+			records := make([]format.EventRecord, 0, len(ordered))
+			for _, entry := range ordered {
+				records = append(records, format.NewEventRecord(entry.event, entry.index))
+			}
+			return format.WriteEventJSONArray(opts.Out, records)
+		}
+		if !buffered {
+			count := 0
+			return processEvents(func(event model.EventProvider) error {
+				count++
+				return format.WriteEventJSON(opts.Out, event, indexBase+count)
 			})
 		}
-		ring := newEventRing(opts.MaxEvents)
-		if err := processEvents(func(event model.EventProvider) error {
-			ring.push(event)
-			return nil
-		}); err != nil {
+		events, err := collectEvents()
+		if err != nil {
 			return err
 		}
-		for _, event := range ring.slice() {
-			fmt.Fprintln(opts.Out, event.GetRaw()) //nolint:errcheck
+		for _, entry := range orderEvents(events, indexBase, opts.Reverse, opts.Renumber) {
+			if err := format.WriteEventJSON(opts.Out, entry.event, entry.index); err != nil {
+				return err
+			}
 		}
 		return nil
 
@@ -118,37 +462,58 @@ func Run(parser model.Parser, opts Options) error {
 		colorEnabled := resolveColorChoice(opts)
 		width := determineWidth(opts.OutFile, opts.Wrap)
 
-		var events []model.EventProvider
-		if opts.MaxEvents > 0 {
-			ring := newEventRing(opts.MaxEvents)
-			if err := processEvents(func(event model.EventProvider) error {
-				ring.push(event)
-				return nil
-			}); err != nil {
-				return err
-			}
-			events = ring.slice()
-		} else {
-			collected := make([]model.EventProvider, 0)
-			if err := processEvents(func(event model.EventProvider) error {
-				collected = append(collected, event)
-				return nil
-			}); err != nil {
-				return err
-			}
-			events = collected
+		events, err := collectEvents()
+		if err != nil {
+			return err
+		}
+		if opts.Reverse {
+			slices.Reverse(events)
 		}
 
-		if len(events) == 0 {
+		if len(events) == 0 && !opts.Stats {
 			return nil
 		}
 
-		lines := renderChatTranscript(events, width, colorEnabled)
+		chatOpts := chatOptions{
+			Width:          width,
+			WrapMode:       opts.WrapMode,
+			MaxArgBytes:    opts.MaxArgBytes,
+			MaxOutputBytes: opts.MaxOutputBytes,
+			UseColor:       colorEnabled,
+			Time:           opts.Time,
+			Charset:        unicodeCharset,
+			RoleLabels:     opts.RoleLabels,
+			KeepControl:    opts.KeepControl,
+			Models:         models,
+			Alignments:     opts.Alignments,
+		}
+		if opts.ASCII || !terminalSupportsUnicode() {
+			chatOpts.Charset = asciiCharset
+		}
+		lines := renderChatTranscript(events, chatOpts)
+		if opts.ShowInstructions {
+			if block := instructionsLines(meta, opts, redactor); block != nil {
+				lines = append(append(block, ""), lines...)
+			}
+		}
+		if block := continuationLines(meta, opts, redactor); block != nil {
+			lines = append(append(block, ""), lines...)
+		}
+		if showLegend(opts) {
+			lines = append(chatLegend(meta, chatOpts), lines...)
+		}
+		if opts.Stats {
+			for _, event := range events {
+				stats.add(event)
+			}
+			lines = append(lines, "", stats.footer())
+		}
 		if len(lines) == 0 {
 			return nil
 		}
-		if opts.OutFile != nil && isatty.IsTerminal(opts.OutFile.Fd()) {
-			return pipeThroughPager(lines, colorEnabled)
+		// An interrupted transcript is written as is rather than paged.
+		if ctx.Err() == nil && shouldPage(opts, len(lines)) {
+			return pipeThroughPager(opts.Out, lines, resolvePagerCommand(opts.Pager), colorEnabled)
 		}
 		return writeLines(opts.Out, lines)
 
@@ -157,27 +522,29 @@ func Run(parser model.Parser, opts Options) error {
 	}
 }
 
+// viewFilters holds the allow-lists applied to each event. A nil set admits
+// every value.
 type viewFilters struct {
-	// TODO: Implement agent-agnostic filtering
-	// For now, filters are disabled
 	entryTypes        map[string]struct{}
 	responseItemTypes map[string]struct{}
 	eventMsgTypes     map[string]struct{}
 	payloadRoles      map[string]struct{}
+
+	// Blocklists used when the matching allow-list above admits everything.
+	excludedResponseItemTypes map[string]struct{}
+	excludedEventMsgTypes     map[string]struct{}
+	excludedPayloadRoles      map[string]struct{}
 }
 
-func buildViewFilters(allFilter bool, entryArg, responseTypeArg, eventMsgTypeArg, payloadRoleArg string) (viewFilters, error) {
-	var filters viewFilters
+// viewExclusions holds the --exclude-* flag values.
+type viewExclusions struct {
+	ResponseTypeArg string
+	EventMsgTypeArg string
+	PayloadRoleArg  string
+}
 
-	// If --all is specified, disable all filters
-	if allFilter {
-		return viewFilters{
-			entryTypes:        nil,
-			responseItemTypes: nil,
-			eventMsgTypes:     nil,
-			payloadRoles:      nil,
-		}, nil
-	}
+func buildViewFilters(allFilter bool, entryArg, responseTypeArg, eventMsgTypeArg, payloadRoleArg string, exclude viewExclusions) (viewFilters, error) {
+	var filters viewFilters
 
 	entryFilter, entryProvided, err := parseEntryTypeArg(entryArg)
 	if err != nil {
@@ -196,9 +563,12 @@ func buildViewFilters(allFilter bool, entryArg, responseTypeArg, eventMsgTypeArg
 		return filters, err
 	}
 
+	// --all lifts the defaults below, so only explicitly given filters apply.
+	// session_meta and other non-message entries are hidden by these
+	// defaults rather than skipped unconditionally.
 	if entryProvided {
 		filters.entryTypes = entryFilter
-	} else {
+	} else if !allFilter {
 		filters.entryTypes = map[string]struct{}{
 			"response_item": {},
 		}
@@ -206,7 +576,7 @@ func buildViewFilters(allFilter bool, entryArg, responseTypeArg, eventMsgTypeArg
 
 	if responseTypeProvided {
 		filters.responseItemTypes = responseTypeFilter
-	} else {
+	} else if !allFilter {
 		filters.responseItemTypes = map[string]struct{}{
 			"message": {},
 		}
@@ -221,16 +591,60 @@ func buildViewFilters(allFilter bool, entryArg, responseTypeArg, eventMsgTypeArg
 
 	if roleProvided {
 		filters.payloadRoles = payloadRoleFilter
-	} else {
+	} else if !allFilter {
 		filters.payloadRoles = map[string]struct{}{
 			"user":      {},
 			"assistant": {},
 		}
 	}
 
+	excludedResponseTypes, err := parseExclusionArg(exclude.ResponseTypeArg, parseResponseTypeArg)
+	if err != nil {
+		return filters, err
+	}
+	excludedEventMsgTypes, err := parseExclusionArg(exclude.EventMsgTypeArg, parseEventMsgTypeArg)
+	if err != nil {
+		return filters, err
+	}
+	excludedRoles, err := parseExclusionArg(exclude.PayloadRoleArg, parsePayloadRoleArg)
+	if err != nil {
+		return filters, err
+	}
+	filters.responseItemTypes, filters.excludedResponseItemTypes = subtractFilter(filters.responseItemTypes, excludedResponseTypes)
+	filters.eventMsgTypes, filters.excludedEventMsgTypes = subtractFilter(filters.eventMsgTypes, excludedEventMsgTypes)
+	filters.payloadRoles, filters.excludedPayloadRoles = subtractFilter(filters.payloadRoles, excludedRoles)
+
 	return filters, nil
 }
 
+// parseExclusionArg parses an --exclude-* value with the parser of the
+// matching allow-list flag. Excluding "all" is rejected.
+func parseExclusionArg(arg string, parse func(string) (map[string]struct{}, bool, error)) (map[string]struct{}, error) {
+	set, provided, err := parse(arg)
+	if err != nil {
+		return nil, err
+	}
+	if provided && set == nil {
+		return nil, errors.New("cannot exclude \"all\"")
+	}
+	return set, nil
+}
+
+// subtractFilter removes excluded values from allowed. A nil allowed set
+// admits everything, so the exclusions are returned as a blocklist instead.
+func subtractFilter(allowed, excluded map[string]struct{}) (map[string]struct{}, map[string]struct{}) {
+	if len(excluded) == 0 {
+		return allowed, nil
+	}
+	if allowed == nil {
+		return nil, excluded
+	}
+	for value := range excluded {
+		delete(allowed, value)
+	}
+	return allowed, nil
+}
+
 func parseEntryTypeArg(arg string) (map[string]struct{}, bool, error) {
 	values := parseCSV(arg)
 	if len(values) == 0 {
@@ -298,11 +712,18 @@ func parseEventMsgTypeArg(arg string) (map[string]struct{}, bool, error) {
 
 	// Valid event_msg types (agent-agnostic)
 	validTypes := map[string]bool{
-		"token_count":     true,
-		"agent_reasoning": true,
-		"user_message":    true,
-		"agent_message":   true,
-		"turn_aborted":    true,
+		"token_count":         true,
+		"agent_reasoning":     true,
+		"user_message":        true,
+		"agent_message":       true,
+		"turn_aborted":        true,
+		"error":               true,
+		"task_started":        true,
+		"task_complete":       true,
+		"exec_command_begin":  true,
+		"exec_command_end":    true,
+		"mcp_tool_call_begin": true,
+		"mcp_tool_call_end":   true,
 	}
 
 	set := make(map[string]struct{}, len(values))
@@ -357,13 +778,68 @@ func parseCSV(arg string) []string {
 	return output
 }
 
+// eventMatchesFilters reports whether event passes the filters, using the
+// normalized entry and payload types so it works for every agent. Payload
+// roles only apply to messages; tool calls and outputs are selected by -T.
 func eventMatchesFilters(event model.EventProvider, filters viewFilters) bool {
-	// TODO: Implement agent-agnostic filtering
-	// For now, accept all events when using generic interface
-	// Agent-specific filtering will be re-implemented later
+	entryType := event.GetEntryType()
+	if !filterAdmits(filters.entryTypes, nil, entryType) {
+		return false
+	}
+
+	payloadType := event.GetPayloadType()
+	switch entryType {
+	case "response_item":
+		if !filterAdmits(filters.responseItemTypes, filters.excludedResponseItemTypes, payloadType) {
+			return false
+		}
+		if payloadType == "message" {
+			return filterAdmits(filters.payloadRoles, filters.excludedPayloadRoles, event.GetRole())
+		}
+	case "event_msg":
+		return filterAdmits(filters.eventMsgTypes, filters.excludedEventMsgTypes, payloadType)
+	}
 	return true
 }
 
+// filterAdmits reports whether value is in allowed, or allowed is nil, and
+// value is not in excluded.
+func filterAdmits(allowed, excluded map[string]struct{}, value string) bool {
+	if _, ok := excluded[value]; ok {
+		return false
+	}
+	if allowed == nil {
+		return true
+	}
+	_, ok := allowed[value]
+	return ok
+}
+
+// numberedEvent pairs an event with the index shown in its header.
+type numberedEvent struct {
+	event model.EventProvider
+	index int
+}
+
+// orderEvents numbers events by their chronological position after base
+// and, when reverse is set, returns them newest first. renumber instead
+// numbers them in the order they are shown.
+func orderEvents(events []model.EventProvider, base int, reverse, renumber bool) []numberedEvent {
+	ordered := make([]numberedEvent, len(events))
+	for i, event := range events {
+		ordered[i] = numberedEvent{event: event, index: base + i + 1}
+	}
+	if reverse {
+		slices.Reverse(ordered)
+	}
+	if renumber {
+		for i := range ordered {
+			ordered[i].index = i + 1
+		}
+	}
+	return ordered
+}
+
 type eventRing struct {
 	data   []model.EventProvider
 	start  int
@@ -405,26 +881,66 @@ func determineWidth(out *os.File, wrap int) int {
 	if wrap > 0 {
 		return wrap
 	}
-	if out != nil {
-		if w, _, err := term.GetSize(int(out.Fd())); err == nil && w > 0 {
-			return w
-		}
-	}
-	if colsStr := os.Getenv("COLUMNS"); colsStr != "" {
-		if v, err := strconv.Atoi(colsStr); err == nil && v > 0 {
-			return v
-		}
+	if w := util.TerminalWidth(out); w > 0 {
+		return w
 	}
 	return 80
 }
 
-func pipeThroughPager(lines []string, colorEnabled bool) error {
+// showLegend decides whether chat output starts with the session header and
+// color legend: by default only when stdout is a terminal.
+func showLegend(opts Options) bool {
+	if opts.NoLegend {
+		return false
+	}
+	if opts.ForceLegend {
+		return true
+	}
+	return opts.OutFile != nil && isatty.IsTerminal(opts.OutFile.Fd())
+}
+
+// shouldPage decides whether chat output goes through a pager. Paging is
+// skipped when disabled, when stdout is not a terminal (unless forced), and
+// when the output fits within the terminal height.
+func shouldPage(opts Options, lineCount int) bool {
+	if opts.NoPager {
+		return false
+	}
+	if opts.ForcePager {
+		return true
+	}
+	if opts.OutFile == nil || !isatty.IsTerminal(opts.OutFile.Fd()) {
+		return false
+	}
+	if _, height, err := term.GetSize(int(opts.OutFile.Fd())); err == nil && height > 0 && lineCount < height {
+		return false
+	}
+	return true
+}
+
+// resolvePagerCommand returns the pager command to run, preferring the
+// explicit --pager value, then AGENTLOG_PAGER, then PAGER. An empty result
+// selects the built-in less invocation.
+func resolvePagerCommand(explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if env := os.Getenv("AGENTLOG_PAGER"); env != "" {
+		return env
+	}
+	return os.Getenv("PAGER")
+}
+
+func pipeThroughPager(out io.Writer, lines []string, pagerCmd string, colorEnabled bool) error {
+	if strings.TrimSpace(pagerCmd) == "cat" {
+		return writeLines(out, lines)
+	}
+
 	text := strings.Join(lines, "\n")
 	if !strings.HasSuffix(text, "\n") {
 		text += "\n"
 	}
 
-	pagerCmd := os.Getenv("PAGER")
 	var cmd *exec.Cmd
 	if pagerCmd == "" {
 		args := []string{"less"}
@@ -436,25 +952,66 @@ func pipeThroughPager(lines []string, colorEnabled bool) error {
 		cmd = exec.Command("sh", "-c", pagerCmd) // #nosec G204
 	}
 
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = out
 	cmd.Stderr = os.Stderr
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return fmt.Errorf("create pager pipe: %w", err)
 	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("run pager: %w", err)
+	}
+
+	writeErr := make(chan error, 1)
 	go func() {
-		defer stdin.Close()         //nolint:errcheck
-		io.WriteString(stdin, text) //nolint:errcheck
+		_, err := io.WriteString(stdin, text)
+		if closeErr := stdin.Close(); err == nil {
+			err = closeErr
+		}
+		writeErr <- err
 	}()
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("run pager: %w", err)
-	}
+	waitErr := cmd.Wait()
+	werr := <-writeErr
 
+	if waitErr != nil && !isPagerQuit(waitErr) {
+		return fmt.Errorf("run pager: %w", waitErr)
+	}
+	if werr != nil && !isBrokenPipe(werr) {
+		return fmt.Errorf("write to pager: %w", werr)
+	}
 	return nil
 }
 
+// isPagerQuit reports whether err is the pager dying from SIGPIPE, which
+// happens when the user quits before all output was consumed, or from the
+// SIGINT a Ctrl-C sends to the pager as well as to agentlog.
+func isPagerQuit(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		switch status.Signal() {
+		case syscall.SIGPIPE, syscall.SIGINT:
+			return true
+		}
+	}
+	// Shells report a child killed by a signal as 128 plus its number.
+	switch exitErr.ExitCode() {
+	case 128 + int(syscall.SIGPIPE), 128 + int(syscall.SIGINT):
+		return true
+	}
+	return false
+}
+
+// isBrokenPipe reports whether err comes from writing to a pager that has
+// already exited.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, os.ErrClosed)
+}
+
 func writeLines(out io.Writer, lines []string) error {
 	for _, line := range lines {
 		if _, err := fmt.Fprintln(out, line); err != nil {
@@ -464,58 +1021,199 @@ func writeLines(out io.Writer, lines []string) error {
 	return nil
 }
 
-func printEvent(out io.Writer, event model.EventProvider, index int, wrap int, useColor bool) {
-	roleLabel := event.GetRole()
-	if roleLabel == "" {
-		roleLabel = "event"
+// eventPrintOptions controls how printEvent renders a single event.
+type eventPrintOptions struct {
+	Wrap           int
+	WrapMode       format.WrapMode
+	MaxArgBytes    int
+	MaxOutputBytes int
+	UseColor       bool
+	Time           format.TimeFormatter
+	Verbose        bool // append agent-specific details such as the service tier to the header
+	RoleLabels     RoleLabels
+	KeepControl    bool
+	Debug          bool // follow each body with the event's raw JSON
+}
+
+// instructionsLines returns the block shown above the events by
+// --show-instructions, or nil when the session records no instructions.
+// Unless opts.FullInstructions is set, the text is clipped to
+// format.InstructionsPreviewBytes.
+func instructionsLines(meta model.SessionMetaProvider, opts Options, redactor *format.Redactor) []string {
+	provider, ok := meta.(model.InstructionsProvider)
+	if !ok || strings.TrimSpace(provider.GetInstructions()) == "" {
+		return nil
+	}
+	text := provider.GetInstructions()
+	if redactor != nil {
+		text = redactor.Redact(text)
+	}
+	if !opts.FullInstructions {
+		text = format.TruncateBytes(text, format.InstructionsPreviewBytes)
 	}
-	roleLabel = strings.ToLower(roleLabel)
+	body := format.RenderTextLines(text, format.RenderOptions{
+		Width:       opts.Wrap,
+		WrapMode:    opts.WrapMode,
+		KeepControl: opts.KeepControl,
+	})
+	lines := []string{"[instructions]", "--------------"}
+	for _, line := range body {
+		lines = append(lines, strings.TrimRight("| "+line, " "))
+	}
+	return lines
+}
+
+// continuationLines returns the note shown above the events of a session
+// that continues a compacted conversation, one line per summary of the
+// earlier part, or nil for any other session.
+func continuationLines(meta model.SessionMetaProvider, opts Options, redactor *format.Redactor) []string {
+	provider, ok := meta.(model.ContinuationProvider)
+	if !ok {
+		return nil
+	}
+	var lines []string
+	for _, summary := range provider.GetContinuedFrom() {
+		if redactor != nil {
+			summary = redactor.Redact(summary)
+		}
+		lines = append(lines, format.RenderTextLines("(continued from compaction: "+summary+")", format.RenderOptions{
+			Width:       opts.Wrap,
+			WrapMode:    opts.WrapMode,
+			KeepControl: opts.KeepControl,
+		})...)
+	}
+	return lines
+}
+
+// printModelChange writes the divider for a model switch just before event,
+// if there was one.
+func printModelChange(out io.Writer, models *modelTracker, event model.EventProvider) {
+	if name, ok := models.changeBefore(event); ok {
+		fmt.Fprintf(out, "%s\n\n", modelDivider(name, "─")) //nolint:errcheck
+	}
+}
+
+func printEvent(out io.Writer, event model.EventProvider, index int, opts eventPrintOptions) {
+	role := strings.ToLower(event.GetRole())
+	if role == "" {
+		role = "event"
+	}
+	roleLabel := opts.RoleLabels.text(role)
 
 	ts := "-"
 	if !event.GetTimestamp().IsZero() {
-		ts = event.GetTimestamp().Format(time.RFC3339)
+		ts = opts.Time.Format(event.GetTimestamp())
 	}
 	headerPlain := fmt.Sprintf("[#%03d] %s | %s", index, roleLabel, ts)
+	details := ""
+	if opts.Verbose {
+		details = headerDetails(event)
+	}
+	if details != "" {
+		headerPlain += " | " + details
+	}
 
 	indexText := fmt.Sprintf("#%03d", index)
 	roleText := roleLabel
 	tsText := ts
 	separator := "|"
 
-	if useColor {
+	if opts.UseColor {
 		indexText = colorize(ansiBoldWhite, indexText)
-		roleText = colorize(roleColor(roleLabel), roleText)
+		roleText = colorize(roleColor(role), roleText)
 		tsText = colorize(ansiTimestamp, tsText)
 		separator = colorize(ansiSeparator, "|")
 	}
 
 	header := fmt.Sprintf("[%s] %s %s %s", indexText, roleText, separator, tsText)
-	fmt.Fprintln(out, header)                                //nolint:errcheck
-	fmt.Fprintln(out, strings.Repeat("-", len(headerPlain))) //nolint:errcheck
-
-	lines := format.RenderEventLines(event, wrap)
-	if len(lines) == 0 {
-		prefix := "|"
-		if useColor {
-			prefix = colorize(ansiSeparator, "|")
+	if details != "" {
+		detailsText := details
+		if opts.UseColor {
+			detailsText = colorize(ansiTimestamp, detailsText)
 		}
-		fmt.Fprintf(out, "%s %s\n", prefix, "(no content)") //nolint:errcheck
-		return
+		header += fmt.Sprintf(" %s %s", separator, detailsText)
 	}
+	fmt.Fprintln(out, header)                                                  //nolint:errcheck
+	fmt.Fprintln(out, strings.Repeat("-", runewidth.StringWidth(headerPlain))) //nolint:errcheck
+
+	lines := format.RenderEventLines(event, format.RenderOptions{
+		Width:          opts.Wrap,
+		WrapMode:       opts.WrapMode,
+		MaxArgBytes:    opts.MaxArgBytes,
+		MaxOutputBytes: opts.MaxOutputBytes,
+		KeepControl:    opts.KeepControl,
+	})
 	linePrefix := "| "
 	emptyPrefix := "|"
-	if useColor {
+	if opts.UseColor {
 		separatorColor := colorize(ansiSeparator, "|")
 		linePrefix = separatorColor + " "
 		emptyPrefix = separatorColor
 	}
+	if len(lines) == 0 {
+		fmt.Fprintf(out, "%s%s\n", linePrefix, "(no content)") //nolint:errcheck
+	}
+	// System entries are injected context, so their body is dimmed too.
+	dim := opts.UseColor && role == "system"
 	for _, line := range lines {
 		if line == "" {
 			fmt.Fprintln(out, emptyPrefix) //nolint:errcheck
 			continue
 		}
+		if dim {
+			line = colorize(ansiDim, line)
+		}
 		fmt.Fprintf(out, "%s%s\n", linePrefix, line) //nolint:errcheck
 	}
+	if opts.Debug {
+		printRawLines(out, event, linePrefix, opts.UseColor)
+	}
+}
+
+// emptyContent reports whether event renders without a body, which the
+// text format shows as "(no content)".
+func emptyContent(event model.EventProvider, opts Options) bool {
+	lines := format.RenderEventLines(event, format.RenderOptions{
+		MaxArgBytes:    opts.MaxArgBytes,
+		MaxOutputBytes: opts.MaxOutputBytes,
+		KeepControl:    opts.KeepControl,
+	})
+	return len(lines) == 0
+}
+
+// printRawLines writes the JSON records behind event for --debug, one per
+// line and unwrapped so they can be copied into a bug report as is.
+func printRawLines(out io.Writer, event model.EventProvider, linePrefix string, useColor bool) {
+	for _, raw := range strings.Split(event.GetRaw(), "\n") {
+		if raw == "" {
+			continue
+		}
+		raw = "raw: " + raw
+		if useColor {
+			raw = colorize(ansiDim, raw)
+		}
+		fmt.Fprintf(out, "%s%s\n", linePrefix, raw) //nolint:errcheck
+	}
+}
+
+// contentRole returns the role prefix of the content format.
+func contentRole(event model.EventProvider, labels RoleLabels) string {
+	if role := event.GetRole(); role != "" {
+		return labels.text(role)
+	}
+	return "event"
+}
+
+// headerDetails returns the agent-specific details shown in verbose headers.
+func headerDetails(event model.EventProvider) string {
+	provider, ok := event.(model.EventMetadataProvider)
+	if !ok {
+		return ""
+	}
+	if tier := provider.GetMetadata()["service_tier"]; tier != "" {
+		return "tier: " + tier
+	}
+	return ""
 }
 
 const (
@@ -526,6 +1224,7 @@ const (
 	ansiAssistant = "\x1b[38;5;44m"
 	ansiUser      = "\x1b[38;5;220m"
 	ansiTool      = "\x1b[38;5;207m"
+	ansiDim       = "\x1b[2m"
 )
 
 func colorize(code string, text string) string {
@@ -538,8 +1237,10 @@ func roleColor(role string) string {
 		return ansiAssistant
 	case "user":
 		return ansiUser
-	case "tool", "system":
+	case "tool":
 		return ansiTool
+	case "system":
+		return ansiDim
 	default:
 		return ansiSeparator
 	}
@@ -559,7 +1260,7 @@ func shouldUseColorAuto(out io.Writer) bool {
 	if os.Getenv("NO_COLOR") != "" {
 		return false
 	}
-	file, ok := out.(*os.File)
+	file, ok := outputFile(out)
 	if !ok {
 		return false
 	}
@@ -567,8 +1268,26 @@ func shouldUseColorAuto(out io.Writer) bool {
 	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
 }
 
+// outputFile returns the file out writes to, looking through the
+// limitWriter that --limit-bytes wraps the output in.
+func outputFile(out io.Writer) (*os.File, bool) {
+	if limiter, ok := out.(*limitWriter); ok {
+		out = limiter.w
+	}
+	file, ok := out.(*os.File)
+	return file, ok
+}
+
+// chainPaths returns the files Run reads: the chain when set, otherwise Path.
+func chainPaths(opts Options) []string {
+	if len(opts.Chain) > 0 {
+		return opts.Chain
+	}
+	return []string{opts.Path}
+}
+
 func copyFile(dst io.Writer, path string) error {
-	f, err := os.Open(path)
+	f, err := model.OpenSessionFile(path)
 	if err != nil {
 		return err
 	}
@@ -1,9 +1,16 @@
 package view
 
 import (
+	"agentlog/internal/claude"
 	"agentlog/internal/codex"
+	"agentlog/internal/format"
 	"agentlog/internal/model"
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,7 +19,7 @@ import (
 )
 
 func TestBuildViewFiltersDefaults(t *testing.T) {
-	filters, err := buildViewFilters(false, "", "", "", "")
+	filters, err := buildViewFilters(false, "", "", "", "", viewExclusions{})
 	if err != nil {
 		t.Fatalf("buildViewFilters returned error: %v", err)
 	}
@@ -33,6 +40,76 @@ func TestBuildViewFiltersDefaults(t *testing.T) {
 	}
 }
 
+func TestBuildViewFiltersAllKeepsExplicitFilters(t *testing.T) {
+	filters, err := buildViewFilters(true, "session_meta", "", "", "", viewExclusions{})
+	if err != nil {
+		t.Fatalf("buildViewFilters returned error: %v", err)
+	}
+	if _, ok := filters.entryTypes["session_meta"]; !ok || len(filters.entryTypes) != 1 {
+		t.Fatalf("expected explicit session_meta entry filter, got %#v", filters.entryTypes)
+	}
+	if filters.responseItemTypes != nil || filters.payloadRoles != nil {
+		t.Fatalf("--all should lift default filters, got %#v", filters)
+	}
+}
+
+func TestRunAllWithSessionMetaEntryType(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "turn-context.jsonl")
+	var buf bytes.Buffer
+	opts := Options{
+		Path:         path,
+		Format:       "text",
+		Out:          &buf,
+		EntryTypeArg: "session_meta",
+		AllFilter:    true,
+	}
+	if err := Run(&codex.CodexParser{}, opts); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "session_meta") || !strings.Contains(out, "test-turn-context-session") {
+		t.Fatalf("expected session_meta event in output:\n%s", out)
+	}
+}
+
+func TestBuildViewFiltersExclusions(t *testing.T) {
+	// An include-all allow-list turns the exclusion into a blocklist.
+	filters, err := buildViewFilters(false, "event_msg", "", "all", "", viewExclusions{EventMsgTypeArg: "token_count"})
+	if err != nil {
+		t.Fatalf("buildViewFilters returned error: %v", err)
+	}
+	if filters.eventMsgTypes != nil {
+		t.Fatalf("expected all event_msg types to be allowed, got %#v", filters.eventMsgTypes)
+	}
+	if _, ok := filters.excludedEventMsgTypes["token_count"]; !ok || len(filters.excludedEventMsgTypes) != 1 {
+		t.Fatalf("expected token_count blocklist, got %#v", filters.excludedEventMsgTypes)
+	}
+
+	// An explicit allow-list has the excluded values removed.
+	filters, err = buildViewFilters(false, "event_msg", "", "token_count,agent_reasoning", "", viewExclusions{
+		EventMsgTypeArg: "token_count",
+		PayloadRoleArg:  "user",
+	})
+	if err != nil {
+		t.Fatalf("buildViewFilters returned error: %v", err)
+	}
+	if _, ok := filters.eventMsgTypes["agent_reasoning"]; !ok || len(filters.eventMsgTypes) != 1 {
+		t.Fatalf("expected only agent_reasoning to remain, got %#v", filters.eventMsgTypes)
+	}
+	if filters.excludedEventMsgTypes != nil {
+		t.Fatalf("expected no blocklist when an allow-list is given, got %#v", filters.excludedEventMsgTypes)
+	}
+	if _, ok := filters.payloadRoles["assistant"]; !ok || len(filters.payloadRoles) != 1 {
+		t.Fatalf("expected default roles minus user, got %#v", filters.payloadRoles)
+	}
+
+	for _, exclude := range []viewExclusions{{ResponseTypeArg: "all"}, {PayloadRoleArg: "unknown"}} {
+		if _, err := buildViewFilters(false, "", "", "", "", exclude); err == nil {
+			t.Fatalf("expected error for %+v", exclude)
+		}
+	}
+}
+
 func TestEventMatchesFilters(t *testing.T) {
 	t.Skip("Filtering logic temporarily bypassed during agent-agnostic refactoring")
 
@@ -67,7 +144,7 @@ func TestParsePayloadRoleArgUnknown(t *testing.T) {
 	}
 }
 
-func TestRenderChatLinesAlignment(t *testing.T) {
+func alignmentFixtureEvents() []model.EventProvider {
 	codexEvents := []codex.CodexEvent{
 		{
 			Role:      codex.PayloadRoleUser,
@@ -90,8 +167,13 @@ func TestRenderChatLinesAlignment(t *testing.T) {
 	for i := range codexEvents {
 		events[i] = &codexEvents[i]
 	}
+	return events
+}
+
+func TestRenderChatLinesAlignment(t *testing.T) {
+	events := alignmentFixtureEvents()
 
-	lines := renderChatTranscript(events, 80, false)
+	lines := renderChatTranscript(events, chatOptions{Width: 80, WrapMode: format.WrapWord})
 	if len(lines) == 0 {
 		t.Fatal("expected chat lines")
 	}
@@ -116,6 +198,50 @@ func TestRenderChatLinesAlignment(t *testing.T) {
 	}
 }
 
+func TestRenderChatLinesASCII(t *testing.T) {
+	lines := renderChatTranscript(alignmentFixtureEvents(), chatOptions{
+		Width:    80,
+		WrapMode: format.WrapWord,
+		Charset:  asciiCharset,
+	})
+	if len(lines) == 0 {
+		t.Fatal("expected chat lines")
+	}
+	for _, line := range lines {
+		for i := 0; i < len(line); i++ {
+			if line[i] >= 0x80 {
+				t.Fatalf("unexpected non-ASCII byte %#x in %q", line[i], line)
+			}
+		}
+	}
+	if findPrefix(lines, "+") < 0 {
+		t.Fatalf("expected ASCII bubble borders: %v", lines)
+	}
+}
+
+func TestTerminalSupportsUnicode(t *testing.T) {
+	cases := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"utf8 locale", map[string]string{"TERM": "xterm-256color", "LANG": "en_US.UTF-8"}, true},
+		{"dumb terminal", map[string]string{"TERM": "dumb", "LANG": "en_US.UTF-8"}, false},
+		{"posix locale", map[string]string{"TERM": "xterm", "LANG": "C"}, false},
+		{"lc_all overrides lang", map[string]string{"TERM": "xterm", "LC_ALL": "C", "LANG": "en_US.UTF-8"}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range []string{"TERM", "LC_ALL", "LC_CTYPE", "LANG"} {
+				t.Setenv(key, tc.env[key])
+			}
+			if got := terminalSupportsUnicode(); got != tc.want {
+				t.Fatalf("terminalSupportsUnicode() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
 func findPrefix(lines []string, prefix string) int {
 	for i, line := range lines {
 		if strings.HasPrefix(line, prefix) || strings.Contains(line, prefix) {
@@ -262,3 +388,1207 @@ func TestFilterCombinations(t *testing.T) {
 		})
 	}
 }
+
+func TestRunChatNoPagerWritesDirectly(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	parser := &codex.CodexParser{}
+	var buf bytes.Buffer
+	opts := Options{
+		Path:         path,
+		Format:       "chat",
+		ForceNoColor: true,
+		ForcePager:   true,
+		NoPager:      true,
+		Pager:        "echo paged",
+		Out:          &buf,
+	}
+	if err := Run(parser, opts); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "paged") {
+		t.Fatalf("--no-pager output should not go through the pager: %q", out)
+	}
+	if !strings.Contains(out, "Hello, can you help me?") {
+		t.Fatalf("expected transcript written directly, got %q", out)
+	}
+}
+
+func TestRunChatForcePagerUsesCommand(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	parser := &codex.CodexParser{}
+	var buf bytes.Buffer
+	opts := Options{
+		Path:         path,
+		Format:       "chat",
+		ForceNoColor: true,
+		ForcePager:   true,
+		Pager:        "sed 's/^/paged:/'",
+		Out:          &buf,
+	}
+	if err := Run(parser, opts); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		if !strings.HasPrefix(line, "paged:") {
+			t.Fatalf("expected every line to pass through the pager, got %q", line)
+		}
+	}
+}
+
+func TestRunChatLegend(t *testing.T) {
+	// Keep the Unicode separator regardless of the test environment.
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("LC_ALL", "en_US.UTF-8")
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	parser := &codex.CodexParser{}
+	render := func(noLegend bool) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := Run(parser, Options{
+			Path:         path,
+			Format:       "chat",
+			ForceNoColor: true,
+			NoPager:      true,
+			ForceLegend:  true,
+			NoLegend:     noLegend,
+			Out:          &buf,
+		}); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		return buf.String()
+	}
+
+	out := render(false)
+	lines := strings.Split(out, "\n")
+	if !strings.HasPrefix(lines[0], "Session test-simple-session") {
+		t.Fatalf("expected session header first, got %q", lines[0])
+	}
+	if lines[1] != "Legend: User (right) · Assistant (left) · Tool (left)" {
+		t.Fatalf("unexpected legend line: %q", lines[1])
+	}
+	if out := render(true); strings.Contains(out, "Legend:") || strings.Contains(out, "Session test-simple-session") {
+		t.Fatalf("--no-legend output should omit the header and legend:\n%s", out)
+	}
+}
+
+func TestRunDebugPrintsRawLines(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	render := func(format string) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := Run(&codex.CodexParser{}, Options{Path: path, Format: format, Debug: true, NoPager: true, ForceNoColor: true, Out: &buf}); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		return buf.String()
+	}
+
+	lines := strings.Split(render("text"), "\n")
+	body := -1
+	for i, line := range lines {
+		if line == "| Hello, can you help me?" {
+			body = i
+			break
+		}
+	}
+	if body < 0 {
+		t.Fatalf("user body not found:\n%s", strings.Join(lines, "\n"))
+	}
+	raw := lines[body+1]
+	if !strings.HasPrefix(raw, "| raw: {") || !strings.Contains(raw, `"Hello, can you help me?"`) {
+		t.Fatalf("expected raw JSON under the body, got %q", raw)
+	}
+
+	for _, format := range []string{"chat", "json"} {
+		if out := render(format); strings.Contains(out, "raw: ") {
+			t.Fatalf("--debug should not affect %s output:\n%s", format, out)
+		}
+	}
+}
+
+func TestRunNoEmptyContent(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "claude-sessions", "sample-with-tools.jsonl")
+	render := func(noEmpty bool) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := Run(&claude.ClaudeParser{}, Options{Path: path, AllFilter: true, NoEmptyContent: noEmpty, ForceNoColor: true, Out: &buf}); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		return buf.String()
+	}
+
+	if out := render(false); !strings.Contains(out, "[#005] summary") || !strings.Contains(out, "(no content)") {
+		t.Fatalf("expected the empty summary entry by default:\n%s", out)
+	}
+	out := render(true)
+	if strings.Contains(out, "(no content)") || strings.Contains(out, "summary") {
+		t.Fatalf("empty events should be omitted:\n%s", out)
+	}
+	if !strings.Contains(out, "[#004] assistant") || strings.Contains(out, "[#005]") {
+		t.Fatalf("expected the remaining events numbered 1-4:\n%s", out)
+	}
+}
+
+// cancelingWriter cancels a context on its first write, standing in for a
+// Ctrl-C that arrives while the first event is being rendered.
+type cancelingWriter struct {
+	bytes.Buffer
+	cancel context.CancelFunc
+}
+
+func (w *cancelingWriter) Write(p []byte) (int, error) {
+	w.cancel()
+	return w.Buffer.Write(p)
+}
+
+func TestRunContextCanceled(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	for _, mode := range []string{"text", "chat"} {
+		ctx, cancel := context.WithCancel(context.Background())
+		out := &cancelingWriter{cancel: cancel}
+		if mode == "chat" {
+			// Chat output is written only after the events are read, so
+			// cancel before reading starts.
+			cancel()
+		}
+		err := Run(&codex.CodexParser{}, Options{Path: path, Format: mode, ForceColor: true, ForcePager: true, Pager: "exit 1", NoLegend: true, Context: ctx, Out: out})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("%s: expected context.Canceled, got %v", mode, err)
+		}
+		got := out.String()
+		if !strings.HasSuffix(got, ansiReset) {
+			t.Fatalf("%s: expected output to end with a color reset: %q", mode, got)
+		}
+		if mode == "text" && (!strings.Contains(got, "#001") || strings.Contains(got, "#002")) {
+			t.Fatalf("expected only the event rendered before the interrupt:\n%s", got)
+		}
+	}
+}
+
+func TestRunFilterExpr(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	render := func(src string) string {
+		t.Helper()
+		filter, err := ParseFilterExpr(src)
+		if err != nil {
+			t.Fatalf("ParseFilterExpr(%q) returned error: %v", src, err)
+		}
+		var buf bytes.Buffer
+		err = Run(&codex.CodexParser{}, Options{Path: path, FilterExpr: filter, ForceNoColor: true, Out: &buf})
+		if err != nil && !errors.Is(err, ErrNoEvents) {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		return buf.String()
+	}
+
+	tests := []struct {
+		expr string
+		want []string // headers of the events shown
+	}{
+		{`role == user && contains(text, "function")`, []string{"[#001] user"}},
+		{`!(role == user) && text != ""`, []string{"[#001] assistant", "[#002] assistant"}},
+		{`role == "assistant" || startsWith(text, "Hello")`, []string{"[#001] user", "[#002] assistant", "[#003] assistant"}},
+		{`timestamp >= 2025-11-05T09:00:03Z && matches(text, "(?i)^i")`, []string{"[#001] user", "[#002] assistant"}},
+		{`type != message`, nil},
+	}
+	for _, tt := range tests {
+		out := render(tt.expr)
+		var headers []string
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasPrefix(line, "[#") {
+				headers = append(headers, strings.SplitN(line, " |", 2)[0])
+			}
+		}
+		if strings.Join(headers, ",") != strings.Join(tt.want, ",") {
+			t.Fatalf("%s: got events %v, want %v\n%s", tt.expr, headers, tt.want, out)
+		}
+	}
+}
+
+func TestParseFilterExprErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`role ==`, "unexpected end of expression at offset 7"},
+		{`role == user &&`, "unexpected end of expression"},
+		{`(role == user`, `expected ")"`},
+		{`text`, "expression is a string, not a condition"},
+		{`!text`, "not a condition"},
+		{`timestamp > yesterday`, "not an RFC3339 timestamp"},
+		{`timestamp == text`, "timestamp with string"},
+		{`contains(role)`, `expected ","`},
+		{`lower(text, "a")`, `unknown function "lower"`},
+		{`matches(text, "(")`, "invalid regular expression"},
+		{`text == "open`, "unterminated string"},
+		{`role = user`, "unexpected character '='"},
+	}
+	for _, tt := range tests {
+		_, err := ParseFilterExpr(tt.expr)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("ParseFilterExpr(%q) error = %v, want %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestRunMergeConsecutive(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "split-message.jsonl")
+	render := func(format string) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := Run(&codex.CodexParser{}, Options{Path: path, Format: format, MergeConsecutive: true, ASCII: true, NoPager: true, NoLegend: true, ForceNoColor: true, Out: &buf}); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		return buf.String()
+	}
+
+	out := render("text")
+	if !strings.Contains(out, "[#002] assistant | 2025-11-07T14:00:02Z\n") {
+		t.Fatalf("merged message should keep the first chunk's timestamp:\n%s", out)
+	}
+	if !strings.Contains(out, "| Check out the branch, then run `git branch -m new-name` to rename it.\n") {
+		t.Fatalf("chunks should render as one body:\n%s", out)
+	}
+	if !strings.Contains(out, "[#004] assistant") || strings.Contains(out, "[#005]") {
+		t.Fatalf("expected four events after merging:\n%s", out)
+	}
+
+	chat := render("chat")
+	if n := strings.Count(chat, "Assistant - "); n != 2 {
+		t.Fatalf("expected 2 assistant bubbles, got %d:\n%s", n, chat)
+	}
+}
+
+func TestRunCompactionContinuation(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "claude-sessions", "sample-compacted.jsonl")
+	const note = "(continued from compaction: Set up the CI workflow for the Go module)"
+	for _, format := range []string{"text", "chat"} {
+		var buf bytes.Buffer
+		if err := Run(&claude.ClaudeParser{}, Options{Path: path, Format: format, NoPager: true, ForceNoColor: true, Out: &buf}); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		if first := strings.SplitN(buf.String(), "\n", 2)[0]; first != note {
+			t.Fatalf("%s output should open with the compaction note, got %q", format, first)
+		}
+	}
+
+	var buf bytes.Buffer
+	other := filepath.Join("..", "..", "testdata", "claude-sessions", "sample-with-tools.jsonl")
+	if err := Run(&claude.ClaudeParser{}, Options{Path: other, AllFilter: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if strings.Contains(buf.String(), "continued from compaction") {
+		t.Fatalf("a session that starts afresh should have no note:\n%s", buf.String())
+	}
+}
+
+func TestRunChatAlignments(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("LC_ALL", "en_US.UTF-8")
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	alignments, err := ParseRoleAlignments(map[string]string{"user": "left", "assistant": "right"})
+	if err != nil {
+		t.Fatalf("ParseRoleAlignments returned error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Run(&codex.CodexParser{}, Options{
+		Path:         path,
+		Format:       "chat",
+		ASCII:        true,
+		ForceNoColor: true,
+		NoPager:      true,
+		ForceLegend:  true,
+		Alignments:   alignments,
+		Out:          &buf,
+	}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if lines[1] != "Legend: User (left) - Assistant (right) - Tool (left)" {
+		t.Fatalf("unexpected legend line: %q", lines[1])
+	}
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		switch {
+		case strings.HasPrefix(trimmed, "| User"):
+			if indent > 2 {
+				t.Fatalf("user bubble should be on the left, got %q", line)
+			}
+		case strings.HasPrefix(trimmed, "| Assistant"):
+			if indent <= 2 {
+				t.Fatalf("assistant bubble should be on the right, got %q", line)
+			}
+		}
+	}
+
+	for _, pairs := range []map[string]string{{"robot": "left"}, {"user": "middle"}} {
+		if _, err := ParseRoleAlignments(pairs); err == nil {
+			t.Fatalf("expected error for %v", pairs)
+		}
+	}
+}
+
+func TestResolvePagerCommand(t *testing.T) {
+	t.Setenv("AGENTLOG_PAGER", "cat")
+	t.Setenv("PAGER", "more")
+
+	if got := resolvePagerCommand("bat"); got != "bat" {
+		t.Fatalf("explicit pager should win, got %q", got)
+	}
+	if got := resolvePagerCommand(""); got != "cat" {
+		t.Fatalf("AGENTLOG_PAGER should take precedence over PAGER, got %q", got)
+	}
+}
+
+func TestPipeThroughPagerEarlyQuit(t *testing.T) {
+	lines := make([]string, 0, 20000)
+	for i := 0; i < cap(lines); i++ {
+		lines = append(lines, strings.Repeat("x", 80))
+	}
+
+	tests := []struct {
+		name  string
+		pager string
+	}{
+		{name: "reads one line then exits", pager: "head -n 1"},
+		{name: "killed by SIGPIPE", pager: "read line; echo \"$line\"; kill -PIPE $$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := pipeThroughPager(&buf, lines, tt.pager, false); err != nil {
+				t.Fatalf("early pager quit should not be an error: %v", err)
+			}
+			if got := strings.TrimRight(buf.String(), "\n"); got != lines[0] {
+				t.Fatalf("expected only the first line, got %d bytes", len(got))
+			}
+		})
+	}
+}
+
+func TestPipeThroughPagerFailure(t *testing.T) {
+	var buf bytes.Buffer
+	if err := pipeThroughPager(&buf, []string{"line"}, "exit 3", false); err == nil {
+		t.Fatal("expected error for failing pager")
+	}
+}
+
+func TestRunCountRespectsMax(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-full.jsonl")
+	parser := &codex.CodexParser{}
+
+	var buf bytes.Buffer
+	if err := Run(parser, Options{Path: path, Count: true, MaxEvents: 3, Format: "json", Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if got := buf.String(); got != "{\"count\":3}\n" {
+		t.Fatalf("unexpected count output: %q", got)
+	}
+}
+
+func TestRunFormatJSONIncludesClaudeMetadata(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "claude-sessions", "sample-priority-tier.jsonl")
+	var buf bytes.Buffer
+	if err := Run(&claude.ClaudeParser{}, Options{Path: path, Format: "json", Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSON lines, got %d:\n%s", len(lines), buf.String())
+	}
+	var record format.EventRecord
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if record.Role != "assistant" || record.Index != 2 {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+	if record.Metadata["service_tier"] != "priority" {
+		t.Fatalf("expected service_tier priority, got %#v", record.Metadata)
+	}
+	if record.Metadata["request_id"] != "req_011CPriorityTier" {
+		t.Fatalf("expected request_id, got %#v", record.Metadata)
+	}
+}
+
+func TestRunFormatJSONToolInput(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "claude-sessions", "sample-with-tools.jsonl")
+	var buf bytes.Buffer
+	if err := Run(&claude.ClaudeParser{}, Options{Path: path, Format: "json", AllFilter: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	var call map[string]any
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		var record struct {
+			Content []map[string]any `json:"content"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		for _, block := range record.Content {
+			if block["type"] == "tool_use" {
+				call = block
+			}
+		}
+	}
+	if call == nil {
+		t.Fatalf("expected a tool_use block:\n%s", buf.String())
+	}
+	if call["tool_name"] != "Read" {
+		t.Fatalf("expected tool_name Read, got %v", call["tool_name"])
+	}
+	input, ok := call["tool_input"].(map[string]any)
+	if !ok || input["file_path"] != "README.md" {
+		t.Fatalf("expected tool_input as an object, got %#v", call["tool_input"])
+	}
+	if !strings.Contains(call["text"].(string), `Input: {"file_path":"README.md"}`) {
+		t.Fatalf("text rendering should be unchanged, got %q", call["text"])
+	}
+}
+
+func TestRunFormatJSONArray(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	var buf bytes.Buffer
+	if err := Run(&codex.CodexParser{}, Options{Path: path, Format: "json", JSONArray: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	var records []format.EventRecord
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("decode array: %v\n%s", err, buf.String())
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(records))
+	}
+	for i, record := range records {
+		if record.Index != i+1 {
+			t.Fatalf("record %d has index %d", i, record.Index)
+		}
+	}
+	if records[1].Role != "assistant" || records[1].Content[0].Text != "Of course! How can I help you today?" {
+		t.Fatalf("unexpected second record: %+v", records[1])
+	}
+
+	buf.Reset()
+	if err := Run(&codex.CodexParser{}, Options{Path: path, Format: "json", JSONArray: true, PayloadRoleArg: "system", Out: &buf}); err != nil && !errors.Is(err, ErrNoEvents) {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Fatalf("expected an empty array, got %q", got)
+	}
+}
+
+func TestPrintEventVerboseShowsServiceTier(t *testing.T) {
+	event := &claude.ClaudeEvent{
+		Kind:    claude.EntryTypeAssistant,
+		Role:    "assistant",
+		Content: []model.ContentBlock{{Type: "text", Text: "hi"}},
+		Usage:   &claude.TokenUsage{ServiceTier: "priority"},
+	}
+
+	var buf bytes.Buffer
+	printEvent(&buf, event, 1, eventPrintOptions{Verbose: true})
+	if !strings.Contains(buf.String(), "| tier: priority") {
+		t.Fatalf("expected service tier in verbose header, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	printEvent(&buf, event, 1, eventPrintOptions{})
+	if strings.Contains(buf.String(), "tier:") {
+		t.Fatalf("service tier should only appear in verbose mode, got:\n%s", buf.String())
+	}
+}
+
+func TestWrapTextModes(t *testing.T) {
+	token := strings.Repeat("x", 30)
+	text := "short words then " + token
+
+	char := wrapText(text, 12, format.WrapChar)
+	word := wrapText(text, 12, format.WrapWord)
+	for name, lines := range map[string][]string{"char": char, "word": word} {
+		for _, line := range lines {
+			if visibleWidth(line) > 12 {
+				t.Fatalf("%s mode line exceeds width: %q", name, line)
+			}
+		}
+	}
+	if word[0] != "short words" || word[1] != "then" {
+		t.Fatalf("word mode should break on spaces first, got %q", word)
+	}
+	if char[0] != "short words " {
+		t.Fatalf("char mode should break at the width boundary, got %q", char)
+	}
+
+	if none := wrapText(text, 12, format.WrapNone); len(none) != 1 || none[0] != text {
+		t.Fatalf("none mode should return the line unchanged, got %q", none)
+	}
+}
+
+func TestRunRedact(t *testing.T) {
+	t.Setenv("HOME", "/Users/test")
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "secrets.jsonl")
+
+	for _, formatMode := range []string{"text", "json", "raw"} {
+		var buf bytes.Buffer
+		opts := Options{Path: path, Format: formatMode, AllFilter: true, Redact: true, RedactCWD: true, Out: &buf}
+		if err := Run(&codex.CodexParser{}, opts); err != nil {
+			t.Fatalf("Run(%s) returned error: %v", formatMode, err)
+		}
+		got := buf.String()
+		for _, secret := range []string{"sk-proj-abcdef1234567890XYZ", "abcDEF1234567890ghiJKL", "/Users/test/"} {
+			if strings.Contains(got, secret) {
+				t.Fatalf("%s output still contains %q:\n%s", formatMode, secret, got)
+			}
+		}
+		for _, want := range []string{"<cwd>/src/api.ts", "~/.config/acme.toml", "/Users/testing"} {
+			if !strings.Contains(got, want) {
+				t.Fatalf("%s output missing %q:\n%s", formatMode, want, got)
+			}
+		}
+	}
+}
+
+func TestRunTailMatchesLastEventsOfFullRead(t *testing.T) {
+	prev := tailChunkSize
+	tailChunkSize = 64 // force several backward reads
+	t.Cleanup(func() { tailChunkSize = prev })
+
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-full.jsonl")
+	render := func(opts Options) string {
+		t.Helper()
+		var buf bytes.Buffer
+		opts.Path = path
+		opts.Format = "json"
+		opts.AllFilter = true
+		opts.Out = &buf
+		if err := Run(&codex.CodexParser{}, opts); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		return buf.String()
+	}
+
+	tail := render(Options{Tail: 3})
+	full := render(Options{MaxEvents: 3})
+	if tail != full {
+		t.Fatalf("--tail 3 differs from the last 3 events of a full read\ntail:\n%s\nfull:\n%s", tail, full)
+	}
+	if n := strings.Count(tail, "\n"); n != 3 {
+		t.Fatalf("expected 3 events, got %d", n)
+	}
+
+	// N beyond the line count falls back to reading the whole file.
+	if all, whole := render(Options{Tail: 1000}), render(Options{}); all != whole {
+		t.Fatalf("--tail larger than the file should match a full read")
+	}
+}
+
+func TestReadTailLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lines.jsonl")
+	if err := os.WriteFile(path, []byte("one\ntwo\n\nthree\nfour\n"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	prev := tailChunkSize
+	tailChunkSize = 3
+	t.Cleanup(func() { tailChunkSize = prev })
+
+	lines, whole, err := readTailLines(path, 2)
+	if err != nil {
+		t.Fatalf("readTailLines returned error: %v", err)
+	}
+	if whole || len(lines) != 2 || string(lines[0]) != "three" || string(lines[1]) != "four" {
+		t.Fatalf("unexpected tail: whole=%v lines=%q", whole, lines)
+	}
+
+	if _, whole, err := readTailLines(path, 4); err != nil || !whole {
+		t.Fatalf("expected whole-file fallback for n equal to the line count, got whole=%v err=%v", whole, err)
+	}
+}
+
+func TestRunStatsFooter(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-full.jsonl")
+	parser := &codex.CodexParser{}
+
+	var buf bytes.Buffer
+	if err := Run(parser, Options{Path: path, AllFilter: true, Stats: true, MaxEvents: 13, ForceNoColor: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	want := "-- 13 events shown, 3 filtered out | tokens: 50 in / 75 out | span: 00:00:12 | first response: 00:00:02 --\n"
+	if got := buf.String(); !strings.HasSuffix(got, "\n\n"+want) {
+		t.Fatalf("expected footer %q, got tail %q", want, got[len(got)-min(len(got), 200):])
+	}
+
+	buf.Reset()
+	if err := Run(parser, Options{Path: path, AllFilter: true, Stats: true, Format: "chat", NoPager: true, ForceNoColor: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "-- 16 events shown, 0 filtered out | tokens: 50 in / 75 out | span: 00:00:15 | first response: 00:00:02 --\n") {
+		t.Fatalf("expected chat footer, got %q", buf.String())
+	}
+
+	for _, mode := range []string{"raw", "json"} {
+		buf.Reset()
+		if err := Run(parser, Options{Path: path, Stats: true, Format: mode, Out: &buf}); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		if strings.Contains(buf.String(), "events shown") {
+			t.Fatalf("--format %s should not print the stats footer", mode)
+		}
+	}
+}
+
+// countingParser wraps a parser and records how many events it yielded.
+type countingParser struct {
+	model.Parser
+	yielded int
+}
+
+func (p *countingParser) IterateEvents(path string, fn func(model.EventProvider) error) error {
+	return p.Parser.IterateEvents(path, func(event model.EventProvider) error {
+		p.yielded++
+		return fn(event)
+	})
+}
+
+func TestRunFirstStopsEarly(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-full.jsonl")
+	parser := &countingParser{Parser: &codex.CodexParser{}}
+
+	var buf bytes.Buffer
+	if err := Run(parser, Options{Path: path, AllFilter: true, First: 2, ForceNoColor: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "[#001]") || !strings.Contains(out, "[#002]") || strings.Contains(out, "[#003]") {
+		t.Fatalf("expected exactly the first two events, got:\n%s", out)
+	}
+	if !strings.Contains(out, "test-full-session") {
+		t.Fatalf("expected the session's opening event, got:\n%s", out)
+	}
+	if parser.yielded != 2 {
+		t.Fatalf("expected iteration to stop after 2 events, read %d", parser.yielded)
+	}
+}
+
+func TestRunFormatJSONLPreservesOriginalLines(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-full.jsonl")
+	parser := &codex.CodexParser{}
+
+	var buf bytes.Buffer
+	if err := Run(parser, Options{Path: path, Format: "jsonl", AllFilter: true, First: 4, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read sample file: %v", err)
+	}
+	lines := strings.SplitAfter(string(source), "\n")
+	want := strings.Join(lines[:4], "")
+	if !strings.Contains(lines[0], "\"type\":\"session_meta\"") {
+		t.Fatalf("fixture should start with session_meta: %q", lines[0])
+	}
+	if got := buf.String(); got != want {
+		t.Fatalf("jsonl output is not byte-identical to the source\nwant:\n%q\ngot:\n%q", want, got)
+	}
+
+	if err := Run(parser, Options{Path: path, Format: "jsonl", Redact: true, Out: &buf}); err == nil {
+		t.Fatal("expected --redact to be rejected with --format jsonl")
+	}
+}
+
+func TestRunReverseEvents(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	parser := &codex.CodexParser{}
+
+	headers := func(opts Options) []string {
+		t.Helper()
+		var buf bytes.Buffer
+		opts.Path = path
+		opts.ForceNoColor = true
+		opts.Out = &buf
+		if err := Run(parser, opts); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		var out []string
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if strings.HasPrefix(line, "[#") {
+				out = append(out, strings.SplitN(line, " |", 2)[0])
+			}
+		}
+		return out
+	}
+
+	forward := headers(Options{})
+	reversed := headers(Options{Reverse: true})
+	if len(forward) < 3 || len(reversed) != len(forward) {
+		t.Fatalf("unexpected event headers: %v / %v", forward, reversed)
+	}
+	for i := range forward {
+		if reversed[i] != forward[len(forward)-1-i] {
+			t.Fatalf("reversed order should keep chronological indices\nforward:  %v\nreversed: %v", forward, reversed)
+		}
+	}
+	if !strings.HasPrefix(reversed[0], fmt.Sprintf("[#%03d]", len(forward))) {
+		t.Fatalf("newest event should keep its original index, got %v", reversed)
+	}
+
+	renumbered := headers(Options{Reverse: true, Renumber: true})
+	if !strings.HasPrefix(renumbered[0], "[#001]") || strings.TrimPrefix(renumbered[0], "[#001]") != strings.TrimPrefix(reversed[0], fmt.Sprintf("[#%03d]", len(forward))) {
+		t.Fatalf("--renumber should number in display order, got %v", renumbered)
+	}
+
+	last := headers(Options{Reverse: true, MaxEvents: 2})
+	if len(last) != 2 || last[0] != "[#002] "+strings.SplitN(forward[len(forward)-1], " ", 2)[1] {
+		t.Fatalf("--max with reverse should show the last two events newest first, got %v", last)
+	}
+}
+
+// emptyParser wraps a parser but yields no events.
+type emptyParser struct {
+	model.Parser
+}
+
+func (emptyParser) IterateEvents(string, func(model.EventProvider) error) error {
+	return nil
+}
+
+func TestRunReportsNoEvents(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	parser := emptyParser{Parser: &codex.CodexParser{}}
+
+	for _, opts := range []Options{
+		{Path: path, ForceNoColor: true},
+		{Path: path, Count: true},
+		{Path: path, Format: "json"},
+	} {
+		var buf bytes.Buffer
+		opts.Out = &buf
+		if err := Run(parser, opts); !errors.Is(err, ErrNoEvents) {
+			t.Fatalf("Run(%+v) = %v, want ErrNoEvents", opts, err)
+		}
+	}
+
+	if err := Run(&codex.CodexParser{}, Options{Path: path, Out: io.Discard}); err != nil {
+		t.Fatalf("Run with events returned error: %v", err)
+	}
+	if err := Run(parser, Options{Path: path, RawFile: true, Out: io.Discard}); err != nil {
+		t.Fatalf("raw output should not report missing events: %v", err)
+	}
+}
+
+func TestEventMatchesFiltersAcrossAgents(t *testing.T) {
+	codexPath := filepath.Join("..", "..", "testdata", "sessions", "sample-full.jsonl")
+	claudePath := filepath.Join("..", "..", "testdata", "claude-sessions", "sample-with-tools.jsonl")
+	thinkingPath := filepath.Join("..", "..", "testdata", "claude-sessions", "sample-thinking.jsonl")
+
+	tests := []struct {
+		name     string
+		parser   model.Parser
+		path     string
+		all      bool
+		entry    string
+		resp     string
+		eventMsg string
+		role     string
+		exclude  viewExclusions
+		want     int
+	}{
+		{name: "codex default", parser: &codex.CodexParser{}, path: codexPath, want: 4},
+		{name: "codex system role", parser: &codex.CodexParser{}, path: codexPath, role: "system", want: 1},
+		{name: "codex token counts", parser: &codex.CodexParser{}, path: codexPath, entry: "event_msg", eventMsg: "token_count", want: 3},
+		{name: "codex tool calls ignore roles", parser: &codex.CodexParser{}, path: codexPath, resp: "function_call,function_call_output", want: 2},
+		{name: "codex all minus token counts", parser: &codex.CodexParser{}, path: codexPath, all: true, exclude: viewExclusions{EventMsgTypeArg: "token_count"}, want: 13},
+		{name: "claude default", parser: &claude.ClaudeParser{}, path: claudePath, want: 2},
+		{name: "claude tool calls", parser: &claude.ClaudeParser{}, path: claudePath, resp: "function_call,function_call_output", want: 2},
+		{name: "claude summary", parser: &claude.ClaudeParser{}, path: claudePath, entry: "session_meta", want: 1},
+		{name: "claude all", parser: &claude.ClaudeParser{}, path: claudePath, all: true, want: 5},
+		{name: "claude thinking", parser: &claude.ClaudeParser{}, path: thinkingPath, resp: "reasoning", want: 2},
+		{name: "claude default hides thinking", parser: &claude.ClaudeParser{}, path: thinkingPath, want: 2},
+		{name: "claude all minus assistant", parser: &claude.ClaudeParser{}, path: claudePath, all: true, exclude: viewExclusions{PayloadRoleArg: "assistant"}, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := buildViewFilters(tt.all, tt.entry, tt.resp, tt.eventMsg, tt.role, tt.exclude)
+			if err != nil {
+				t.Fatalf("buildViewFilters returned error: %v", err)
+			}
+			got := 0
+			if err := tt.parser.IterateEvents(tt.path, func(event model.EventProvider) error {
+				if eventMatchesFilters(event, filters) {
+					got++
+				}
+				return nil
+			}); err != nil {
+				t.Fatalf("IterateEvents returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %d matching events, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRunClaudeThinking(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "claude-sessions", "sample-thinking.jsonl")
+
+	var buf bytes.Buffer
+	if err := Run(&claude.ClaudeParser{}, Options{Path: path, ResponseTypeArg: "message,reasoning", ForceNoColor: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"💭 Reasoning: 221 = 13 * 17, so it is composite.", "💭 Reasoning: (redacted thinking)", "No, 221 is 13 × 17."} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "signature") || strings.Contains(out, `"thinking"`) {
+		t.Fatalf("thinking blocks should not render as raw JSON:\n%s", out)
+	}
+}
+
+func TestRunContentFormat(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	parser := &codex.CodexParser{}
+
+	var buf bytes.Buffer
+	if err := Run(parser, Options{Path: path, Format: "content", Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	want := "Hello, can you help me?\n\n" +
+		"Of course! How can I help you today?\n\n" +
+		"I need to write a function\n\n" +
+		"I'd be happy to help you write a function. What should it do?\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected content output:\n%q\nwant:\n%q", got, want)
+	}
+
+	buf.Reset()
+	if err := Run(parser, Options{Path: path, Format: "content", WithRole: true, MaxEvents: 2, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	want = "user: I need to write a function\n\n" +
+		"assistant: I'd be happy to help you write a function. What should it do?\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected content output with roles:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+// TestFilterArgsAcceptCodexConstants keeps the string-based filter flags in
+// step with the typed constants the Codex parser emits, so a new constant
+// cannot become impossible to filter on.
+func TestFilterArgsAcceptCodexConstants(t *testing.T) {
+	check := func(kind string, parse func(string) (map[string]struct{}, bool, error), values ...string) {
+		t.Helper()
+		for _, value := range values {
+			set, provided, err := parse(value)
+			if err != nil || !provided {
+				t.Fatalf("%s %q rejected: provided=%v err=%v", kind, value, provided, err)
+			}
+			if _, ok := set[value]; !ok {
+				t.Fatalf("%s %q missing from parsed set %v", kind, value, set)
+			}
+		}
+	}
+
+	check("entry type", parseEntryTypeArg,
+		string(codex.EntryTypeSessionMeta), string(codex.EntryTypeResponseItem),
+		string(codex.EntryTypeEventMsg), string(codex.EntryTypeTurnContext))
+	check("response type", parseResponseTypeArg,
+		string(codex.ResponseItemTypeMessage), string(codex.ResponseItemTypeReasoning),
+		string(codex.ResponseItemTypeFunctionCall), string(codex.ResponseItemTypeFunctionCallOutput),
+		string(codex.ResponseItemTypeCustomToolCall), string(codex.ResponseItemTypeCustomToolCallOutput))
+	check("event_msg type", parseEventMsgTypeArg,
+		string(codex.EventMsgTypeTokenCount), string(codex.EventMsgTypeAgentReasoning),
+		string(codex.EventMsgTypeUserMessage), string(codex.EventMsgTypeAgentMessage),
+		string(codex.EventMsgTypeTurnAborted), string(codex.EventMsgTypeError),
+		string(codex.EventMsgTypeTaskStarted), string(codex.EventMsgTypeTaskComplete),
+		string(codex.EventMsgTypeExecBegin), string(codex.EventMsgTypeExecEnd),
+		string(codex.EventMsgTypeMCPToolBegin), string(codex.EventMsgTypeMCPToolEnd))
+	check("payload role", parsePayloadRoleArg,
+		string(codex.PayloadRoleUser), string(codex.PayloadRoleAssistant),
+		string(codex.PayloadRoleTool), string(codex.PayloadRoleSystem))
+
+	filters, err := buildViewFilters(false, string(codex.EntryTypeEventMsg), "", string(codex.EventMsgTypeTokenCount), "", viewExclusions{})
+	if err != nil {
+		t.Fatalf("buildViewFilters returned error: %v", err)
+	}
+	event := &codex.CodexEvent{Kind: codex.EntryTypeEventMsg, PayloadType: string(codex.EventMsgTypeTokenCount)}
+	if !eventMatchesFilters(event, filters) {
+		t.Fatalf("a token_count event should match -E event_msg -M token_count")
+	}
+}
+
+func TestRunBetweenIndex(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-full.jsonl")
+	parser := &codex.CodexParser{}
+
+	var buf bytes.Buffer
+	if err := Run(parser, Options{Path: path, AllFilter: true, BetweenStart: 3, BetweenEnd: 5, ForceNoColor: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	var headers []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "[#") {
+			headers = append(headers, line[:len("[#000]")])
+		}
+	}
+	if got := strings.Join(headers, ","); got != "[#003],[#004],[#005]" {
+		t.Fatalf("expected events 3-5 with their original numbers, got %s", got)
+	}
+
+	// An end past the last event is clamped; a start past it matches nothing.
+	buf.Reset()
+	if err := Run(parser, Options{Path: path, AllFilter: true, BetweenStart: 15, BetweenEnd: 99, Count: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "2" {
+		t.Fatalf("expected the last 2 of 16 events, got %s", got)
+	}
+	if err := Run(parser, Options{Path: path, AllFilter: true, BetweenStart: 17, BetweenEnd: 20, Out: io.Discard}); !errors.Is(err, ErrNoEvents) {
+		t.Fatalf("expected ErrNoEvents past the last event, got %v", err)
+	}
+}
+
+func TestRunRoleLabels(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("LC_ALL", "en_US.UTF-8")
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	parser := &codex.CodexParser{}
+	labels, err := ParseRoleLabels(map[string]string{"User": "You", "assistant": "AI"})
+	if err != nil {
+		t.Fatalf("ParseRoleLabels returned error: %v", err)
+	}
+	render := func(mode string, labels RoleLabels) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := Run(parser, Options{Path: path, Format: mode, Wrap: 80, ForceNoColor: true, NoPager: true, ForceLegend: true, RoleLabels: labels, Out: &buf}); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		return buf.String()
+	}
+	// indentOf returns the indentation of the first line containing text.
+	indentOf := func(out, text string) int {
+		t.Helper()
+		for _, line := range strings.Split(out, "\n") {
+			if strings.Contains(line, text) {
+				return len(line) - len(strings.TrimLeft(line, " "))
+			}
+		}
+		t.Fatalf("no line contains %q:\n%s", text, out)
+		return 0
+	}
+
+	if out := render("text", labels); !strings.Contains(out, "[#001] You | ") || !strings.Contains(out, "[#002] AI | ") {
+		t.Fatalf("text headers should use the custom labels:\n%s", out)
+	}
+
+	plain, labeled := render("chat", nil), render("chat", labels)
+	if !strings.Contains(labeled, "Legend: You (right) · AI (left) · Tool (left)") {
+		t.Fatalf("legend should use the custom labels:\n%s", labeled)
+	}
+	if strings.Contains(labeled, "| User · ") || strings.Contains(labeled, "| Assistant · ") {
+		t.Fatalf("chat headers should not show the default labels:\n%s", labeled)
+	}
+	// Bubbles stay on the side of their role.
+	if indentOf(labeled, "| You · ") != indentOf(plain, "| User · ") || indentOf(labeled, "| AI · ") != indentOf(plain, "| Assistant · ") {
+		t.Fatalf("custom labels changed bubble alignment:\n%s\nvs\n%s", labeled, plain)
+	}
+
+	if _, err := ParseRoleLabels(map[string]string{"bot": "Robot"}); err == nil {
+		t.Fatalf("expected an unknown role to be rejected")
+	}
+}
+
+func TestRunFlatTools(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "tool-pairs.jsonl")
+
+	var buf bytes.Buffer
+	if err := Run(&codex.CodexParser{}, Options{
+		Path:            path,
+		Format:          "content",
+		ResponseTypeArg: "function_call,function_call_output",
+		FlatTools:       true,
+		Out:             &buf,
+	}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	// The two calls are made before either output arrives; each output
+	// still lands in the block of the call it answers.
+	blocks := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n\n")
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 combined blocks, got %d:\n%s", len(blocks), buf.String())
+	}
+	if !strings.Contains(blocks[0], `"ls"`) || !strings.Contains(blocks[0], "Output: go.mod") {
+		t.Fatalf("first call not paired with its output:\n%s", blocks[0])
+	}
+	if !strings.Contains(blocks[1], `"cat"`) || !strings.Contains(blocks[1], "Output: module example") {
+		t.Fatalf("second call not paired with its output:\n%s", blocks[1])
+	}
+
+	buf.Reset()
+	path = filepath.Join("..", "..", "testdata", "claude-sessions", "sample-with-tools.jsonl")
+	if err := Run(&claude.ClaudeParser{}, Options{Path: path, AllFilter: true, FlatTools: true, Stats: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	output := buf.String()
+	call := strings.Index(output, "Tool: Read (ID: toolu_01abc)")
+	result := strings.Index(output, "Tool Result (ID: toolu_01abc)")
+	if call < 0 || result < call || strings.Contains(output[call:result], "[#") {
+		t.Fatalf("tool_use and tool_result not rendered in one event:\n%s", output)
+	}
+	if strings.Contains(output, "[#005]") {
+		t.Fatalf("expected the tool result to be folded into its call:\n%s", output)
+	}
+	if !strings.Contains(output, "5 events shown, 0 filtered out") {
+		t.Fatalf("expected stats to count both halves of the pair:\n%s", output)
+	}
+}
+
+func TestRunExecCommands(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "exec-commands.jsonl")
+	run := func(flat bool) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := Run(&codex.CodexParser{}, Options{
+			Path:            path,
+			Format:          "content",
+			EntryTypeArg:    "event_msg",
+			EventMsgTypeArg: "exec_command_begin,exec_command_end",
+			FlatTools:       flat,
+			Out:             &buf,
+		}); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		return buf.String()
+	}
+
+	if output := run(false); !strings.Contains(output, "$ go test ./...") || !strings.Contains(output, "Output: --- FAIL: TestParse\nFAIL\nExit code: 1") {
+		t.Fatalf("expected the command and its exit code:\n%s", output)
+	}
+
+	// The commands end in the opposite order they began; call_id keeps
+	// each output with its own command.
+	blocks := strings.Split(strings.TrimSuffix(run(true), "\n"), "\n\n")
+	want := []string{
+		"$ go build ./...\nExit code: 0",
+		"$ go test ./...\nOutput: --- FAIL: TestParse\nFAIL\nExit code: 1",
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("expected %d combined blocks, got %d:\n%s", len(want), len(blocks), strings.Join(blocks, "\n\n"))
+	}
+	for i := range want {
+		if blocks[i] != want[i] {
+			t.Fatalf("block %d = %q, want %q", i, blocks[i], want[i])
+		}
+	}
+}
+
+func TestRunShowModelChanges(t *testing.T) {
+	// Codex records the model in turn_context entries, which are filtered
+	// out; the switch is shown before the next rendered event.
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "turn-context.jsonl")
+	var buf bytes.Buffer
+	if err := Run(&codex.CodexParser{}, Options{Path: path, ShowModelChanges: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	output := buf.String()
+	if got := strings.Count(output, "model changed to"); got != 1 {
+		t.Fatalf("expected one model divider, got %d:\n%s", got, output)
+	}
+	divider := strings.Index(output, "── model changed to gpt-5 ──")
+	if divider < 0 || divider > strings.Index(output, "Think harder") || divider < strings.Index(output, "Starting with the parser") {
+		t.Fatalf("expected the divider between the two turns:\n%s", output)
+	}
+
+	path = filepath.Join("..", "..", "testdata", "claude-sessions", "sample-model-switch.jsonl")
+	buf.Reset()
+	if err := Run(&claude.ClaudeParser{}, Options{Path: path, Format: "chat", ASCII: true, NoPager: true, ShowModelChanges: true, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	output = buf.String()
+	if got := strings.Count(output, "model changed to"); got != 1 || !strings.Contains(output, "-- model changed to claude-opus-4-20250514 --") {
+		t.Fatalf("expected one ASCII model divider for the Claude switch:\n%s", output)
+	}
+
+	buf.Reset()
+	if err := Run(&claude.ClaudeParser{}, Options{Path: path, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if strings.Contains(buf.String(), "model changed") {
+		t.Fatalf("expected no divider without ShowModelChanges:\n%s", buf.String())
+	}
+}
+
+func TestRunLimitBytes(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-full.jsonl")
+	parser := &codex.CodexParser{}
+
+	for _, mode := range []string{"text", "json", "content", "chat"} {
+		var full bytes.Buffer
+		if err := Run(parser, Options{Path: path, Format: mode, NoPager: true, Out: &full}); err != nil {
+			t.Fatalf("%s: Run returned error: %v", mode, err)
+		}
+
+		var buf bytes.Buffer
+		if err := Run(parser, Options{Path: path, Format: mode, NoPager: true, LimitBytes: 50, Out: &buf}); err != nil {
+			t.Fatalf("%s: expected the limit to stop cleanly, got %v", mode, err)
+		}
+		want := full.String()[:50] + "\n(output truncated at 50 bytes)\n"
+		if got := buf.String(); got != want {
+			t.Fatalf("%s: unexpected truncated output:\n%q\nwant:\n%q", mode, got, want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Run(parser, Options{Path: path, LimitBytes: 1 << 20, Out: &buf}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if strings.Contains(buf.String(), "output truncated") {
+		t.Fatalf("expected no notice when the output fits:\n%s", buf.String())
+	}
+
+	// Automatic color still looks at the terminal behind the limit.
+	if file, ok := outputFile(&limitWriter{w: os.Stdout, limit: 50}); !ok || file != os.Stdout {
+		t.Fatalf("expected the limited output to resolve to stdout, got %v", file)
+	}
+}
+
+func TestRunShowInstructions(t *testing.T) {
+	path := filepath.Join("..", "..", "testdata", "codex-edge-cases", "instructions.jsonl")
+	parser := &codex.CodexParser{}
+	run := func(opts Options) string {
+		t.Helper()
+		var buf bytes.Buffer
+		opts.Path, opts.Out = path, &buf
+		if err := Run(parser, opts); err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+		return buf.String()
+	}
+
+	if output := run(Options{}); strings.Contains(output, "[instructions]") || strings.Contains(output, "You are Codex") {
+		t.Fatalf("expected no instructions unless requested:\n%s", output)
+	}
+
+	output := run(Options{ShowInstructions: true})
+	if !strings.HasPrefix(output, "[instructions]\n--------------\n| You are Codex") {
+		t.Fatalf("expected the instructions above the events:\n%s", output)
+	}
+	if !strings.Contains(output, "bytes truncated)") || strings.Contains(output, "files you changed.") {
+		t.Fatalf("expected the instructions to be clipped by default:\n%s", output)
+	}
+	if strings.Index(output, "[instructions]") > strings.Index(output, "Tidy up the README") {
+		t.Fatalf("expected the instructions before the first event:\n%s", output)
+	}
+
+	output = run(Options{ShowInstructions: true, FullInstructions: true})
+	if strings.Contains(output, "truncated") || !strings.Contains(output, "| Always end with a summary of the files you changed.") {
+		t.Fatalf("expected the whole instructions with FullInstructions:\n%s", output)
+	}
+}
@@ -0,0 +1,69 @@
+package view
+
+import (
+	"agentlog/internal/format"
+	"agentlog/internal/model"
+	"agentlog/internal/store"
+	"fmt"
+	"time"
+)
+
+// viewStats summarizes a rendered transcript for the --stats footer.
+type viewStats struct {
+	seen         int // events read from the session
+	shown        int
+	inputTokens  int
+	outputTokens int
+	first        time.Time
+	last         time.Time
+	// firstResponse sees every event read, so the latency describes the
+	// session rather than the filtered output.
+	firstResponse store.FirstResponseTracker
+}
+
+// observe records an event read from the session, before filtering.
+func (s *viewStats) observe(event model.EventProvider) {
+	s.seen++
+	s.firstResponse.Observe(event)
+}
+
+// add records an event that made it into the output.
+func (s *viewStats) add(event model.EventProvider) {
+	if composite, ok := event.(compositeEvent); ok {
+		for _, part := range composite.parts() {
+			s.add(part)
+		}
+		return
+	}
+	s.shown++
+	if provider, ok := event.(model.TokenUsageProvider); ok {
+		input, output := provider.GetTokenUsage()
+		s.inputTokens += input
+		s.outputTokens += output
+	}
+	ts := event.GetTimestamp()
+	if ts.IsZero() {
+		return
+	}
+	if s.first.IsZero() || ts.Before(s.first) {
+		s.first = ts
+	}
+	if ts.After(s.last) {
+		s.last = ts
+	}
+}
+
+// footer renders the summary line. Events dropped by the filters or by
+// --max count as filtered out.
+func (s *viewStats) footer() string {
+	span := time.Duration(0)
+	if !s.first.IsZero() {
+		span = s.last.Sub(s.first)
+	}
+	line := fmt.Sprintf("-- %d events shown, %d filtered out | tokens: %d in / %d out | span: %s",
+		s.shown, s.seen-s.shown, s.inputTokens, s.outputTokens, format.DurationClock.Format(int(span/time.Second)))
+	if latency, ok := s.firstResponse.Latency(); ok {
+		line += " | first response: " + format.DurationClock.Format(int(latency/time.Second))
+	}
+	return line + " --"
+}
@@ -0,0 +1,131 @@
+package view
+
+import (
+	"agentlog/internal/model"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// tailChunkSize is how many bytes are read per step when scanning backward.
+var tailChunkSize int64 = 64 * 1024
+
+// errCompressedTail is returned by readTailLines for gzip-compressed files,
+// which cannot be read backward.
+var errCompressedTail = errors.New("compressed session file")
+
+// readTailLines returns the last n non-blank lines of the file at path by
+// reading backward from the end. whole reports that the file holds n or fewer
+// lines, in which case lines is nil and the caller should read the file
+// normally.
+func readTailLines(path string, n int) (lines [][]byte, whole bool, err error) {
+	reader, err := model.OpenSessionFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("open session file: %w", err)
+	}
+	defer reader.Close() //nolint:errcheck
+	file, ok := reader.(*os.File)
+	if !ok {
+		return nil, false, errCompressedTail
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, false, fmt.Errorf("stat session file: %w", err)
+	}
+
+	offset := info.Size()
+	var buf []byte
+	for offset > 0 {
+		size := tailChunkSize
+		if offset < size {
+			size = offset
+		}
+		offset -= size
+
+		chunk := make([]byte, size)
+		if _, err := file.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return nil, false, fmt.Errorf("read session file: %w", err)
+		}
+		buf = append(chunk, buf...)
+
+		complete := buf
+		if offset > 0 {
+			// The first segment may start mid-line; only count what follows it.
+			idx := bytes.IndexByte(buf, '\n')
+			if idx < 0 {
+				continue
+			}
+			complete = buf[idx+1:]
+		}
+		found := nonBlankLines(complete)
+		if len(found) > n {
+			return found[len(found)-n:], false, nil
+		}
+		if offset == 0 {
+			break
+		}
+	}
+	return nil, true, nil
+}
+
+func nonBlankLines(data []byte) [][]byte {
+	var lines [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// iterateTail calls fn for the events decoded from the last n lines of path.
+// Lines that fail to decode are skipped. It falls back to a full read when
+// the file is short, is gzip-compressed and cannot be read backward, or the
+// parser cannot decode single lines.
+func iterateTail(parser model.Parser, path string, n int, fn func(model.EventProvider) error) error {
+	lineParser, ok := parser.(model.EventLineParser)
+	if !ok {
+		return iterateLastEvents(parser, path, n, fn)
+	}
+	lines, whole, err := readTailLines(path, n)
+	if errors.Is(err, errCompressedTail) {
+		return iterateLastEvents(parser, path, n, fn)
+	}
+	if err != nil {
+		return err
+	}
+	if whole {
+		return parser.IterateEvents(path, fn)
+	}
+	for _, line := range lines {
+		event, err := lineParser.ParseEventLine(line)
+		if err != nil {
+			continue
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterateLastEvents reads the whole file and calls fn for its last n events.
+func iterateLastEvents(parser model.Parser, path string, n int, fn func(model.EventProvider) error) error {
+	ring := newEventRing(n)
+	if err := parser.IterateEvents(path, func(event model.EventProvider) error {
+		ring.push(event)
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, event := range ring.slice() {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package view
+
+import (
+	"agentlog/internal/model"
+	"strings"
+)
+
+// toolPair presents a tool call with the outputs that answer it as a single
+// event, so --flat-tools renders them in one block. The call supplies the
+// header; the outputs' content follows the call's.
+type toolPair struct {
+	model.EventProvider
+	outputs []model.EventProvider
+}
+
+func (e *toolPair) GetContent() []model.ContentBlock {
+	content := append([]model.ContentBlock(nil), e.EventProvider.GetContent()...)
+	for _, output := range e.outputs {
+		content = append(content, output.GetContent()...)
+	}
+	return content
+}
+
+// GetRaw returns the call's record followed by its outputs', one per line.
+func (e *toolPair) GetRaw() string {
+	raws := make([]string, 0, len(e.outputs)+1)
+	raws = append(raws, e.EventProvider.GetRaw())
+	for _, output := range e.outputs {
+		raws = append(raws, output.GetRaw())
+	}
+	return strings.Join(raws, "\n")
+}
+
+// GetMetadata forwards the call's metadata, if any.
+func (e *toolPair) GetMetadata() map[string]string {
+	if provider, ok := e.EventProvider.(model.EventMetadataProvider); ok {
+		return provider.GetMetadata()
+	}
+	return nil
+}
+
+// GetTokenUsage forwards the call's token usage, if any.
+func (e *toolPair) GetTokenUsage() (input, output int) {
+	if provider, ok := e.EventProvider.(model.TokenUsageProvider); ok {
+		return provider.GetTokenUsage()
+	}
+	return 0, 0
+}
+
+// parts returns the call followed by its outputs.
+func (e *toolPair) parts() []model.EventProvider {
+	return append([]model.EventProvider{e.EventProvider}, e.outputs...)
+}
+
+// pairToolCalls moves each tool output up to the call it answers. Outputs
+// that name their call (Claude's tool_use_id, the call_id of Codex
+// exec_command_end) join that call; outputs that do not (Codex function
+// calls) join the earliest preceding call not yet answered. Outputs without
+// a matching call stay where they are.
+func pairToolCalls(events []model.EventProvider) []model.EventProvider {
+	paired := make([]model.EventProvider, 0, len(events))
+	byID := make(map[string]int)
+	var pending []int // calls without IDs, oldest first
+	for _, event := range events {
+		ids := toolCallIDs(event)
+		if !isToolOutput(event) {
+			switch {
+			case len(ids) > 0:
+				for _, id := range ids {
+					byID[id] = len(paired)
+				}
+			case isToolCall(event):
+				pending = append(pending, len(paired))
+			}
+			paired = append(paired, event)
+			continue
+		}
+
+		call := -1
+		if len(ids) > 0 {
+			if idx, ok := byID[ids[0]]; ok {
+				call = idx
+			}
+		} else if len(pending) > 0 {
+			call, pending = pending[0], pending[1:]
+		}
+		if call < 0 {
+			paired = append(paired, event)
+			continue
+		}
+		pair, ok := paired[call].(*toolPair)
+		if !ok {
+			pair = &toolPair{EventProvider: paired[call]}
+			paired[call] = pair
+		}
+		pair.outputs = append(pair.outputs, event)
+	}
+	return paired
+}
+
+func toolCallIDs(event model.EventProvider) []string {
+	if provider, ok := event.(model.ToolCallProvider); ok {
+		return provider.GetToolCallIDs()
+	}
+	return nil
+}
+
+func isToolCall(event model.EventProvider) bool {
+	switch event.GetPayloadType() {
+	case "function_call", "custom_tool_call", "exec_command_begin":
+		return true
+	}
+	return false
+}
+
+func isToolOutput(event model.EventProvider) bool {
+	switch event.GetPayloadType() {
+	case "function_call_output", "custom_tool_call_output", "exec_command_end":
+		return true
+	}
+	return false
+}
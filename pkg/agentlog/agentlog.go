@@ -0,0 +1,162 @@
+// Package agentlog lets Go programs read and render agent session logs
+// without going through the CLI.
+//
+// Stability: the functions, types, and fields exported here follow semantic
+// versioning; they are only removed or changed incompatibly in a major
+// release. New fields and options may be added at any time, so construct
+// option structs with field names. The interfaces are aliases of the ones the
+// parsers implement; call their methods but do not implement them yourself,
+// as methods may be added. Everything under internal/ may change without
+// notice.
+package agentlog
+
+import (
+	// Register both parsers with the model factory.
+	_ "agentlog/internal/claude"
+	_ "agentlog/internal/codex"
+	"agentlog/internal/model"
+	"agentlog/internal/store"
+	"agentlog/internal/view"
+	"errors"
+	"io"
+	"time"
+)
+
+// Agent identifies the tool that wrote a session log.
+type Agent = model.AgentType
+
+const (
+	// Codex reads sessions written by the Codex CLI.
+	Codex Agent = model.AgentCodex
+	// Claude reads sessions written by Claude Code.
+	Claude Agent = model.AgentClaude
+)
+
+// EventProvider is a single event of a session.
+type EventProvider = model.EventProvider
+
+// SessionMetaProvider describes a session: ID, path, working directory, and
+// start time.
+type SessionMetaProvider = model.SessionMetaProvider
+
+// SessionSummaryProvider is a listed session with its summary, message
+// count, and duration.
+type SessionSummaryProvider = model.SessionSummaryProvider
+
+// ContentBlock is a typed part of an event's content.
+type ContentBlock = model.ContentBlock
+
+// Session is an opened session log.
+type Session struct {
+	parser model.Parser
+	meta   model.SessionMetaProvider
+}
+
+// OpenSession reads the metadata of the session log at path.
+func OpenSession(agent Agent, path string) (*Session, error) {
+	parser, err := model.NewParser(agent)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := parser.ReadSessionMeta(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{parser: parser, meta: meta}, nil
+}
+
+// Meta returns the session metadata.
+func (s *Session) Meta() SessionMetaProvider { return s.meta }
+
+// Events calls fn for each event in file order. Returning an error from fn
+// stops the iteration and is returned as is.
+func (s *Session) Events(fn func(EventProvider) error) error {
+	return s.parser.IterateEvents(s.meta.GetPath(), fn)
+}
+
+// ListOptions selects the sessions returned by ListSessions.
+type ListOptions struct {
+	// Roots are the directories to scan. Sessions found under more than one
+	// root are listed once.
+	Roots []string
+	// CWD keeps only sessions started in this directory; empty keeps all.
+	CWD string
+	// After and Before bound the session start time; zero means no bound.
+	After  time.Time
+	Before time.Time
+	// Limit caps the number of sessions returned; 0 means no limit.
+	Limit int
+}
+
+// ListResult holds the listed sessions, newest first, and the files that
+// were skipped because they could not be read.
+type ListResult struct {
+	Sessions []SessionSummaryProvider
+	Warnings []error
+}
+
+// ListSessions scans the roots for session logs of the given agent.
+func ListSessions(agent Agent, opts ListOptions) (ListResult, error) {
+	if len(opts.Roots) == 0 {
+		return ListResult{}, errors.New("at least one root directory is required")
+	}
+	parser, err := model.NewParser(agent)
+	if err != nil {
+		return ListResult{}, err
+	}
+	storeOpts := store.ListOptions{
+		Roots: opts.Roots,
+		CWD:   opts.CWD,
+		Limit: opts.Limit,
+	}
+	if !opts.After.IsZero() {
+		storeOpts.After = &opts.After
+	}
+	if !opts.Before.IsZero() {
+		storeOpts.Before = &opts.Before
+	}
+	result, err := store.ListSessions(parser, storeOpts)
+	if err != nil {
+		return ListResult{}, err
+	}
+	return ListResult{Sessions: result.Summaries, Warnings: result.Warnings}, nil
+}
+
+// RenderOptions controls RenderTranscript.
+type RenderOptions struct {
+	// Format is "text" (the default), "chat", "raw", "jsonl", or "json".
+	Format string
+	// Width wraps message bodies at this many columns; 0 disables wrapping
+	// for text, and chat falls back to $COLUMNS or 80.
+	Width int
+	// All lifts the default filters so every entry is rendered.
+	All bool
+	// MaxEvents keeps only the most recent N events; 0 means no limit.
+	MaxEvents int
+	// Color enables ANSI colors.
+	Color bool
+	// Redact masks the home directory and secrets.
+	Redact bool
+}
+
+// RenderTranscript writes the session transcript to w. It never pages and
+// never writes anywhere else. A transcript without matching events is not an
+// error.
+func RenderTranscript(w io.Writer, s *Session, opts RenderOptions) error {
+	err := view.Run(s.parser, view.Options{
+		Path:         s.meta.GetPath(),
+		Format:       opts.Format,
+		Wrap:         opts.Width,
+		MaxEvents:    opts.MaxEvents,
+		AllFilter:    opts.All,
+		ForceColor:   opts.Color,
+		ForceNoColor: !opts.Color,
+		Redact:       opts.Redact,
+		NoPager:      true,
+		Out:          w,
+	})
+	if errors.Is(err, view.ErrNoEvents) {
+		return nil
+	}
+	return err
+}
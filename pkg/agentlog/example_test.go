@@ -0,0 +1,40 @@
+package agentlog_test
+
+import (
+	"agentlog/pkg/agentlog"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func Example_listSessions() {
+	root := filepath.Join("..", "..", "testdata", "sessions")
+	result, err := agentlog.ListSessions(agentlog.Codex, agentlog.ListOptions{Roots: []string{root}})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, session := range result.Sessions {
+		fmt.Printf("%s  %d messages  %s\n", session.GetID(), session.GetMessageCount(), session.GetSummary())
+	}
+	// Output:
+	// test-full-session  4 messages  Please help me implement a feature
+	// test-simple-session  4 messages  Hello, can you help me?
+}
+
+func Example_renderTranscript() {
+	path := filepath.Join("..", "..", "testdata", "sessions", "sample-simple.jsonl")
+	session, err := agentlog.OpenSession(agentlog.Codex, path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("session:", session.Meta().GetID())
+	if err := agentlog.RenderTranscript(os.Stdout, session, agentlog.RenderOptions{Format: "text", MaxEvents: 1}); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// session: test-simple-session
+	// [#001] assistant | 2025-11-05T09:00:04Z
+	// ---------------------------------------
+	// | I'd be happy to help you write a function. What should it do?
+}